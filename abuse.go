@@ -0,0 +1,202 @@
+package ding
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ding-live/ding-go/internal/lru"
+	"github.com/ding-live/ding-go/pkg/status"
+)
+
+const (
+	defaultAbuseWindow                = 10 * time.Minute
+	defaultAbuseSpamThreshold         = 10
+	defaultAbuseInvalidCheckThreshold = 5
+
+	// abuseCacheSize bounds the number of authentications and phone numbers
+	// tracked by the abuse detector.
+	abuseCacheSize = 10000
+
+	// abuseAuthTTL is how long the phone number of an authentication is
+	// remembered to attribute its checks.
+	abuseAuthTTL = time.Hour
+)
+
+// AbusePattern is a suspicious pattern detected in the traffic of the client.
+type AbusePattern string
+
+const (
+	// AbuseSpamDetected is reported when many authentications are flagged as
+	// spam by Ding, which hints at an SMS pumping attack.
+	AbuseSpamDetected AbusePattern = "spam_detected"
+
+	// AbuseInvalidChecks is reported when many invalid codes are checked for
+	// the same phone number, which hints at a brute force attack.
+	AbuseInvalidChecks AbusePattern = "invalid_checks"
+)
+
+// AbuseAlert describes a suspicious pattern detected by the client.
+type AbuseAlert struct {
+	Pattern AbusePattern
+
+	// PhoneNumber is the targeted phone number for AbuseInvalidChecks,
+	// redacted according to Config.RedactPhoneNumbers.
+	PhoneNumber string
+
+	// Count is the number of occurrences that triggered the alert, within
+	// Window.
+	Count  int
+	Window time.Duration
+}
+
+// AbuseDetection configures the detection of suspicious patterns in the
+// authentications and checks made through the client. Detection is local to
+// the client: run it on every instance, or aggregate the alerts.
+type AbuseDetection struct {
+	// OnAlert is called synchronously when a pattern is detected, and must be
+	// safe for concurrent use. Detection is enabled when it is set.
+	OnAlert func(AbuseAlert)
+
+	// Window is the period over which occurrences are counted. Once an alert
+	// is raised, its occurrences are forgotten, so a sustained attack raises
+	// an alert every threshold occurrences.
+	//
+	// Defaults to 10 minutes.
+	Window time.Duration
+
+	// SpamThreshold is the number of authentications with the spam_detected
+	// status that raises an AbuseSpamDetected alert.
+	//
+	// Defaults to 10.
+	SpamThreshold int
+
+	// InvalidCheckThreshold is the number of invalid checks for the same
+	// phone number that raises an AbuseInvalidChecks alert. Only checks of
+	// authentications created by the client are attributed to a number.
+	//
+	// Defaults to 5.
+	InvalidCheckThreshold int
+}
+
+// abuseDetector counts the occurrences of the patterns of AbuseDetection.
+type abuseDetector struct {
+	onAlert               func(AbuseAlert)
+	window                time.Duration
+	spamThreshold         int
+	invalidCheckThreshold int
+	redaction             PhoneRedaction
+	now                   func() time.Time
+
+	// phoneNumbers maps authentication UUIDs to their phone number.
+	phoneNumbers *lru.Cache[string, string]
+
+	mu sync.Mutex
+	// spam holds the times of the recent spam_detected authentications.
+	spam []time.Time
+	// invalidChecks holds the times of the recent invalid checks of each
+	// phone number.
+	invalidChecks *lru.Cache[string, []time.Time]
+}
+
+func newAbuseDetector(cfg AbuseDetection, redaction PhoneRedaction) *abuseDetector {
+	if cfg.OnAlert == nil {
+		return nil
+	}
+
+	d := &abuseDetector{
+		onAlert:               cfg.OnAlert,
+		window:                cfg.Window,
+		spamThreshold:         cfg.SpamThreshold,
+		invalidCheckThreshold: cfg.InvalidCheckThreshold,
+		redaction:             redaction,
+		now:                   time.Now,
+	}
+
+	if d.window <= 0 {
+		d.window = defaultAbuseWindow
+	}
+
+	if d.spamThreshold <= 0 {
+		d.spamThreshold = defaultAbuseSpamThreshold
+	}
+
+	if d.invalidCheckThreshold <= 0 {
+		d.invalidCheckThreshold = defaultAbuseInvalidCheckThreshold
+	}
+
+	d.phoneNumbers = lru.New[string, string](abuseCacheSize, abuseAuthTTL)
+	d.invalidChecks = lru.New[string, []time.Time](abuseCacheSize, d.window)
+
+	return d
+}
+
+// authenticated records the result of an authentication. A nil detector
+// doesn't record anything.
+func (d *abuseDetector) authenticated(auth *Authentication) {
+	if d == nil {
+		return
+	}
+
+	d.phoneNumbers.Add(auth.AuthenticationUUID, auth.PhoneNumber.E164)
+
+	if auth.Status != status.AuthSpamDetected {
+		return
+	}
+
+	d.mu.Lock()
+	d.spam = d.record(d.spam)
+	count := len(d.spam)
+	if count >= d.spamThreshold {
+		d.spam = nil
+	}
+	d.mu.Unlock()
+
+	if count >= d.spamThreshold {
+		d.onAlert(AbuseAlert{Pattern: AbuseSpamDetected, Count: count, Window: d.window})
+	}
+}
+
+// checked records the result of a check. A nil detector doesn't record
+// anything.
+func (d *abuseDetector) checked(check *Check) {
+	if d == nil || check.Status != status.CheckInvalid {
+		return
+	}
+
+	phoneNumber, ok := d.phoneNumbers.Get(check.AuthenticationUUID)
+	if !ok {
+		return
+	}
+
+	d.mu.Lock()
+	times, _ := d.invalidChecks.Get(phoneNumber)
+	times = d.record(times)
+	count := len(times)
+	if count >= d.invalidCheckThreshold {
+		times = nil
+	}
+	d.invalidChecks.Add(phoneNumber, times)
+	d.mu.Unlock()
+
+	if count >= d.invalidCheckThreshold {
+		d.onAlert(AbuseAlert{
+			Pattern:     AbuseInvalidChecks,
+			PhoneNumber: d.redaction.redact(phoneNumber),
+			Count:       count,
+			Window:      d.window,
+		})
+	}
+}
+
+// record appends the current time to times, dropping the times that are out
+// of the window.
+func (d *abuseDetector) record(times []time.Time) []time.Time {
+	now := d.now()
+
+	i := 0
+	for i < len(times) && now.Sub(times[i]) >= d.window {
+		i++
+	}
+
+	return append(times[i:], now)
+}
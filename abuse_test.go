@@ -0,0 +1,104 @@
+package ding
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ding-live/ding-go/pkg/dingtest"
+	"github.com/ding-live/ding-go/pkg/status"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type alertRecorder struct {
+	mu     sync.Mutex
+	alerts []AbuseAlert
+}
+
+func (r *alertRecorder) record(a AbuseAlert) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.alerts = append(r.alerts, a)
+}
+
+func TestAbuseDetection(t *testing.T) {
+	srv := dingtest.NewServer()
+	defer srv.Close()
+
+	srv.SetScenario("+33600000001", dingtest.Scenario{Status: status.AuthSpamDetected})
+
+	var rec alertRecorder
+
+	client, err := NewClient(Config{
+		CustomerUUID:       dingtest.CustomerUUID,
+		APIKey:             dingtest.APIKey,
+		BaseURL:            srv.URL,
+		RedactPhoneNumbers: PhoneRedactionTruncate,
+		AbuseDetection: AbuseDetection{
+			OnAlert:               rec.record,
+			SpamThreshold:         3,
+			InvalidCheckThreshold: 2,
+		},
+	})
+	require.NoError(t, err)
+
+	for i := 0; i < 4; i++ {
+		_, err := client.Authenticate(NewAuth("+33600000001"))
+		require.NoError(t, err)
+	}
+
+	require.Len(t, rec.alerts, 1)
+	assert.Equal(t, AbuseAlert{Pattern: AbuseSpamDetected, Count: 3, Window: 10 * time.Minute}, rec.alerts[0])
+
+	auth, err := client.Authenticate(NewAuth("+33612345678"))
+	require.NoError(t, err)
+
+	_, err = client.Check(auth.AuthenticationUUID, "0000")
+	require.NoError(t, err)
+	assert.Len(t, rec.alerts, 1)
+
+	// Invalid checks are counted per phone number, across authentications.
+	auth, err = client.Authenticate(NewAuth("+33612345678"))
+	require.NoError(t, err)
+
+	_, err = client.Check(auth.AuthenticationUUID, "0000")
+	require.NoError(t, err)
+
+	require.Len(t, rec.alerts, 2)
+	assert.Equal(t, AbuseAlert{
+		Pattern:     AbuseInvalidChecks,
+		PhoneNumber: "+336******78",
+		Count:       2,
+		Window:      10 * time.Minute,
+	}, rec.alerts[1])
+
+	// Valid checks are not counted.
+	_, err = client.Check(auth.AuthenticationUUID, dingtest.Code)
+	require.NoError(t, err)
+	assert.Len(t, rec.alerts, 2)
+}
+
+func TestAbuseDetectionWindow(t *testing.T) {
+	var rec alertRecorder
+
+	d := newAbuseDetector(AbuseDetection{OnAlert: rec.record, Window: time.Minute, SpamThreshold: 2}, PhoneRedactionNone)
+
+	now := time.Now()
+	d.now = func() time.Time { return now }
+
+	spam := &Authentication{AuthenticationUUID: "a", Status: status.AuthSpamDetected}
+
+	d.authenticated(spam)
+	now = now.Add(time.Minute)
+	d.authenticated(spam)
+	assert.Empty(t, rec.alerts)
+
+	now = now.Add(time.Second)
+	d.authenticated(spam)
+	assert.Len(t, rec.alerts, 1)
+
+	// The detector is disabled without OnAlert.
+	assert.Nil(t, newAbuseDetector(AbuseDetection{SpamThreshold: 1}, PhoneRedactionNone))
+}
@@ -0,0 +1,81 @@
+package ding
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func newBenchClient(b *testing.B, body string) *Client {
+	hc := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprint(w, body)
+	}))
+	b.Cleanup(hc.Close)
+
+	client, err := NewClient(Config{
+		CustomerUUID: uuid.New().String(),
+		BaseURL:      hc.URL,
+	})
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	return client
+}
+
+func BenchmarkAuthenticate(b *testing.B) {
+	client := newBenchClient(b, `{
+		"authentication_uuid": "a74ee547-564d-487a-91df-37fb25413a91",
+		"status": "pending",
+		"created_at": "2024-01-01T00:00:00Z",
+		"expires_at": "2024-01-01T00:05:00Z"
+	}`)
+
+	opt := NewAuth("+33612345678").WithIP("192.168.0.1")
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := client.Authenticate(opt); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCheck(b *testing.B) {
+	client := newBenchClient(b, `{"authentication_uuid": "a74ee547-564d-487a-91df-37fb25413a91", "status": "valid"}`)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := client.Check("a74ee547-564d-487a-91df-37fb25413a91", "1234"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkNormalizePhoneNumber(b *testing.B) {
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := normalizePhoneNumber("+33 6 12 34 56 78", "", PhoneValidationStrict); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkLoggerDisabled(b *testing.B) {
+	l := &Logger{Level: LevelError}
+
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		l.Debugf("%s %s received HTTP status %d", http.MethodPost, "check", http.StatusOK)
+	}
+}
@@ -0,0 +1,139 @@
+package ding
+
+import (
+	"net/netip"
+	"time"
+)
+
+// NewAuth returns the options to authenticate phoneNumber. Optional fields can
+// then be set by chaining the With methods:
+//
+//	opt := ding.NewAuth("+33xxxxxxxxx").
+//		WithIP("192.168.0.1").
+//		WithDevice(ding.DeviceTypeIOS, deviceID).
+//		WithDeviceModel("iPhone15,2").
+//		WithOSVersion("17.4").
+//		WithCallback("https://example.com/callback")
+func NewAuth(phoneNumber string) AuthenticateOptions {
+	return AuthenticateOptions{
+		PhoneNumber: phoneNumber,
+	}
+}
+
+// WithPhoneRegion returns a copy of the options with the region used to parse
+// the phone number set, overriding Config.DefaultPhoneRegion.
+func (o AuthenticateOptions) WithPhoneRegion(region string) AuthenticateOptions {
+	o.PhoneRegion = region
+	return o
+}
+
+// WithIP returns a copy of the options with the IP address of the user set.
+// The address can include a port, so that the RemoteAddr of an http.Request
+// can be passed as is.
+func (o AuthenticateOptions) WithIP(ip string) AuthenticateOptions {
+	o.IP = &ip
+	return o
+}
+
+// WithIPAddr returns a copy of the options with the IP address of the user set
+// from a parsed address, such as the one of a netip.AddrPort.
+func (o AuthenticateOptions) WithIPAddr(addr netip.Addr) AuthenticateOptions {
+	return o.WithIP(addr.Unmap().String())
+}
+
+// WithDevice returns a copy of the options with the type and ID of the user
+// device set.
+func (o AuthenticateOptions) WithDevice(deviceType DeviceType, deviceID string) AuthenticateOptions {
+	o.DeviceType = &deviceType
+	o.DeviceID = &deviceID
+	return o
+}
+
+// WithDeviceSignals returns a copy of the options with the device of the user
+// set.
+func (o AuthenticateOptions) WithDeviceSignals(device DeviceSignals) AuthenticateOptions {
+	o.Device = &device
+	return o
+}
+
+// WithAppVersion returns a copy of the options with the version of your app
+// set.
+func (o AuthenticateOptions) WithAppVersion(version string) AuthenticateOptions {
+	o.AppVersion = &version
+	return o
+}
+
+// WithDeviceModel returns a copy of the options with the model of the user
+// device set, such as "iPhone15,2".
+func (o AuthenticateOptions) WithDeviceModel(model string) AuthenticateOptions {
+	d := o.device()
+	d.Model = model
+	o.Device = &d
+	return o
+}
+
+// WithOSVersion returns a copy of the options with the version of the operating
+// system of the user device set.
+func (o AuthenticateOptions) WithOSVersion(version string) AuthenticateOptions {
+	d := o.device()
+	d.OSVersion = version
+	o.Device = &d
+	return o
+}
+
+// device returns a copy of o.Device, so that builders don't modify the device
+// of the options they were called on.
+func (o AuthenticateOptions) device() DeviceSignals {
+	if o.Device == nil {
+		return DeviceSignals{}
+	}
+
+	return *o.Device
+}
+
+// WithCallback returns a copy of the options with the callback URL set.
+func (o AuthenticateOptions) WithCallback(url string) AuthenticateOptions {
+	o.CallbackURL = &url
+	return o
+}
+
+// WithConsent returns a copy of the options with the consent of the user set.
+func (o AuthenticateOptions) WithConsent(consent Consent) AuthenticateOptions {
+	o.Consent = &consent
+	return o
+}
+
+// WithTTL returns a copy of the options with the lifetime of the code
+// shortened to ttl. See AuthenticateOptions.TTL.
+func (o AuthenticateOptions) WithTTL(ttl time.Duration) AuthenticateOptions {
+	o.TTL = ttl
+	return o
+}
+
+// WithChannels returns a copy of the options with the ordered list of channels
+// used to deliver the code set.
+func (o AuthenticateOptions) WithChannels(channels ...Channel) AuthenticateOptions {
+	o.Channels = channels
+	return o
+}
+
+// WithReturningUser returns a copy of the options marking the user as a
+// returning user.
+func (o AuthenticateOptions) WithReturningUser() AuthenticateOptions {
+	o.IsReturningUser = true
+	return o
+}
+
+// WithExtra returns a copy of the options with the extra field name set to
+// value. See AuthenticateOptions.Extra.
+func (o AuthenticateOptions) WithExtra(name string, value interface{}) AuthenticateOptions {
+	extra := make(map[string]interface{}, len(o.Extra)+1)
+	for k, v := range o.Extra {
+		extra[k] = v
+	}
+
+	extra[name] = value
+	o.Extra = extra
+
+	return o
+}
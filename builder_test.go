@@ -0,0 +1,58 @@
+package ding
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewAuth(t *testing.T) {
+	base := NewAuth("+33612345678")
+
+	opt := base.
+		WithIP("192.168.0.1").
+		WithDevice(DeviceTypeIOS, "device-id").
+		WithAppVersion("1.2.0").
+		WithCallback("https://example.com/callback").
+		WithReturningUser()
+
+	assert.Equal(t, AuthenticateOptions{
+		PhoneNumber:     "+33612345678",
+		IP:              String("192.168.0.1"),
+		DeviceID:        String("device-id"),
+		DeviceType:      &DeviceTypeIOS,
+		AppVersion:      String("1.2.0"),
+		CallbackURL:     String("https://example.com/callback"),
+		IsReturningUser: true,
+	}, opt)
+
+	// The base options are left untouched.
+	assert.Equal(t, AuthenticateOptions{PhoneNumber: "+33612345678"}, base)
+}
+
+func TestNewAuthDevice(t *testing.T) {
+	base := NewAuth("+33612345678").WithDeviceSignals(DeviceSignals{ID: "device-id"})
+
+	opt := base.
+		WithDeviceModel("iPhone15,2").
+		WithOSVersion("17.4")
+
+	assert.Equal(t, &DeviceSignals{
+		ID:        "device-id",
+		Model:     "iPhone15,2",
+		OSVersion: "17.4",
+	}, opt.Device)
+
+	// The device of the base options is left untouched.
+	assert.Equal(t, &DeviceSignals{ID: "device-id"}, base.Device)
+}
+
+func TestNewAuthExtra(t *testing.T) {
+	base := NewAuth("+33612345678").WithExtra("template_id", "login")
+	opt := base.WithExtra("locale", "fr")
+
+	assert.Equal(t, map[string]interface{}{"template_id": "login", "locale": "fr"}, opt.Extra)
+
+	// The extra fields of the base options are left untouched.
+	assert.Equal(t, map[string]interface{}{"template_id": "login"}, base.Extra)
+}
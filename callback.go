@@ -0,0 +1,35 @@
+package ding
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/ding-live/ding-go/pkg/webhook"
+)
+
+// maxCallbackSize bounds the size of the callback bodies read by
+// VerifyCallback.
+const maxCallbackSize = 1 << 20
+
+// VerifyCallback reads the body of a callback sent by Ding to your callback URL
+// and checks its signature against Config.CallbackSecret. The body is only
+// returned if the signature is valid, in which case r.Body is consumed.
+//
+// Signature errors are the ones of the webhook package, such as
+// webhook.ErrInvalidSignature.
+func (c *Client) VerifyCallback(r *http.Request) ([]byte, error) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxCallbackSize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(body) > maxCallbackSize {
+		return nil, ErrCallbackTooLarge
+	}
+
+	if err := webhook.Verify(c.callbackSecret, body, r.Header.Get(webhook.SignatureHeader)); err != nil {
+		return nil, err
+	}
+
+	return body, nil
+}
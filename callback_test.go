@@ -0,0 +1,61 @@
+package ding
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ding-live/ding-go/pkg/dingtest"
+	"github.com/ding-live/ding-go/pkg/webhook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyCallback(t *testing.T) {
+	client, err := NewClient(Config{
+		CustomerUUID:   dingtest.CustomerUUID,
+		CallbackSecret: "whsec_test_secret",
+	})
+	require.NoError(t, err)
+
+	const body = `{"status":"approved"}`
+
+	r := httptest.NewRequest("POST", "/callback", strings.NewReader(body))
+	r.Header.Set(webhook.SignatureHeader, webhook.Sign("whsec_test_secret", []byte(body), time.Now()))
+
+	got, err := client.VerifyCallback(r)
+	require.NoError(t, err)
+	assert.Equal(t, body, string(got))
+
+	r = httptest.NewRequest("POST", "/callback", strings.NewReader(body))
+	r.Header.Set(webhook.SignatureHeader, webhook.Sign("whsec_other_secret", []byte(body), time.Now()))
+
+	_, err = client.VerifyCallback(r)
+	assert.ErrorIs(t, err, webhook.ErrInvalidSignature)
+}
+
+func TestInsecureCallbackURL(t *testing.T) {
+	srv := dingtest.NewServer()
+	defer srv.Close()
+
+	client := newTestClient(t, srv)
+
+	_, err := client.Authenticate(NewAuth("+33612345678").WithCallback("http://example.com/callback"))
+	assert.ErrorIs(t, err, ErrInsecureCallbackURL)
+	assert.ErrorIs(t, err, ErrInvalidCallbackURL)
+
+	_, err = client.Authenticate(NewAuth("+33612345678").WithCallback("ftp://example.com/callback"))
+	assert.ErrorIs(t, err, ErrInvalidCallbackURL)
+
+	client, err = NewClient(Config{
+		CustomerUUID:             dingtest.CustomerUUID,
+		APIKey:                   dingtest.APIKey,
+		BaseURL:                  srv.URL,
+		AllowInsecureCallbackURL: true,
+	})
+	require.NoError(t, err)
+
+	_, err = client.Authenticate(NewAuth("+33612345678").WithCallback("http://localhost:8080/callback"))
+	assert.NoError(t, err)
+}
@@ -0,0 +1,36 @@
+package ding
+
+// Channel is a channel through which a code can be delivered.
+type Channel string
+
+var (
+	ChannelSMS      Channel = "sms"
+	ChannelWhatsApp Channel = "whatsapp"
+	ChannelVoice    Channel = "voice"
+)
+
+func (c Channel) String() string {
+	return string(c)
+}
+
+// isValidChannels reports whether channels only contains known channels, each
+// at most once.
+func isValidChannels(channels []Channel) bool {
+	seen := make(map[Channel]bool, len(channels))
+
+	for _, c := range channels {
+		switch c {
+		case ChannelSMS, ChannelWhatsApp, ChannelVoice:
+		default:
+			return false
+		}
+
+		if seen[c] {
+			return false
+		}
+
+		seen[c] = true
+	}
+
+	return true
+}
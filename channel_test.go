@@ -0,0 +1,30 @@
+package ding
+
+import (
+	"testing"
+
+	"github.com/ding-live/ding-go/pkg/dingtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChannels(t *testing.T) {
+	srv := dingtest.NewServer()
+	defer srv.Close()
+
+	client := newTestClient(t, srv)
+
+	auth, err := client.Authenticate(NewAuth("+33612345678"))
+	require.NoError(t, err)
+	assert.Equal(t, ChannelSMS, auth.Channel)
+
+	auth, err = client.Authenticate(NewAuth("+33612345678").WithChannels(ChannelWhatsApp, ChannelSMS, ChannelVoice))
+	require.NoError(t, err)
+	assert.Equal(t, ChannelWhatsApp, auth.Channel)
+
+	_, err = client.Authenticate(NewAuth("+33612345678").WithChannels(ChannelSMS, ChannelSMS))
+	assert.ErrorIs(t, err, ErrInvalidChannels)
+
+	_, err = client.Authenticate(NewAuth("+33612345678").WithChannels(Channel("pigeon")))
+	assert.ErrorIs(t, err, ErrInvalidChannels)
+}
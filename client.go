@@ -2,23 +2,58 @@ package ding
 
 import (
 	"context"
+	"crypto/tls"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"net/url"
+	"strings"
 	"time"
 
 	"github.com/ding-live/ding-go/internal/api"
+	"github.com/ding-live/ding-go/internal/fips"
+	"github.com/ding-live/ding-go/pkg/code"
+	"github.com/ding-live/ding-go/pkg/lowlevel"
 	"github.com/ding-live/ding-go/pkg/status"
+	"github.com/ding-live/ding-go/pkg/transport"
 	"github.com/google/uuid"
-	"github.com/nyaruka/phonenumbers"
 )
 
 // Client is the main Ding client.
 // It is used to interact with the Ding API.
 type Client struct {
-	api          api.API
-	customerUUID string
+	api                      *api.API
+	customerUUID             string
+	defaultPhoneRegion       string
+	phoneValidation          PhoneValidation
+	rejectVOIP               bool
+	optOutList               OptOutList
+	phoneRedaction           PhoneRedaction
+	waitOnRateLimit          bool
+	codeLength               int
+	callbackSecret           string
+	allowInsecureCallbackURL bool
+
+	// err is returned by every call when the client was derived with invalid
+	// options.
+	err error
+
+	// lifecycle is shared by the client and every client derived from it.
+	lifecycle *lifecycle
+
+	// dedup is nil unless Config.DeduplicationWindow is set.
+	dedup *dedup
+
+	abuse *abuseDetector
+
+	hooks Hooks
+
+	phoneCache *phoneCache
+
+	// deadlines holds the expiries set with AuthenticateOptions.TTL.
+	deadlines *deadlines
 }
 
 // Config is the configuration required to instanciate a new client
@@ -29,9 +64,60 @@ type Config struct {
 	// APIKey is your secret API key
 	APIKey string
 
+	// APIKeys spreads the calls of the client across several API keys of
+	// your account, in proportion to their weights, for when the rate limits
+	// of a single key are below your traffic. It replaces APIKey, which must
+	// then be left empty.
+	APIKeys []WeightedAPIKey
+
+	// FallbackAPIKey is a backup API key that the client switches to, for all
+	// its calls, as soon as the API refuses APIKey or APIKeys, for instance
+	// because the key was revoked during a rotation. The refused call is sent
+	// again with it, and Hooks.OnAPIKeyFailover is called.
+	//
+	// The switch is one-way: the primary keys are never tried again, even if
+	// the refusal was temporary, until a new client is created. Recreate the
+	// client from OnAPIKeyFailover once the primary key works again if you
+	// need to switch back.
+	//
+	// Defaults to no fallback key.
+	FallbackAPIKey string
+
+	// BaseURL is the URL of the Ding API that requests are sent to. It is
+	// mostly useful to point the client at a fake server such as the one
+	// provided by the dingtest package.
+	//
+	// Defaults to https://api.ding.live/v1.
+	BaseURL string
+
+	// APIVersion pins the version of the Ding API that the client talks to,
+	// such as "v1", so that a new version is only adopted deliberately. It
+	// must be one of SupportedAPIVersions. The Ding API is versioned by the
+	// path of its URLs, so BaseURL, when set, must end with the version.
+	//
+	// Defaults to the version of BaseURL, or to the newest version supported
+	// when BaseURL is empty.
+	APIVersion string
+
+	// Environments maps the names of your deployment environments, such as
+	// "staging" and "production", to their credentials and APIs. When set,
+	// CustomerUUID, APIKey and BaseURL must be left empty and Environment
+	// selects the environment used by the client.
+	Environments map[string]Environment
+
+	// Environment is the name of the environment of Environments used by the
+	// client, typically read from the environment of the process.
+	Environment string
+
 	// MaxNetworkRetries sets maximum number of times that the library will
 	// retry requests that appear to have failed due to an intermittent
-	// problem.
+	// problem. Since the Ding API can't tell a retry from a new request, the
+	// calls of the client, which are all POST requests, are only retried
+	// when the API didn't process them: when they failed before reaching
+	// it, such as when the connection is refused, or were rate limited or
+	// refused with a Retry-After header. Timeouts and server errors are not
+	// retried, so that retries never send a code twice or spend a check.
+	// Once retries run out, the error of the last response is returned.
 	//
 	// This value is a pointer to allow us to differentiate an unset versus
 	// empty value. Use ding.Int for an easy way to set this value.
@@ -39,11 +125,123 @@ type Config struct {
 	// Defaults to 3.
 	MaxNetworkRetries *int
 
+	// Backoff sets the delays between the retries of a request, and bounds
+	// the total time spent retrying it.
+	//
+	// Defaults to exponential delays starting at 1 second and capped at 30
+	// seconds, without jitter.
+	Backoff Backoff
+
+	// RetryBudget caps the retries of all the calls of the client, so that
+	// an outage of the Ding API isn't made worse by every call retrying on
+	// its own. The budget is shared by the clients derived with With.
+	//
+	// Defaults to no budget, where every call retries up to
+	// MaxNetworkRetries times.
+	RetryBudget RetryBudget
+
+	// ShouldRetry decides whether a failed attempt of a request is retried,
+	// for instance to never retry checks. It is only called for requests
+	// that are safe to send twice, while the context of the call isn't done
+	// and retries remain: requests with an idempotent method, such as GET
+	// requests made with Do, and POST requests that the API didn't process,
+	// as described in MaxNetworkRetries.
+	// See ShouldRetryFunc.
+	//
+	// Defaults to DefaultShouldRetry.
+	ShouldRetry ShouldRetryFunc
+
+	// Timeout bounds every call to the Ding API, retries included. It can be
+	// overridden for a single call with WithTimeout.
+	//
+	// Defaults to 0, which only relies on the context of the call.
+	Timeout time.Duration
+
+	// Timeouts overrides Timeout for some endpoints, keyed by their path
+	// relative to BaseURL: "authentication", "check", "retry", "status" or
+	// the path given to Do. For instance, authentications can be given more
+	// time than checks, which a user waits for. A zero duration disables the
+	// timeout of an endpoint. WithTimeout overrides every endpoint.
+	Timeouts map[string]time.Duration
+
+	// SigningKey is the key used to sign every request with an HMAC, for
+	// accounts on the request signing security tier. Signatures are
+	// timestamped with the clock of the Ding API, as given by its responses,
+	// so that a drifting local clock doesn't get them rejected.
+	//
+	// In FIPS 140 mode, it must be at least 14 bytes long.
+	//
+	// Defaults to no signature.
+	SigningKey string
+
 	// CustomHTTPClient is an HTTP client instance to use when making API requests.
 	//
 	// If left unset, it'll be set to a default HTTP client for the package.
 	CustomHTTPClient *http.Client
 
+	// TLS restricts the TLS versions, cipher suites and root CAs used by the
+	// default HTTP client. NewClient fails if it is set along with
+	// CustomHTTPClient or Transport, whose TLS settings are their own.
+	//
+	// Defaults to the TLS settings of Go.
+	TLS TLSPolicy
+
+	// DialContext opens the connections of the default HTTP client, for
+	// instance to apply egress policies. NewClient fails if it is set along
+	// with CustomHTTPClient or Transport.
+	//
+	// Defaults to a net.Dialer using Resolver.
+	DialContext func(ctx context.Context, network, addr string) (net.Conn, error)
+
+	// Resolver looks up the host of the Ding API for the default HTTP client,
+	// for instance to force an internal DNS server. It is ignored when
+	// DialContext is set, and NewClient fails if it is set along with
+	// CustomHTTPClient or Transport.
+	//
+	// Defaults to net.DefaultResolver.
+	Resolver *net.Resolver
+
+	// Transport sends the requests of the client to the Ding API instead of
+	// HTTP, for instance to go through a gateway or a test double. When set,
+	// CustomHTTPClient is ignored.
+	//
+	// Defaults to HTTP.
+	Transport transport.Transport
+
+	// MaxResponseSize is the maximum size in bytes of the response bodies read
+	// by the client. Larger responses, which can only come from a misbehaving
+	// proxy, fail with ErrInternal.
+	//
+	// Defaults to 4 MB.
+	MaxResponseSize int64
+
+	// Hooks are called by the client as it talks to the Ding API.
+	Hooks Hooks
+
+	// DeduplicationWindow makes Authenticate coalesce the calls made for the
+	// same phone number with the same options while one is in flight, and for
+	// this long after it succeeded, into a single authentication. It prevents
+	// double sends when users tap "send code" twice. Calls are only coalesced
+	// within a process, so route the requests of a given user to the same
+	// instance if you run several of them.
+	//
+	// Defaults to 0, which disables deduplication.
+	DeduplicationWindow time.Duration
+
+	// RejectDuplicates makes Authenticate return ErrDuplicateRequest for
+	// the calls made for the same phone number within DeduplicationWindow,
+	// whatever their options, instead of coalescing them. It lets you tell
+	// users that a code is already on its way.
+	//
+	// Defaults to false.
+	RejectDuplicates bool
+
+	// AbuseDetection raises alerts when suspicious patterns occur in the
+	// traffic of the client, such as many invalid checks for a phone number.
+	//
+	// Defaults to no detection.
+	AbuseDetection AbuseDetection
+
 	// LeveledLogger is the logger that the will be used to log errors,
 	// warnings, and informational messages.
 	//
@@ -55,47 +253,397 @@ type Config struct {
 	//
 	// Defaults to ding.Logger.
 	LeveledLogger LeveledLogger
+
+	// RedactPhoneNumbers controls how phone numbers appear in the messages
+	// logged by the client. The hooks and errors of the client never carry
+	// phone numbers.
+	//
+	// Defaults to PhoneRedactionNone.
+	RedactPhoneNumbers PhoneRedaction
+
+	// DefaultPhoneRegion is the two-letter ISO country code used to interpret
+	// phone numbers written in national format, such as "FR" to accept
+	// "06 12 34 56 78". Numbers are always sent to the API in E.164 format.
+	//
+	// It can be overridden for a single call with AuthenticateOptions.PhoneRegion.
+	// Defaults to no region, meaning that numbers must be in international
+	// format.
+	DefaultPhoneRegion string
+
+	// PhoneValidation controls how phone numbers are validated before being
+	// sent to the API. Use PhoneValidationLenient or PhoneValidationOff if your
+	// traffic includes newly allocated ranges that are rejected locally.
+	//
+	// Defaults to PhoneValidationStrict.
+	PhoneValidation PhoneValidation
+
+	// RejectVOIPNumbers makes Authenticate return ErrVOIPNumber for numbers in
+	// VOIP ranges, without sending them to the API. See
+	// PhoneNumber.LineType.
+	RejectVOIPNumbers bool
+
+	// OptOutList holds the numbers that Authenticate must never send codes
+	// to, returning ErrOptedOut instead. Numbers are added and removed with
+	// OptOut and OptIn, or directly in the list.
+	//
+	// Defaults to no list.
+	OptOutList OptOutList
+
+	// PhoneCacheSize is the number of phone numbers whose validation result
+	// is kept in memory, keyed on the raw input. Set it to a negative value to
+	// disable the cache.
+	//
+	// Defaults to 1024.
+	PhoneCacheSize int
+
+	// PhoneCacheTTL is how long validation results are cached.
+	//
+	// Defaults to 10 minutes.
+	PhoneCacheTTL time.Duration
+
+	// WaitOnRateLimit makes Authenticate and Retry wait until the time given by
+	// the API and try again once when they are rate limited, instead of
+	// returning the rate_limited status right away.
+	//
+	// The wait is bounded by the context of the call: if its deadline would be
+	// exceeded, or if it is cancelled while waiting, the rate limited result is
+	// returned.
+	WaitOnRateLimit bool
+
+	// CodeLength is the number of digits of the codes sent to your users, as
+	// configured on your account. Codes of a different length are rejected
+	// with ErrInvalidCodeFormat without calling the API.
+	//
+	// Defaults to 0, which only checks that codes are made of digits.
+	CodeLength int
+
+	// CallbackSecret is the secret shared with Ding to sign the callbacks sent
+	// to your callback URLs. It is required by VerifyCallback.
+	CallbackSecret string
+
+	// AllowInsecureCallbackURL allows callback URLs using the http scheme.
+	// Callbacks carry authentication results, so they should only be sent over
+	// https outside of development.
+	//
+	// Defaults to false.
+	AllowInsecureCallbackURL bool
+}
+
+// Backoff sets the delays between the retries of a request. The delay before
+// the n-th retry is Initial * Multiplier^(n-1), capped at MaxInterval, unless
+// the API asks for a given delay with a Retry-After header, which is capped at
+// MaxInterval as well.
+type Backoff struct {
+	// Initial is the delay before the first retry.
+	//
+	// Defaults to 1 second.
+	Initial time.Duration
+
+	// Multiplier is the factor applied to the delay after every retry.
+	//
+	// Defaults to 2, which is also used for values below 1.
+	Multiplier float64
+
+	// MaxInterval caps the delay between two attempts.
+	//
+	// Defaults to 30 seconds.
+	MaxInterval time.Duration
+
+	// MaxElapsedTime bounds the time spent on a request, from its first
+	// attempt: no retry is made that would start later, so that retries add
+	// a bounded latency. It applies on top of MaxNetworkRetries.
+	//
+	// Defaults to 0, which only relies on MaxNetworkRetries and the context
+	// of the call.
+	MaxElapsedTime time.Duration
+
+	// FullJitter waits a random delay between zero and the computed one,
+	// which spreads the retries of clients that failed at the same time.
+	//
+	// Defaults to false.
+	FullJitter bool
+}
+
+// WeightedAPIKey is an API key and its share of the calls of a client. A key
+// of weight 2 is sent twice as many calls as a key of weight 1.
+type WeightedAPIKey struct {
+	Key string
+
+	// Weight is the share of the calls sent with Key.
+	//
+	// Defaults to 1, which is also used for values below 1.
+	Weight int
+}
+
+// RetryBudget caps retries to a share of the calls of a client, such as 10%
+// extra load with a Ratio of 0.1. Every call earns Ratio retries and every
+// retry spends one; calls that fail once the budget is spent are not retried.
+type RetryBudget struct {
+	// Ratio is the number of retries allowed per call. A value of 0
+	// disables the budget.
+	Ratio float64
+
+	// MinRetries is the number of retries allowed at once regardless of
+	// Ratio, so that clients making few calls can still retry. The unspent
+	// retries never exceed it.
+	//
+	// Defaults to 10.
+	MinRetries int
+}
+
+// ShouldRetryFunc reports whether an attempt of a request should be retried.
+//
+// attempt is the number of attempts made so far, starting at 1. When a
+// response was received, res holds its status and headers, but not its body,
+// and res.Request the method and URL of the request. Otherwise res is nil and
+// err is a *url.Error giving them.
+type ShouldRetryFunc func(res *http.Response, err error, attempt int) bool
+
+// DefaultShouldRetry retries network errors, except certificate errors, rate
+// limits and server errors.
+func DefaultShouldRetry(res *http.Response, err error, attempt int) bool {
+	if err != nil {
+		return api.IsRetryable(0, err)
+	}
+
+	return api.IsRetryable(res.StatusCode, nil)
+}
+
+func (f ShouldRetryFunc) api() api.ShouldRetryFunc {
+	if f == nil {
+		return nil
+	}
+
+	return func(req transport.Request, res transport.Response, err error, attempt int) bool {
+		if err != nil {
+			var urlErr *url.Error
+			if !errors.As(err, &urlErr) {
+				err = &url.Error{Op: req.Method, URL: req.URL, Err: err}
+			}
+
+			return f(nil, err, attempt)
+		}
+
+		return f(httpResponse(req, res), nil, attempt)
+	}
+}
+
+// httpResponse returns the status and headers of res, received for req, as an
+// *http.Response without body.
+func httpResponse(req transport.Request, res transport.Response) *http.Response {
+	u, _ := url.Parse(req.URL)
+
+	return &http.Response{
+		Status:     fmt.Sprintf("%d %s", res.StatusCode, http.StatusText(res.StatusCode)),
+		StatusCode: res.StatusCode,
+		Header:     res.Header.Clone(),
+		Body:       http.NoBody,
+		Request:    &http.Request{Method: req.Method, URL: u},
+	}
+}
+
+// Error is returned for the errors reported by the Ding API when its response
+// identifies the request. It wraps one of the errors of the package, so that
+// errors.Is(err, ErrNegativeBalance) still works.
+type Error struct {
+	// Err is the error reported by the API, such as ErrNegativeBalance.
+	Err error
+
+	// RequestID identifies the request in the logs of Ding. Give it to Ding
+	// support when reporting an issue.
+	RequestID string
+
+	// Code is the code of the error response of the API, such as
+	// code.NegativeBalance, or empty if the error didn't come with one.
+	Code code.Code
+}
+
+func (e *Error) Error() string {
+	if e.RequestID == "" {
+		return e.Err.Error()
+	}
+
+	return fmt.Sprintf("%s (request ID %s)", e.Err, e.RequestID)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// withRequestID wraps err in an *Error if requestID is known.
+func withRequestID(err error, requestID string) error {
+	if requestID == "" {
+		return err
+	}
+
+	return &Error{Err: err, RequestID: requestID}
 }
 
 var (
-	ErrUnauthorized        = errors.New("unauthorized, please check your API key")
-	ErrInternal            = errors.New("an unhandled error occured")
-	ErrInvalidPhoneNumber  = errors.New("invalid phone number")
-	ErrInvalidCustomerUUID = errors.New("invalid account UUID")
-	ErrNegativeBalance     = errors.New("negative balance")
-	ErrUnsupportedRegion   = errors.New("unsupported region")
-	ErrInvalidAuthUUID     = errors.New("invalid authentication UUID")
-	ErrInvalidCallbackURL  = errors.New("invalid callback URL")
+	ErrUnauthorized         = errors.New("unauthorized, please check your API key")
+	ErrInternal             = errors.New("an unhandled error occured")
+	ErrInvalidPhoneNumber   = errors.New("invalid phone number")
+	ErrInvalidCustomerUUID  = errors.New("invalid account UUID")
+	ErrNegativeBalance      = errors.New("negative balance")
+	ErrUnsupportedRegion    = errors.New("unsupported region")
+	ErrInvalidAuthUUID      = errors.New("invalid authentication UUID")
+	ErrInvalidCallbackURL   = errors.New("invalid callback URL")
+	ErrInvalidChannels      = errors.New("invalid channels")
+	ErrInvalidCodeFormat    = errors.New("invalid code format")
+	ErrInvalidDeviceSignals = errors.New("invalid device signals")
+	ErrInvalidExtra         = errors.New("invalid extra request fields")
+	ErrInvalidIP            = errors.New("invalid IP address")
+	ErrInvalidTTL           = errors.New("invalid authentication TTL")
+	ErrCallbackTooLarge     = errors.New("callback body too large")
+	ErrClosed               = errors.New("client closed")
+	ErrQueued               = errors.New("authentication queued until the Ding API is reachable")
+	ErrOptedOut             = errors.New("phone number opted out")
+	ErrNoOptOutList         = errors.New("no opt-out list configured")
+	ErrDuplicateRequest     = errors.New("duplicate authentication request")
+	ErrPanic                = errors.New("panic in user-supplied function")
+
+	// ErrUnreachable is returned when no response could be obtained from the
+	// Ding API, even after retries. It wraps ErrInternal.
+	ErrUnreachable = fmt.Errorf("unable to reach the Ding API: %w", ErrInternal)
+
+	// errNotSent is returned when the request failed before being sent, so
+	// that the Ding API can't have processed it. It wraps ErrUnreachable.
+	errNotSent = fmt.Errorf("%w: request not sent", ErrUnreachable)
+
+	// ErrUnexpectedResponse is returned for responses that are not the JSON
+	// expected from the Ding API, such as the HTML error page of a proxy. The
+	// returned error wraps it with the content type and status of the
+	// response. It wraps ErrInternal.
+	ErrUnexpectedResponse = fmt.Errorf("unexpected response from the Ding API: %w", ErrInternal)
+
+	// ErrVOIPNumber is returned for VOIP numbers when Config.RejectVOIPNumbers
+	// is set. It wraps ErrInvalidPhoneNumber.
+	ErrVOIPNumber = fmt.Errorf("%w: VOIP numbers are not allowed", ErrInvalidPhoneNumber)
+
+	// ErrInsecureCallbackURL is returned for http callback URLs unless
+	// Config.AllowInsecureCallbackURL is set. It wraps ErrInvalidCallbackURL.
+	ErrInsecureCallbackURL = fmt.Errorf("%w: http is not allowed", ErrInvalidCallbackURL)
 )
 
 const apiBaseURL = "https://api.ding.live/v1"
 
 // NewClient returns a new Ding client with a `Config` object.
 func NewClient(cfg Config) (*Client, error) {
+	cfg, err := cfg.withEnvironment()
+	if err != nil {
+		return nil, err
+	}
+
 	if !isValidUUID(cfg.CustomerUUID) {
 		return nil, ErrInvalidCustomerUUID
 	}
 
-	logger := cfg.LeveledLogger
+	if len(cfg.APIKeys) > 0 && cfg.APIKey != "" {
+		return nil, fmt.Errorf("APIKey and APIKeys can't both be set")
+	}
+
+	apiKeys := make([]api.WeightedAPIKey, len(cfg.APIKeys))
+
+	for i, k := range cfg.APIKeys {
+		if k.Key == "" {
+			return nil, fmt.Errorf("APIKeys: empty key at index %d", i)
+		}
+
+		apiKeys[i] = api.WeightedAPIKey(k)
+	}
+
+	if fips.Enabled() && cfg.SigningKey != "" && len(cfg.SigningKey) < fips.MinKeySize {
+		return nil, fmt.Errorf("signing key too short for FIPS 140 mode: %d bytes, at least %d required", len(cfg.SigningKey), fips.MinKeySize)
+	}
+
+	if (cfg.DialContext != nil || cfg.Resolver != nil) && (cfg.CustomHTTPClient != nil || cfg.Transport != nil) {
+		return nil, fmt.Errorf("dialer and resolver can't be applied to a custom HTTP client or transport")
+	}
+
+	dial := cfg.DialContext
+
+	if dial == nil && cfg.Resolver != nil {
+		dial = (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+			Resolver:  cfg.Resolver,
+		}).DialContext
+	}
+
+	var tlsConfig *tls.Config
+
+	if !cfg.TLS.isZero() {
+		if cfg.CustomHTTPClient != nil || cfg.Transport != nil {
+			return nil, fmt.Errorf("TLS policy can't be applied to a custom HTTP client or transport")
+		}
+
+		tlsConfig, err = cfg.TLS.config()
+		if err != nil {
+			return nil, fmt.Errorf("invalid TLS policy: %w", err)
+		}
+	}
+
+	logger := newSafeLogger(cfg.LeveledLogger)
 
 	if logger == nil {
 		logger = &DefaultLeveledLogger
 	}
 
+	logger = newRedactingLogger(logger, cfg.RedactPhoneNumbers)
+
+	baseURL, err := versionedBaseURL(cfg.BaseURL, cfg.APIVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	if baseURL == "" {
+		baseURL = apiBaseURL
+	}
+
 	api, err := api.New(api.Config{
-		BaseURL:           apiBaseURL,
+		BaseURL:           baseURL,
 		APIKey:            cfg.APIKey,
+		APIKeys:           apiKeys,
+		FallbackAPIKey:    cfg.FallbackAPIKey,
+		OnFailover:        cfg.Hooks.OnAPIKeyFailover,
 		MaxNetworkRetries: cfg.MaxNetworkRetries,
+		Backoff:           api.Backoff(cfg.Backoff),
+		RetryBudget:       api.RetryBudget(cfg.RetryBudget),
+		ShouldRetry:       cfg.ShouldRetry.api(),
+		Timeout:           cfg.Timeout,
+		Timeouts:          cfg.Timeouts,
+		SigningKey:        cfg.SigningKey,
 		CustomHTTPClient:  cfg.CustomHTTPClient,
+		TLSConfig:         tlsConfig,
+		DialContext:       dial,
 		LeveledLogger:     logger,
+		Transport:         cfg.Transport,
+		MaxBodySize:       cfg.MaxResponseSize,
+		OnRequest:         cfg.Hooks.onRequest(),
+		OnDeprecation:     cfg.Hooks.onDeprecation(),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("create API client: %w", err)
 	}
 
 	return &Client{
-		customerUUID: cfg.CustomerUUID,
-		api:          *api,
+		customerUUID:             cfg.CustomerUUID,
+		api:                      api,
+		defaultPhoneRegion:       cfg.DefaultPhoneRegion,
+		phoneValidation:          cfg.PhoneValidation,
+		rejectVOIP:               cfg.RejectVOIPNumbers,
+		optOutList:               cfg.OptOutList,
+		hooks:                    cfg.Hooks,
+		phoneRedaction:           cfg.RedactPhoneNumbers,
+		waitOnRateLimit:          cfg.WaitOnRateLimit,
+		codeLength:               cfg.CodeLength,
+		callbackSecret:           cfg.CallbackSecret,
+		allowInsecureCallbackURL: cfg.AllowInsecureCallbackURL,
+		lifecycle:                newLifecycle(),
+		dedup:                    newDedup(cfg.DeduplicationWindow, cfg.RejectDuplicates),
+		abuse:                    newAbuseDetector(cfg.AbuseDetection, cfg.RedactPhoneNumbers),
+		phoneCache:               newPhoneCache(cfg.PhoneCacheSize, cfg.PhoneCacheTTL),
+		deadlines:                newDeadlines(),
 	}, nil
 }
 
@@ -116,58 +664,318 @@ func (d DeviceType) String() string {
 // is required. Other options are optional but recommended because they are used by
 // the Ding antispam system.
 type AuthenticateOptions struct {
-	PhoneNumber     string
-	IP              *string
-	DeviceID        *string
-	DeviceType      *DeviceType
-	AppVersion      *string
+	PhoneNumber string
+
+	// PhoneRegion overrides Config.DefaultPhoneRegion for this call. It only
+	// affects how the number is parsed: the Ding API has no routing hint and
+	// picks the gateway from the country of the E.164 number it receives.
+	PhoneRegion string
+
+	// Device describes the device of the user. Its fields take precedence
+	// over IP, DeviceID, DeviceType and AppVersion.
+	Device *DeviceSignals
+
+	// Deprecated: use Device.IP instead.
+	IP *string
+	// Deprecated: use Device.ID instead.
+	DeviceID *string
+	// Deprecated: use Device.Type instead.
+	DeviceType *DeviceType
+	// Deprecated: use Device.AppVersion instead.
+	AppVersion *string
+
 	CallbackURL     *string
 	IsReturningUser bool
+
+	// Channels is the ordered list of channels to try to deliver the code
+	// through, for instance WhatsApp, then SMS, then a voice call. Each channel
+	// can only appear once.
+	//
+	// Defaults to the channels configured on your account.
+	Channels []Channel
+
+	// Extra holds fields added as is to the JSON body of the request, for
+	// parameters of the Ding API that the SDK doesn't model yet, such as
+	// beta features enabled on your account. Each value is encoded with
+	// encoding/json. Fields that the SDK already sends, such as
+	// "phone_number", can't be overridden.
+	Extra map[string]interface{}
+
+	// Consent is the consent of the user to receive the code. It is not
+	// sent to the Ding API but returned on the Authentication.
+	Consent *Consent
+
+	// TTL shortens the lifetime of the code below the default of your
+	// account, for security-sensitive flows. It must be at least
+	// MinAuthenticationTTL, and has no effect if longer than the default.
+	//
+	// The Ding API has no such setting, so the TTL is enforced by the client:
+	// past it, Check reports status.CheckExpiredAuth without calling the API.
+	// Only the client that sent the code, and the clients derived from it,
+	// know the TTL: codes checked by another process, or after a restart,
+	// are accepted until the default expiry. Use the session package, whose
+	// store keeps the shortened expiry, to enforce it across processes.
+	TTL time.Duration
 }
 
+// MinAuthenticationTTL is the shortest AuthenticateOptions.TTL, below which
+// users can hardly receive and type their code in time.
+const MinAuthenticationTTL = 30 * time.Second
+
 // Authentication is the result of an authentication request.
 type Authentication struct {
 	AuthenticationUUID string
 	Status             status.Auth
 	CreatedAt          time.Time
 	ExpiresAt          time.Time
+
+	// NextRetryAt is the earliest time at which Retry can send a new code.
+	NextRetryAt time.Time
+
+	// PhoneNumber is the normalized number the code was sent to. It is only
+	// set on the result of Authenticate.
+	PhoneNumber PhoneNumber
+
+	// Channel is the channel through which the code was delivered, or an empty
+	// string if it is not known yet.
+	Channel Channel
+
+	// RequestID identifies the request that returned the authentication in
+	// the logs of Ding. Give it to Ding support when reporting an issue.
+	RequestID string
+
+	// Warnings are the non-fatal issues with the authentication, such as a
+	// number that may not receive SMS.
+	Warnings []Warning
+
+	// Raw holds the top-level fields of the response that the SDK doesn't
+	// model yet, so that they can be read as soon as the API adds them. It
+	// is nil when there are none.
+	Raw map[string]json.RawMessage
+
+	// Consent is the consent given with AuthenticateOptions.Consent, with its
+	// defaults set. It is only set on the result of Authenticate.
+	Consent *Consent
+
+	// receivedAt is the local time at which the authentication was received,
+	// only set by Authenticate. Since the authentication was created then, it
+	// is used to compensate for clock skew when the response had no Date
+	// header.
+	receivedAt time.Time
+
+	// clockOffset is the offset of the clock of the API when the
+	// authentication was received, known when hasClockOffset is set.
+	clockOffset    time.Duration
+	hasClockOffset bool
 }
 
 // AuthenticateWithContext performs an authentication request against the Ding API that
 // can be cancelled with a context. Authentication requests allow you to send a message to
 // a given phone number with a code that the user will have to enter in your app.
 func (c *Client) AuthenticateWithContext(ctx context.Context, opt AuthenticateOptions) (*Authentication, error) {
-	if !isValidNumber(opt.PhoneNumber) {
-		return nil, ErrInvalidPhoneNumber
+	c = c.withContext(ctx)
+
+	if err := c.enter(); err != nil {
+		return nil, err
+	}
+	defer c.leave()
+
+	region := opt.PhoneRegion
+
+	if region == "" {
+		region = c.defaultPhoneRegion
+	}
+
+	phoneNumber, err := c.phoneCache.normalize(opt.PhoneNumber, region, c.phoneValidation)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.rejectVOIP && phoneNumber.LineType == LineTypeVOIP {
+		return nil, ErrVOIPNumber
+	}
+
+	if err := c.checkOptOut(ctx, phoneNumber); err != nil {
+		return nil, err
 	}
 
 	if opt.CallbackURL != nil {
-		if !isValidURL(*opt.CallbackURL) {
-			return nil, ErrInvalidCallbackURL
+		if err := c.validateCallbackURL(*opt.CallbackURL); err != nil {
+			return nil, err
 		}
 	}
 
+	if !isValidChannels(opt.Channels) {
+		return nil, ErrInvalidChannels
+	}
+
+	device, err := opt.deviceSignals().normalize()
+	if err != nil {
+		return nil, err
+	}
+
+	if opt.TTL != 0 && opt.TTL < MinAuthenticationTTL {
+		return nil, ErrInvalidTTL
+	}
+
+	var consent *Consent
+
+	if opt.Consent != nil {
+		cons, err := opt.Consent.normalize(time.Now())
+		if err != nil {
+			return nil, err
+		}
+
+		consent = &cons
+	}
+
 	req := api.AuthRequest{
-		PhoneNumber:     opt.PhoneNumber,
+		PhoneNumber:     phoneNumber.E164,
 		CustomerUUID:    c.customerUUID,
-		IP:              opt.IP,
-		DeviceID:        opt.DeviceID,
-		AppVersion:      opt.AppVersion,
+		IP:              optionalString(device.IP),
+		DeviceID:        optionalString(device.ID),
+		DeviceType:      optionalString(device.Type.String()),
+		AppVersion:      optionalString(device.AppVersion),
+		OSVersion:       optionalString(device.OSVersion),
+		DeviceModel:     optionalString(device.Model),
+		UserAgent:       optionalString(device.UserAgent),
 		CallbackURL:     opt.CallbackURL,
 		IsReturningUser: &opt.IsReturningUser,
 	}
 
-	if opt.DeviceType != nil {
-		req.DeviceType = String(opt.DeviceType.String())
+	for _, ch := range opt.Channels {
+		req.Channels = append(req.Channels, ch.String())
+	}
+
+	backend := c.api
+	if len(opt.Extra) > 0 {
+		if err := api.CheckExtra(req, opt.Extra); err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidExtra, err)
+		}
+
+		backend = backend.WithExtra(opt.Extra)
+	}
+
+	send := func() (*Authentication, error) {
+		res, err := c.authenticate(ctx, backend, req)
+		if err != nil {
+			return nil, err
+		}
+
+		if c.waitOnRateLimit && res.Success.Status == status.AuthRateLimited && waitUntil(ctx, res.Success.NextRetryAt) {
+			if res, err = c.authenticate(ctx, backend, req); err != nil {
+				return nil, err
+			}
+		}
+
+		auth := &Authentication{
+			AuthenticationUUID: res.Success.AuthenticationUUID,
+			Status:             res.Success.Status,
+			CreatedAt:          res.Success.CreatedAt,
+			ExpiresAt:          res.Success.ExpiresAt,
+			NextRetryAt:        res.Success.NextRetryAt,
+			PhoneNumber:        phoneNumber,
+			Channel:            Channel(res.Success.Channel),
+			RequestID:          res.RequestID,
+			Raw:                res.Raw,
+			Warnings:           append(phoneWarnings(phoneNumber), responseWarnings(res.Deprecation, res.Sunset)...),
+			receivedAt:         time.Now(),
+			clockOffset:        res.ClockOffset,
+			hasClockOffset:     res.HasClockOffset,
+		}
+
+		if err := c.protect("abuse alert hook", func() { c.abuse.authenticated(auth) }); err != nil {
+			return nil, err
+		}
+
+		if err := c.protect("authentication blocked hook", func() { c.hooks.authenticated(auth) }); err != nil {
+			return nil, err
+		}
+
+		return auth, nil
 	}
 
-	res, err := c.api.Authentication(ctx, req)
+	var auth *Authentication
+
+	if c.dedup == nil {
+		auth, err = send()
+	} else {
+		var key string
+		if key, err = c.dedup.key(req, opt.Extra); err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidExtra, err)
+		}
+
+		auth, err = c.dedup.do(ctx, key, send)
+	}
+
+	if err != nil || consent == nil && opt.TTL == 0 {
+		return auth, err
+	}
+
+	// Deduplicated calls share their result, but not their options.
+	a := *auth
+	a.Consent = consent
+
+	if expiresAt := a.CreatedAt.Add(opt.TTL); opt.TTL != 0 && expiresAt.Before(a.ExpiresAt) {
+		a.ExpiresAt = expiresAt
+
+		now := time.Now()
+		c.deadlines.set(a.AuthenticationUUID, now.Add(a.ExpiresIn(now)))
+	}
+
+	return &a, nil
+}
+
+// authenticate sends req through backend, the API of c with the extra fields
+// of the call, and returns its successful response.
+func (c *Client) authenticate(ctx context.Context, backend *api.API, req api.AuthRequest) (*api.AuthenticationResponse, error) {
+	res, err := backend.Authentication(ctx, req)
 	if err != nil {
 		return nil, apiErrToErr(err)
 	}
 
 	if res.Error != nil {
-		return nil, apiErrorCodeToErr(res.Error.Code)
+		return nil, responseErr(res.Error.Code, res.RequestID)
+	}
+
+	return res, nil
+}
+
+// Authenticate performs an authentication request against the Ding API. Authentication
+// requests allow you to send a message to a given phone number with a code that the user
+// will have to enter in your app.
+func (c *Client) Authenticate(opt AuthenticateOptions) (*Authentication, error) {
+	return c.AuthenticateWithContext(context.Background(), opt)
+}
+
+// AuthenticationStatusWithContext retrieves the current state of an authentication
+// from the Ding API with a request that can be cancelled with a context.
+//
+// The returned Authentication holds all the API exposes about an
+// authentication: its status, channel, creation, expiry and next retry times.
+// The API doesn't return its template, checks or delivery attempts.
+func (c *Client) AuthenticationStatusWithContext(ctx context.Context, authUUID string) (*Authentication, error) {
+	c = c.withContext(ctx)
+
+	if err := c.enter(); err != nil {
+		return nil, err
+	}
+	defer c.leave()
+
+	if !isValidUUID(authUUID) {
+		return nil, ErrInvalidAuthUUID
+	}
+
+	res, err := c.api.Status(ctx, api.StatusRequest{
+		CustomerUUID:       c.customerUUID,
+		AuthenticationUUID: authUUID,
+	})
+	if err != nil {
+		return nil, apiErrToErr(err)
+	}
+
+	if res.Error != nil {
+		return nil, responseErr(res.Error.Code, res.RequestID)
 	}
 
 	return &Authentication{
@@ -175,14 +983,20 @@ func (c *Client) AuthenticateWithContext(ctx context.Context, opt AuthenticateOp
 		Status:             res.Success.Status,
 		CreatedAt:          res.Success.CreatedAt,
 		ExpiresAt:          res.Success.ExpiresAt,
+		NextRetryAt:        res.Success.NextRetryAt,
+		Channel:            Channel(res.Success.Channel),
+		RequestID:          res.RequestID,
+		Raw:                res.Raw,
+		Warnings:           responseWarnings(res.Deprecation, res.Sunset),
+		clockOffset:        res.ClockOffset,
+		hasClockOffset:     res.HasClockOffset,
 	}, nil
 }
 
-// Authenticate performs an authentication request against the Ding API. Authentication
-// requests allow you to send a message to a given phone number with a code that the user
-// will have to enter in your app.
-func (c *Client) Authenticate(opt AuthenticateOptions) (*Authentication, error) {
-	return c.AuthenticateWithContext(context.Background(), opt)
+// AuthenticationStatus retrieves the current state of an authentication from the
+// Ding API.
+func (c *Client) AuthenticationStatus(authUUID string) (*Authentication, error) {
+	return c.AuthenticationStatusWithContext(context.Background(), authUUID)
 }
 
 // ----------------------------------------------------------------------------
@@ -191,16 +1005,42 @@ func (c *Client) Authenticate(opt AuthenticateOptions) (*Authentication, error)
 type Check struct {
 	AuthenticationUUID string
 	Status             status.Check
+
+	// RequestID identifies the request in the logs of Ding.
+	RequestID string
+
+	// Warnings are the non-fatal issues with the check.
+	Warnings []Warning
+
+	// Raw holds the top-level fields of the response that the SDK doesn't
+	// model yet. See Authentication.Raw.
+	Raw map[string]json.RawMessage
 }
 
 // CheckWithContext performs a check request against the Ding API that can be cancelled
 // with a context. Check requests allow you to enter the code that the user entered in
 // your app to check if it is valid.
 func (c *Client) CheckWithContext(ctx context.Context, authUUID string, code string) (*Check, error) {
+	c = c.withContext(ctx)
+
+	if err := c.enter(); err != nil {
+		return nil, err
+	}
+	defer c.leave()
+
 	if !isValidUUID(authUUID) {
 		return nil, ErrInvalidAuthUUID
 	}
 
+	if !isValidCode(code, c.codeLength) {
+		return nil, ErrInvalidCodeFormat
+	}
+
+	// The API would accept the code past the TTL of the authentication.
+	if c.deadlines.expired(authUUID, time.Now()) {
+		return &Check{AuthenticationUUID: authUUID, Status: status.CheckExpiredAuth}, nil
+	}
+
 	res, err := c.api.Check(ctx, api.CheckRequest{
 		CustomerUUID:       c.customerUUID,
 		AuthenticationUUID: authUUID,
@@ -211,13 +1051,22 @@ func (c *Client) CheckWithContext(ctx context.Context, authUUID string, code str
 	}
 
 	if res.Error != nil {
-		return nil, apiErrorCodeToErr(res.Error.Code)
+		return nil, responseErr(res.Error.Code, res.RequestID)
 	}
 
-	return &Check{
+	check := &Check{
 		AuthenticationUUID: res.Success.AuthenticationUUID,
 		Status:             res.Success.Status,
-	}, nil
+		RequestID:          res.RequestID,
+		Raw:                res.Raw,
+		Warnings:           responseWarnings(res.Deprecation, res.Sunset),
+	}
+
+	if err := c.protect("abuse alert hook", func() { c.abuse.checked(check) }); err != nil {
+		return nil, err
+	}
+
+	return check, nil
 }
 
 // Check performs a check request against the Ding API.
@@ -226,39 +1075,164 @@ func (c *Client) Check(authUUID string, code string) (*Check, error) {
 	return c.CheckWithContext(context.Background(), authUUID, code)
 }
 
+// VerifyCode checks the code entered by the user and reports whether it is
+// valid. Any other status, such as an invalid code or an expired
+// authentication, returns false. Errors are reserved for invalid arguments
+// and failures to reach the Ding API.
+func (c *Client) VerifyCode(ctx context.Context, authUUID string, code string) (bool, error) {
+	check, err := c.CheckWithContext(ctx, authUUID, code)
+	if err != nil {
+		return false, err
+	}
+
+	return check.Status.IsSuccess(), nil
+}
+
+const (
+	defaultCheckMaxAttempts = 3
+	defaultCheckBackoff     = 500 * time.Millisecond
+)
+
+// CheckRetryConfig configures how CheckWithRetry retries transient failures.
+type CheckRetryConfig struct {
+	// MaxAttempts is the maximum number of check requests performed.
+	//
+	// Defaults to 3.
+	MaxAttempts int
+
+	// Backoff is the delay before the second attempt. It doubles after every
+	// subsequent attempt.
+	//
+	// Defaults to 500 milliseconds.
+	Backoff time.Duration
+}
+
+// CheckWithRetry performs a check request like CheckWithContext, but retries it
+// with the same code when the Ding API could not be reached. Once attempts are
+// exhausted, ErrUnreachable is returned, which lets callers tell a transient
+// failure apart from an invalid code. The attempts replace the retries of
+// Config.MaxNetworkRetries, which are disabled for the call.
+//
+// An attempt that failed in transit may still have been processed by the API,
+// in which case the code has been consumed and the next attempt reports that
+// the authentication is already validated. Since the client can't tell whether
+// the earlier attempt or another call validated it, status.CheckAlreadyValidated
+// is returned as is and must not be treated as a valid code.
+func (c *Client) CheckWithRetry(ctx context.Context, authUUID string, code string, cfg CheckRetryConfig) (*Check, error) {
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = defaultCheckMaxAttempts
+	}
+
+	if cfg.Backoff <= 0 {
+		cfg.Backoff = defaultCheckBackoff
+	}
+
+	c = c.With(func(c *Client) { c.api = c.api.WithMaxRetries(0) })
+	backoff := cfg.Backoff
+
+	for attempt := 1; ; attempt++ {
+		check, err := c.CheckWithContext(ctx, authUUID, code)
+		if err == nil {
+			return check, nil
+		}
+
+		if !errors.Is(err, ErrUnreachable) || attempt == cfg.MaxAttempts {
+			return nil, err
+		}
+
+		if !waitUntil(ctx, time.Now().Add(backoff)) {
+			return nil, err
+		}
+
+		backoff *= 2
+	}
+}
+
 // ----------------------------------------------------------------------------
 
 // Retry is the result of a retry request.
 type Retry struct {
 	AuthenticationUUID string
 	Status             status.Retry
+
+	// NextRetryAt is the earliest time at which another retry can be
+	// requested.
+	NextRetryAt time.Time
+
+	// RemainingRetry is the number of retries left for the authentication.
+	RemainingRetry int
+
+	// RequestID identifies the request in the logs of Ding.
+	RequestID string
+
+	// Warnings are the non-fatal issues with the retry.
+	Warnings []Warning
+
+	// Raw holds the top-level fields of the response that the SDK doesn't
+	// model yet. See Authentication.Raw.
+	Raw map[string]json.RawMessage
+
+	// clockOffset is the offset of the clock of the API when the retry was
+	// received, used to compensate for clock skew.
+	clockOffset time.Duration
 }
 
 // RetryWithContext performs a retry request against the Ding API that can be cancelled with
 // a context.
 func (c *Client) RetryWithContext(ctx context.Context, authUUID string) (*Retry, error) {
+	c = c.withContext(ctx)
+
+	if err := c.enter(); err != nil {
+		return nil, err
+	}
+	defer c.leave()
+
 	if !isValidUUID(authUUID) {
 		return nil, ErrInvalidAuthUUID
 	}
 
-	res, err := c.api.Retry(ctx, api.RetryRequest{
+	req := api.RetryRequest{
 		CustomerUUID:       c.customerUUID,
 		AuthenticationUUID: authUUID,
-	})
+	}
+
+	res, err := c.retry(ctx, req)
 	if err != nil {
-		return nil, apiErrToErr(err)
+		return nil, err
 	}
 
-	if res.Error != nil {
-		return nil, apiErrorCodeToErr(res.Error.Code)
+	if c.waitOnRateLimit && res.Success.Status == status.RetryRateLimited && waitUntil(ctx, res.Success.NextRetryAt) {
+		if res, err = c.retry(ctx, req); err != nil {
+			return nil, err
+		}
 	}
 
 	return &Retry{
 		AuthenticationUUID: res.Success.AuthenticationUUID,
 		Status:             res.Success.Status,
+		NextRetryAt:        res.Success.NextRetryAt,
+		RemainingRetry:     res.Success.RemainingRetry,
+		RequestID:          res.RequestID,
+		Raw:                res.Raw,
+		Warnings:           responseWarnings(res.Deprecation, res.Sunset),
+		clockOffset:        res.ClockOffset,
 	}, nil
 }
 
+// retry sends req and returns its successful response.
+func (c *Client) retry(ctx context.Context, req api.RetryRequest) (*api.RetryResponse, error) {
+	res, err := c.api.Retry(ctx, req)
+	if err != nil {
+		return nil, apiErrToErr(err)
+	}
+
+	if res.Error != nil {
+		return nil, responseErr(res.Error.Code, res.RequestID)
+	}
+
+	return res, nil
+}
+
 // Retry performs a retry request against the Ding API. Retry requests allow you to send
 // a new SMS to the user with a new code, using the initial authentication UUID.
 func (c *Client) Retry(authUUID string) (*Retry, error) {
@@ -267,28 +1241,105 @@ func (c *Client) Retry(authUUID string) (*Retry, error) {
 
 // ----------------------------------------------------------------------------
 
+// Do sends a request to an endpoint of the Ding API that the SDK does not
+// support yet, using the authentication, retries and logging configured on the
+// client. path is relative to the base URL, such as "authentication".
+//
+// in is encoded as the JSON body of the request unless nil, and the body of a
+// successful response is decoded into out unless nil. Unlike the other methods,
+// Do does not add the customer UUID to the payload. Errors returned by the API
+// are mapped to the same errors as the other methods of the client.
+func (c *Client) Do(ctx context.Context, method, path string, in, out interface{}) error {
+	c = c.withContext(ctx)
+
+	if err := c.enter(); err != nil {
+		return err
+	}
+	defer c.leave()
+
+	res, err := c.api.Do(ctx, method, path, in, out)
+	if err != nil {
+		return apiErrToErr(err)
+	}
+
+	if res.Error != nil {
+		return responseErr(res.Error.Code, res.RequestID)
+	}
+
+	return nil
+}
+
+// LowLevel returns a client sending raw requests to the Ding API with the API
+// keys, retries, hooks and logger of c, for callers who need full control over
+// the payloads. See package lowlevel.
+func (c *Client) LowLevel() *lowlevel.Client {
+	return lowlevel.New(c.api)
+}
+
+// ----------------------------------------------------------------------------
+
 func apiErrToErr(err error) error {
-	switch err {
-	case api.ErrUnauthorized:
-		return ErrUnauthorized
+	var (
+		sentinel   error
+		unexpected *api.UnexpectedResponseError
+		panicked   *api.PanicError
+		extra      *api.ExtraFieldError
+	)
+
+	switch {
+	case errors.As(err, &unexpected):
+		sentinel = fmt.Errorf("%w: %s", ErrUnexpectedResponse, unexpected.Reason)
+	case errors.As(err, &panicked):
+		sentinel = fmt.Errorf("%w: %s", ErrPanic, panicked)
+	case errors.As(err, &extra):
+		sentinel = fmt.Errorf("%w: %s", ErrInvalidExtra, extra)
+	case errors.Is(err, api.ErrUnauthorized):
+		sentinel = ErrUnauthorized
+	case errors.Is(err, api.ErrNotSent):
+		sentinel = errNotSent
+	case errors.Is(err, api.ErrUnreachable):
+		sentinel = ErrUnreachable
 	default:
-		return ErrInternal
+		sentinel = ErrInternal
 	}
+
+	var resErr *api.ResponseError
+	if errors.As(err, &resErr) {
+		return withRequestID(sentinel, resErr.RequestID)
+	}
+
+	return sentinel
 }
 
-func apiErrorCodeToErr(code api.ErrorCode) error {
-	switch code {
-	case api.ErrorCodeInvalidPhoneNumber:
+// protect calls fn, a function that calls code supplied by the user of the
+// client and is described by name, and returns an error wrapping ErrPanic if
+// it panics.
+func (c *Client) protect(name string, fn func()) error {
+	if err := c.api.Protect(name, fn); err != nil {
+		return apiErrToErr(err)
+	}
+
+	return nil
+}
+
+// responseErr returns the error for an error response of the API.
+func responseErr(c code.Code, requestID string) error {
+	return &Error{Err: apiErrorCodeToErr(c), RequestID: requestID, Code: c}
+}
+
+func apiErrorCodeToErr(c code.Code) error {
+	switch c {
+	case code.InvalidPhoneNumber:
 		return ErrInvalidPhoneNumber
-	case api.ErrorCodeAccountInvalid:
+	case code.AccountInvalid:
 		return ErrInvalidCustomerUUID
-	case api.ErrorCodeNegativeBalance:
+	case code.NegativeBalance:
 		return ErrNegativeBalance
-	case api.ErrorCodeInvalidLine:
+	case code.InvalidLine:
 		return ErrInvalidPhoneNumber
-	case api.ErrorCodeUnsupportedRegion:
+	case code.UnsupportedRegion:
 		return ErrUnsupportedRegion
-	case api.ErrorCodeInvalidAuthUUID:
+	case code.InvalidAuthUUID:
 		return ErrInvalidAuthUUID
 	default:
 		return ErrInternal
@@ -297,19 +1348,30 @@ func apiErrorCodeToErr(code api.ErrorCode) error {
 
 // ----------------------------------------------------------------------------
 
-func isValidNumber(phoneNumber string) bool {
-	num, err := phonenumbers.Parse(phoneNumber, "ZZ")
-	if err != nil {
+// waitUntil blocks until t and reports whether it was reached. It returns false
+// right away if t is unknown or if ctx would expire before t.
+func waitUntil(ctx context.Context, t time.Time) bool {
+	if t.IsZero() {
 		return false
 	}
 
-	if !phonenumbers.IsValidNumber(num) {
+	if deadline, ok := ctx.Deadline(); ok && deadline.Before(t) {
 		return false
 	}
 
-	return true
+	timer := time.NewTimer(time.Until(t))
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
 }
 
+// ----------------------------------------------------------------------------
+
 func isValidUUID(customerUUID string) bool {
 	if _, err := uuid.Parse(customerUUID); err != nil {
 		return false
@@ -318,6 +1380,22 @@ func isValidUUID(customerUUID string) bool {
 	return true
 }
 
+// isValidCode reports whether code is only made of digits and, if length is
+// positive, has exactly length digits.
+func isValidCode(code string, length int) bool {
+	if code == "" || (length > 0 && len(code) != length) {
+		return false
+	}
+
+	for _, r := range code {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
 func isValidURL(u string) bool {
 	if _, err := url.ParseRequestURI(u); err != nil {
 		return false
@@ -325,3 +1403,24 @@ func isValidURL(u string) bool {
 
 	return true
 }
+
+func (c *Client) validateCallbackURL(u string) error {
+	if !isValidURL(u) {
+		return ErrInvalidCallbackURL
+	}
+
+	parsed, _ := url.Parse(u)
+
+	switch strings.ToLower(parsed.Scheme) {
+	case "https":
+		return nil
+	case "http":
+		if c.allowInsecureCallbackURL {
+			return nil
+		}
+
+		return ErrInsecureCallbackURL
+	default:
+		return ErrInvalidCallbackURL
+	}
+}
@@ -1,12 +1,28 @@
 package ding
 
 import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
+	"syscall"
 	"testing"
+	"time"
 
+	"github.com/ding-live/ding-go/internal/fips"
+	"github.com/ding-live/ding-go/pkg/code"
+	"github.com/ding-live/ding-go/pkg/dingtest"
+	"github.com/ding-live/ding-go/pkg/status"
+	"github.com/ding-live/ding-go/pkg/transport"
 	"github.com/google/uuid"
 	"github.com/nyaruka/phonenumbers"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -59,3 +75,566 @@ func TestParseCallbackURL(t *testing.T) {
 	})
 	require.NotErrorIs(t, authErr2, ErrInvalidCallbackURL)
 }
+
+func TestWaitOnRateLimit(t *testing.T) {
+	calls := 0
+
+	hc := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+
+		st := status.AuthPending
+		if calls == 1 {
+			st = status.AuthRateLimited
+		}
+
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprintf(w, `{
+			"authentication_uuid": "%s",
+			"status": "%s",
+			"next_retry_at": "%s"
+		}`, uuid.New().String(), st, time.Now().Add(50*time.Millisecond).UTC().Format(time.RFC3339Nano))
+	}))
+	defer hc.Close()
+
+	client, err := NewClient(Config{
+		CustomerUUID:    uuid.New().String(),
+		BaseURL:         hc.URL,
+		WaitOnRateLimit: true,
+	})
+	require.NoError(t, err)
+
+	pn := phonenumbers.GetExampleNumber("US")
+
+	auth, err := client.Authenticate(AuthenticateOptions{
+		PhoneNumber: phonenumbers.Format(pn, phonenumbers.E164),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, status.AuthPending, auth.Status)
+	assert.Equal(t, 2, calls)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	calls = 0
+
+	auth, err = client.AuthenticateWithContext(ctx, AuthenticateOptions{
+		PhoneNumber: phonenumbers.Format(pn, phonenumbers.E164),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, status.AuthRateLimited, auth.Status)
+	assert.Equal(t, 1, calls)
+}
+
+func TestCheckWithRetry(t *testing.T) {
+	calls := 0
+	authUUID := uuid.New().String()
+
+	hc := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+
+		if calls == 1 {
+			conn, _, err := w.(http.Hijacker).Hijack()
+			require.NoError(t, err)
+			conn.Close()
+			return
+		}
+
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprintf(w, `{"authentication_uuid": "%s", "status": "already_validated"}`, authUUID)
+	}))
+	defer hc.Close()
+
+	client, err := NewClient(Config{
+		CustomerUUID:      uuid.New().String(),
+		BaseURL:           hc.URL,
+		MaxNetworkRetries: Int(0),
+	})
+	require.NoError(t, err)
+
+	_, err = client.Check(authUUID, "1234")
+	require.ErrorIs(t, err, ErrUnreachable)
+	require.ErrorIs(t, err, ErrInternal)
+
+	calls = 0
+
+	check, err := client.CheckWithRetry(context.Background(), authUUID, "1234", CheckRetryConfig{
+		Backoff: time.Millisecond,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls)
+
+	// The client can't tell whether the first attempt validated the
+	// authentication, so it is not reported as valid.
+	assert.Equal(t, status.CheckAlreadyValidated, check.Status)
+}
+
+func TestCheckWithRetryDisablesNetworkRetries(t *testing.T) {
+	calls := 0
+
+	hc := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+
+		conn, _, err := w.(http.Hijacker).Hijack()
+		require.NoError(t, err)
+		conn.Close()
+	}))
+	defer hc.Close()
+
+	client, err := NewClient(Config{
+		CustomerUUID:      uuid.New().String(),
+		BaseURL:           hc.URL,
+		MaxNetworkRetries: Int(3),
+		Backoff:           Backoff{Initial: time.Millisecond, MaxInterval: time.Millisecond},
+	})
+	require.NoError(t, err)
+
+	_, err = client.CheckWithRetry(context.Background(), uuid.New().String(), "1234", CheckRetryConfig{
+		MaxAttempts: 2,
+		Backoff:     time.Millisecond,
+	})
+	require.ErrorIs(t, err, ErrUnreachable)
+	assert.Equal(t, 2, calls)
+}
+
+func TestCheckCodeFormat(t *testing.T) {
+	srv := dingtest.NewServer()
+	defer srv.Close()
+
+	client, err := NewClient(Config{
+		CustomerUUID: dingtest.CustomerUUID,
+		APIKey:       dingtest.APIKey,
+		BaseURL:      srv.URL,
+		CodeLength:   len(dingtest.Code),
+	})
+	require.NoError(t, err)
+
+	auth, err := client.Authenticate(NewAuth("+33612345678"))
+	require.NoError(t, err)
+
+	for _, code := range []string{"", "12a4", "１２３４", "123", "12345"} {
+		_, err = client.Check(auth.AuthenticationUUID, code)
+		assert.ErrorIs(t, err, ErrInvalidCodeFormat, code)
+	}
+
+	check, err := client.Check(auth.AuthenticationUUID, dingtest.Code)
+	require.NoError(t, err)
+	assert.Equal(t, status.CheckValid, check.Status)
+}
+
+func TestDo(t *testing.T) {
+	srv := dingtest.NewServer()
+	defer srv.Close()
+
+	client := newTestClient(t, srv)
+
+	err := client.Do(context.Background(), http.MethodPost, "/status", map[string]string{
+		"customer_uuid":       dingtest.CustomerUUID,
+		"authentication_uuid": uuid.New().String(),
+	}, nil)
+	require.ErrorIs(t, err, ErrInvalidAuthUUID)
+
+	err = client.With(WithCustomerUUID("invalid")).Do(context.Background(), http.MethodGet, "status", nil, nil)
+	require.ErrorIs(t, err, ErrInvalidCustomerUUID)
+}
+
+func TestUnexpectedResponse(t *testing.T) {
+	hc := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Such as the login page of a captive portal.
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, "<html><body>Sign in to the network</body></html>")
+	}))
+	defer hc.Close()
+
+	client, err := NewClient(Config{
+		CustomerUUID:      dingtest.CustomerUUID,
+		BaseURL:           hc.URL,
+		MaxNetworkRetries: Int(0),
+	})
+	require.NoError(t, err)
+
+	_, err = client.Check(uuid.New().String(), "1234")
+	assert.ErrorIs(t, err, ErrUnexpectedResponse)
+	assert.ErrorIs(t, err, ErrInternal)
+	assert.Contains(t, err.Error(), `content type "text/html" with HTTP status 200`)
+}
+
+func TestGzipResponse(t *testing.T) {
+	hc := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Encoding", "gzip")
+
+		gz := gzip.NewWriter(w)
+		fmt.Fprintf(gz, `{"authentication_uuid":%q,"status":"valid"}`, dingtest.CustomerUUID)
+		gz.Close()
+	}))
+	defer hc.Close()
+
+	client, err := NewClient(Config{
+		CustomerUUID: dingtest.CustomerUUID,
+		BaseURL:      hc.URL,
+		// Asking for gzip explicitly disables the transparent decompression
+		// of the HTTP transport.
+		CustomHTTPClient: &http.Client{Transport: gzipTransport{http.DefaultTransport}},
+	})
+	require.NoError(t, err)
+
+	check, err := client.Check(uuid.New().String(), "1234")
+	require.NoError(t, err)
+	assert.Equal(t, status.CheckValid, check.Status)
+}
+
+type gzipTransport struct {
+	http.RoundTripper
+}
+
+func (t gzipTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	r = r.Clone(r.Context())
+	r.Header.Set("Accept-Encoding", "gzip")
+
+	return t.RoundTripper.RoundTrip(r)
+}
+
+func TestRequestID(t *testing.T) {
+	srv := dingtest.NewServer()
+	defer srv.Close()
+
+	srv.SetScenario("+33600000001", dingtest.Scenario{Error: string(code.NegativeBalance)})
+
+	client := newTestClient(t, srv)
+
+	auth, err := client.Authenticate(NewAuth("+33612345678"))
+	require.NoError(t, err)
+	assert.NotEmpty(t, auth.RequestID)
+
+	check, err := client.Check(auth.AuthenticationUUID, dingtest.Code)
+	require.NoError(t, err)
+	assert.NotEmpty(t, check.RequestID)
+	assert.NotEqual(t, auth.RequestID, check.RequestID)
+
+	_, err = client.Authenticate(NewAuth("+33600000001"))
+	assert.ErrorIs(t, err, ErrNegativeBalance)
+
+	var dingErr *Error
+	require.ErrorAs(t, err, &dingErr)
+	assert.NotEmpty(t, dingErr.RequestID)
+	assert.Contains(t, err.Error(), dingErr.RequestID)
+	assert.Equal(t, code.NegativeBalance, dingErr.Code)
+
+	// Errors of responses without request ID are returned as is.
+	hc := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer hc.Close()
+
+	client, err = NewClient(Config{CustomerUUID: dingtest.CustomerUUID, BaseURL: hc.URL})
+	require.NoError(t, err)
+
+	_, err = client.Check(uuid.NewString(), "1234")
+	assert.Equal(t, ErrUnauthorized, err)
+}
+
+func TestTransport(t *testing.T) {
+	var got transport.Request
+
+	client, err := NewClient(Config{
+		CustomerUUID: dingtest.CustomerUUID,
+		APIKey:       dingtest.APIKey,
+		BaseURL:      "unix:///var/run/ding.sock",
+		Transport: transport.Func(func(ctx context.Context, req transport.Request) (transport.Response, error) {
+			got = req
+
+			return transport.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(`{"authentication_uuid": "a74ee547-564d-487a-91df-37fb25413a91", "status": "valid"}`)),
+			}, nil
+		}),
+	})
+	require.NoError(t, err)
+
+	check, err := client.Check(uuid.New().String(), "1234")
+	require.NoError(t, err)
+	assert.Equal(t, status.CheckValid, check.Status)
+
+	assert.Equal(t, http.MethodPost, got.Method)
+	assert.Equal(t, "unix:///var/run/ding.sock/check", got.URL)
+	assert.Equal(t, dingtest.APIKey, got.Header.Get("x-api-key"))
+	assert.Contains(t, string(got.Body), `"check_code":"1234"`)
+}
+
+func TestAuthenticateExtra(t *testing.T) {
+	var got transport.Request
+
+	client, err := NewClient(Config{
+		CustomerUUID: dingtest.CustomerUUID,
+		APIKey:       dingtest.APIKey,
+		Transport: transport.Func(func(ctx context.Context, req transport.Request) (transport.Response, error) {
+			got = req
+
+			return transport.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(`{"authentication_uuid": "a74ee547-564d-487a-91df-37fb25413a91", "status": "pending"}`)),
+			}, nil
+		}),
+	})
+	require.NoError(t, err)
+
+	_, err = client.Authenticate(NewAuth("+33612345678").
+		WithExtra("template_id", "login").
+		WithExtra("locale", map[string]string{"language": "fr"}))
+	require.NoError(t, err)
+
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(got.Body, &body))
+	assert.Equal(t, "+33612345678", body["phone_number"])
+	assert.Equal(t, "login", body["template_id"])
+	assert.Equal(t, map[string]interface{}{"language": "fr"}, body["locale"])
+
+	// The fields sent by the SDK can't be overridden, whatever their case.
+	got = transport.Request{}
+	for _, name := range []string{"phone_number", "Customer_UUID", "ip", ""} {
+		_, err = client.Authenticate(NewAuth("+33612345678").WithExtra(name, "value"))
+		assert.ErrorIs(t, err, ErrInvalidExtra, name)
+	}
+	assert.Nil(t, got.Body)
+}
+
+func TestRawFields(t *testing.T) {
+	body := `{"authentication_uuid": "a74ee547-564d-487a-91df-37fb25413a91", "status": "valid"}`
+
+	client, err := NewClient(Config{
+		CustomerUUID: dingtest.CustomerUUID,
+		APIKey:       dingtest.APIKey,
+		Transport: transport.Func(func(ctx context.Context, req transport.Request) (transport.Response, error) {
+			return transport.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(body)),
+			}, nil
+		}),
+	})
+	require.NoError(t, err)
+
+	check, err := client.Check(uuid.New().String(), "1234")
+	require.NoError(t, err)
+	assert.Nil(t, check.Raw)
+
+	body = `{"authentication_uuid": "a74ee547-564d-487a-91df-37fb25413a91", "Status": "valid", "risk_score": 0.2, "carrier": {"name": "Orange"}}`
+
+	check, err = client.Check(uuid.New().String(), "1234")
+	require.NoError(t, err)
+	assert.Equal(t, status.CheckValid, check.Status)
+	assert.Equal(t, map[string]json.RawMessage{
+		"risk_score": json.RawMessage(`0.2`),
+		"carrier":    json.RawMessage(`{"name": "Orange"}`),
+	}, check.Raw)
+}
+
+func TestAPIKeys(t *testing.T) {
+	var keys []string
+
+	client, err := NewClient(Config{
+		CustomerUUID: dingtest.CustomerUUID,
+		APIKeys: []WeightedAPIKey{
+			{Key: "key_a", Weight: 2},
+			{Key: "key_b"},
+		},
+		Transport: transport.Func(func(ctx context.Context, req transport.Request) (transport.Response, error) {
+			keys = append(keys, req.Header.Get("x-api-key"))
+
+			return transport.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(`{"authentication_uuid": "a74ee547-564d-487a-91df-37fb25413a91", "status": "valid"}`)),
+			}, nil
+		}),
+	})
+	require.NoError(t, err)
+
+	for i := 0; i < 6; i++ {
+		_, err = client.Check(uuid.New().String(), "1234")
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, []string{"key_a", "key_b", "key_a", "key_a", "key_b", "key_a"}, keys)
+
+	_, err = NewClient(Config{
+		CustomerUUID: dingtest.CustomerUUID,
+		APIKey:       dingtest.APIKey,
+		APIKeys:      []WeightedAPIKey{{Key: "key_a"}},
+	})
+	assert.Error(t, err)
+
+	_, err = NewClient(Config{
+		CustomerUUID: dingtest.CustomerUUID,
+		APIKeys:      []WeightedAPIKey{{Key: "key_a"}, {}},
+	})
+	assert.Error(t, err)
+}
+
+func TestFallbackAPIKey(t *testing.T) {
+	var (
+		keys      []string
+		failovers int
+	)
+
+	client, err := NewClient(Config{
+		CustomerUUID:   dingtest.CustomerUUID,
+		APIKey:         "revoked_key",
+		FallbackAPIKey: "fallback_key",
+		Hooks: Hooks{
+			OnAPIKeyFailover: func() { failovers++ },
+		},
+		Transport: transport.Func(func(ctx context.Context, req transport.Request) (transport.Response, error) {
+			key := req.Header.Get("x-api-key")
+			keys = append(keys, key)
+
+			if key == "revoked_key" {
+				return transport.Response{
+					StatusCode: http.StatusForbidden,
+					Header:     http.Header{"Content-Type": {"application/json"}},
+					Body:       io.NopCloser(strings.NewReader(`{"code": "invalid_api_key", "message": "invalid API key"}`)),
+				}, nil
+			}
+
+			return transport.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(`{"authentication_uuid": "a74ee547-564d-487a-91df-37fb25413a91", "status": "valid"}`)),
+			}, nil
+		}),
+	})
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		_, err = client.Check(uuid.New().String(), "1234")
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, []string{"revoked_key", "fallback_key", "fallback_key"}, keys)
+	assert.Equal(t, 1, failovers)
+}
+
+func TestDialContext(t *testing.T) {
+	srv := dingtest.NewServer()
+	defer srv.Close()
+
+	var dialed []string
+
+	client, err := NewClient(Config{
+		CustomerUUID: dingtest.CustomerUUID,
+		APIKey:       dingtest.APIKey,
+		BaseURL:      "http://ding.internal:8080",
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			dialed = append(dialed, addr)
+
+			var d net.Dialer
+			return d.DialContext(ctx, network, strings.TrimPrefix(srv.URL, "http://"))
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = client.Authenticate(NewAuth("+33612345678"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ding.internal:8080"}, dialed)
+
+	_, err = NewClient(Config{
+		CustomerUUID:     dingtest.CustomerUUID,
+		CustomHTTPClient: &http.Client{},
+		Resolver:         &net.Resolver{PreferGo: true},
+	})
+	assert.Error(t, err)
+}
+
+func TestWeakSigningKeyFIPS(t *testing.T) {
+	if !fips.Enabled() {
+		t.Skip("FIPS 140 mode is disabled")
+	}
+
+	_, err := NewClient(Config{CustomerUUID: dingtest.CustomerUUID, SigningKey: "short"})
+	assert.Error(t, err)
+
+	_, err = NewClient(Config{CustomerUUID: dingtest.CustomerUUID, SigningKey: "long_enough_signing_key"})
+	assert.NoError(t, err)
+}
+
+func TestShouldRetry(t *testing.T) {
+	calls := map[string]int{}
+
+	hc := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls[r.URL.Path]++
+
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+
+		w.Header().Set("retry-after", "0")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer hc.Close()
+
+	var attempts []int
+
+	client, err := NewClient(Config{
+		CustomerUUID:      dingtest.CustomerUUID,
+		APIKey:            dingtest.APIKey,
+		BaseURL:           hc.URL,
+		MaxNetworkRetries: Int(2),
+		LeveledLogger:     &Logger{Level: LevelNull},
+		ShouldRetry: func(res *http.Response, err error, attempt int) bool {
+			require.NoError(t, err)
+			assert.Equal(t, http.MethodGet, res.Request.Method)
+			attempts = append(attempts, attempt)
+
+			return !strings.HasSuffix(res.Request.URL.Path, "/check") && DefaultShouldRetry(res, err, attempt)
+		},
+	})
+	require.NoError(t, err)
+
+	err = client.Do(context.Background(), http.MethodGet, "check", nil, nil)
+	assert.ErrorIs(t, err, ErrInternal)
+	assert.Equal(t, 1, calls["/check"])
+
+	// Once retries run out, the error of the last response is returned.
+	err = client.Do(context.Background(), http.MethodGet, "retry", nil, nil)
+	assert.ErrorIs(t, err, ErrUnexpectedResponse)
+	assert.Equal(t, 3, calls["/retry"])
+
+	assert.Equal(t, []int{1, 1, 2, 3}, attempts)
+
+	// POST requests that may have been processed by the API, such as after
+	// a gateway error, are neither retried nor given to ShouldRetry.
+	_, err = client.Retry(uuid.NewString())
+	assert.ErrorIs(t, err, ErrInternal)
+	assert.Equal(t, 4, calls["/retry"])
+	assert.Len(t, attempts, 4)
+}
+
+func TestShouldRetryNetworkError(t *testing.T) {
+	var errs []error
+
+	client, err := NewClient(Config{
+		CustomerUUID:  dingtest.CustomerUUID,
+		APIKey:        dingtest.APIKey,
+		LeveledLogger: &Logger{Level: LevelNull},
+		Transport: transport.Func(func(ctx context.Context, req transport.Request) (transport.Response, error) {
+			return transport.Response{}, &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+		}),
+		ShouldRetry: func(res *http.Response, err error, attempt int) bool {
+			assert.Nil(t, res)
+			errs = append(errs, err)
+
+			return false
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = client.Retry(uuid.NewString())
+	assert.ErrorIs(t, err, ErrUnreachable)
+
+	// POST requests are only retried when they failed before being sent.
+	require.Len(t, errs, 1)
+	assert.ErrorIs(t, errs[0], syscall.ECONNREFUSED)
+
+	var urlErr *url.Error
+	require.ErrorAs(t, errs[0], &urlErr)
+	assert.Equal(t, http.MethodPost, urlErr.Op)
+	assert.True(t, strings.HasSuffix(urlErr.URL, "/retry"))
+}
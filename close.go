@@ -0,0 +1,79 @@
+package ding
+
+import (
+	"context"
+	"sync"
+)
+
+// lifecycle tracks the calls in flight for a client and the clients derived
+// from it, so that they can be drained by Close.
+type lifecycle struct {
+	mu       sync.Mutex
+	closed   bool
+	inFlight sync.WaitGroup
+	done     chan struct{}
+}
+
+func newLifecycle() *lifecycle {
+	return &lifecycle{
+		done: make(chan struct{}),
+	}
+}
+
+// enter reports the start of a call, and fails if the client is closed or was
+// derived with invalid options. Every successful call to enter must be
+// followed by a call to leave.
+func (c *Client) enter() error {
+	if c.err != nil {
+		return c.err
+	}
+
+	c.lifecycle.mu.Lock()
+	defer c.lifecycle.mu.Unlock()
+
+	if c.lifecycle.closed {
+		return ErrClosed
+	}
+
+	c.lifecycle.inFlight.Add(1)
+
+	return nil
+}
+
+// leave reports the end of a call started with enter.
+func (c *Client) leave() {
+	c.lifecycle.inFlight.Done()
+}
+
+// Close stops the client and every client derived from it with With. Calls
+// made after Close fail with ErrClosed, long running calls such as
+// AuthenticateAndWait stop waiting, streams of StreamStatus end and queues
+// created with NewQueue stop flushing. Close then waits for the requests in
+// flight to complete and closes the idle connections of the HTTP client.
+//
+// If ctx is done before the requests in flight complete, Close returns the
+// context error without closing the connections.
+func (c *Client) Close(ctx context.Context) error {
+	c.lifecycle.mu.Lock()
+	if !c.lifecycle.closed {
+		c.lifecycle.closed = true
+		close(c.lifecycle.done)
+	}
+	c.lifecycle.mu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		c.lifecycle.inFlight.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	c.api.CloseIdleConnections()
+
+	return nil
+}
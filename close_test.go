@@ -0,0 +1,79 @@
+package ding
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ding-live/ding-go/pkg/dingtest"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClose(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	hc := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprintf(w, `{"authentication_uuid": "%s", "status": "pending"}`, uuid.New().String())
+	}))
+	defer hc.Close()
+
+	client, err := NewClient(Config{
+		CustomerUUID: uuid.New().String(),
+		BaseURL:      hc.URL,
+	})
+	require.NoError(t, err)
+
+	derived := client.With(WithTimeout(time.Minute))
+
+	done := make(chan error)
+	go func() {
+		_, err := derived.Authenticate(NewAuth("+33612345678"))
+		done <- err
+	}()
+
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	// The request in flight is not done yet.
+	require.ErrorIs(t, client.Close(ctx), context.DeadlineExceeded)
+
+	_, err = client.Authenticate(NewAuth("+33612345678"))
+	require.ErrorIs(t, err, ErrClosed)
+
+	close(release)
+	require.NoError(t, <-done)
+	require.NoError(t, client.Close(context.Background()))
+}
+
+func TestCloseStopsPolling(t *testing.T) {
+	srv := dingtest.NewServer()
+	defer srv.Close()
+
+	client := newTestClient(t, srv)
+
+	go func() {
+		for srv.LastAuthenticationUUID() == "" {
+			time.Sleep(time.Millisecond)
+		}
+
+		client.Close(context.Background())
+	}()
+
+	auth, err := client.AuthenticateAndWait(context.Background(), NewAuth("+33612345678"), PollConfig{
+		Interval: time.Hour,
+	})
+	require.ErrorIs(t, err, ErrClosed)
+	assert.NotNil(t, auth)
+}
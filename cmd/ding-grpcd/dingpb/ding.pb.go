@@ -0,0 +1,622 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: ding.proto
+
+package dingpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type AuthenticateRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Phone number, in international format unless phone_region is set.
+	PhoneNumber string `protobuf:"bytes,1,opt,name=phone_number,json=phoneNumber,proto3" json:"phone_number,omitempty"`
+	// Two-letter ISO country code used to read national phone numbers.
+	PhoneRegion string `protobuf:"bytes,2,opt,name=phone_region,json=phoneRegion,proto3" json:"phone_region,omitempty"`
+	// Device of the user, used by the Ding antispam system.
+	Device *Device `protobuf:"bytes,3,opt,name=device,proto3" json:"device,omitempty"`
+	// URL to which the results of the authentication are sent.
+	CallbackUrl     string `protobuf:"bytes,4,opt,name=callback_url,json=callbackUrl,proto3" json:"callback_url,omitempty"`
+	IsReturningUser bool   `protobuf:"varint,5,opt,name=is_returning_user,json=isReturningUser,proto3" json:"is_returning_user,omitempty"`
+	// Channels to try, in order, such as "whatsapp" and "sms".
+	Channels      []string `protobuf:"bytes,6,rep,name=channels,proto3" json:"channels,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AuthenticateRequest) Reset() {
+	*x = AuthenticateRequest{}
+	mi := &file_ding_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AuthenticateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AuthenticateRequest) ProtoMessage() {}
+
+func (x *AuthenticateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ding_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AuthenticateRequest.ProtoReflect.Descriptor instead.
+func (*AuthenticateRequest) Descriptor() ([]byte, []int) {
+	return file_ding_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *AuthenticateRequest) GetPhoneNumber() string {
+	if x != nil {
+		return x.PhoneNumber
+	}
+	return ""
+}
+
+func (x *AuthenticateRequest) GetPhoneRegion() string {
+	if x != nil {
+		return x.PhoneRegion
+	}
+	return ""
+}
+
+func (x *AuthenticateRequest) GetDevice() *Device {
+	if x != nil {
+		return x.Device
+	}
+	return nil
+}
+
+func (x *AuthenticateRequest) GetCallbackUrl() string {
+	if x != nil {
+		return x.CallbackUrl
+	}
+	return ""
+}
+
+func (x *AuthenticateRequest) GetIsReturningUser() bool {
+	if x != nil {
+		return x.IsReturningUser
+	}
+	return false
+}
+
+func (x *AuthenticateRequest) GetChannels() []string {
+	if x != nil {
+		return x.Channels
+	}
+	return nil
+}
+
+type Device struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Ip    string                 `protobuf:"bytes,1,opt,name=ip,proto3" json:"ip,omitempty"`
+	Id    string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	// One of IOS, ANDROID or WEB.
+	Type          string `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`
+	AppVersion    string `protobuf:"bytes,4,opt,name=app_version,json=appVersion,proto3" json:"app_version,omitempty"`
+	OsVersion     string `protobuf:"bytes,5,opt,name=os_version,json=osVersion,proto3" json:"os_version,omitempty"`
+	Model         string `protobuf:"bytes,6,opt,name=model,proto3" json:"model,omitempty"`
+	UserAgent     string `protobuf:"bytes,7,opt,name=user_agent,json=userAgent,proto3" json:"user_agent,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Device) Reset() {
+	*x = Device{}
+	mi := &file_ding_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Device) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Device) ProtoMessage() {}
+
+func (x *Device) ProtoReflect() protoreflect.Message {
+	mi := &file_ding_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Device.ProtoReflect.Descriptor instead.
+func (*Device) Descriptor() ([]byte, []int) {
+	return file_ding_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Device) GetIp() string {
+	if x != nil {
+		return x.Ip
+	}
+	return ""
+}
+
+func (x *Device) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Device) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Device) GetAppVersion() string {
+	if x != nil {
+		return x.AppVersion
+	}
+	return ""
+}
+
+func (x *Device) GetOsVersion() string {
+	if x != nil {
+		return x.OsVersion
+	}
+	return ""
+}
+
+func (x *Device) GetModel() string {
+	if x != nil {
+		return x.Model
+	}
+	return ""
+}
+
+func (x *Device) GetUserAgent() string {
+	if x != nil {
+		return x.UserAgent
+	}
+	return ""
+}
+
+type AuthenticateResponse struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	AuthenticationUuid string                 `protobuf:"bytes,1,opt,name=authentication_uuid,json=authenticationUuid,proto3" json:"authentication_uuid,omitempty"`
+	Status             string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	// Phone number in E.164 format.
+	PhoneNumber   string                 `protobuf:"bytes,3,opt,name=phone_number,json=phoneNumber,proto3" json:"phone_number,omitempty"`
+	Channel       string                 `protobuf:"bytes,4,opt,name=channel,proto3" json:"channel,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	ExpiresAt     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AuthenticateResponse) Reset() {
+	*x = AuthenticateResponse{}
+	mi := &file_ding_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AuthenticateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AuthenticateResponse) ProtoMessage() {}
+
+func (x *AuthenticateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ding_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AuthenticateResponse.ProtoReflect.Descriptor instead.
+func (*AuthenticateResponse) Descriptor() ([]byte, []int) {
+	return file_ding_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *AuthenticateResponse) GetAuthenticationUuid() string {
+	if x != nil {
+		return x.AuthenticationUuid
+	}
+	return ""
+}
+
+func (x *AuthenticateResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *AuthenticateResponse) GetPhoneNumber() string {
+	if x != nil {
+		return x.PhoneNumber
+	}
+	return ""
+}
+
+func (x *AuthenticateResponse) GetChannel() string {
+	if x != nil {
+		return x.Channel
+	}
+	return ""
+}
+
+func (x *AuthenticateResponse) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *AuthenticateResponse) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+type CheckRequest struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	AuthenticationUuid string                 `protobuf:"bytes,1,opt,name=authentication_uuid,json=authenticationUuid,proto3" json:"authentication_uuid,omitempty"`
+	Code               string                 `protobuf:"bytes,2,opt,name=code,proto3" json:"code,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *CheckRequest) Reset() {
+	*x = CheckRequest{}
+	mi := &file_ding_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckRequest) ProtoMessage() {}
+
+func (x *CheckRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ding_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckRequest.ProtoReflect.Descriptor instead.
+func (*CheckRequest) Descriptor() ([]byte, []int) {
+	return file_ding_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *CheckRequest) GetAuthenticationUuid() string {
+	if x != nil {
+		return x.AuthenticationUuid
+	}
+	return ""
+}
+
+func (x *CheckRequest) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+type CheckResponse struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	AuthenticationUuid string                 `protobuf:"bytes,1,opt,name=authentication_uuid,json=authenticationUuid,proto3" json:"authentication_uuid,omitempty"`
+	Status             string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *CheckResponse) Reset() {
+	*x = CheckResponse{}
+	mi := &file_ding_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckResponse) ProtoMessage() {}
+
+func (x *CheckResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ding_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckResponse.ProtoReflect.Descriptor instead.
+func (*CheckResponse) Descriptor() ([]byte, []int) {
+	return file_ding_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CheckResponse) GetAuthenticationUuid() string {
+	if x != nil {
+		return x.AuthenticationUuid
+	}
+	return ""
+}
+
+func (x *CheckResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+type RetryRequest struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	AuthenticationUuid string                 `protobuf:"bytes,1,opt,name=authentication_uuid,json=authenticationUuid,proto3" json:"authentication_uuid,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *RetryRequest) Reset() {
+	*x = RetryRequest{}
+	mi := &file_ding_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RetryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RetryRequest) ProtoMessage() {}
+
+func (x *RetryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ding_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RetryRequest.ProtoReflect.Descriptor instead.
+func (*RetryRequest) Descriptor() ([]byte, []int) {
+	return file_ding_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *RetryRequest) GetAuthenticationUuid() string {
+	if x != nil {
+		return x.AuthenticationUuid
+	}
+	return ""
+}
+
+type RetryResponse struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	AuthenticationUuid string                 `protobuf:"bytes,1,opt,name=authentication_uuid,json=authenticationUuid,proto3" json:"authentication_uuid,omitempty"`
+	Status             string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	NextRetryAt        *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=next_retry_at,json=nextRetryAt,proto3" json:"next_retry_at,omitempty"`
+	RemainingRetry     int32                  `protobuf:"varint,4,opt,name=remaining_retry,json=remainingRetry,proto3" json:"remaining_retry,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *RetryResponse) Reset() {
+	*x = RetryResponse{}
+	mi := &file_ding_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RetryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RetryResponse) ProtoMessage() {}
+
+func (x *RetryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ding_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RetryResponse.ProtoReflect.Descriptor instead.
+func (*RetryResponse) Descriptor() ([]byte, []int) {
+	return file_ding_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *RetryResponse) GetAuthenticationUuid() string {
+	if x != nil {
+		return x.AuthenticationUuid
+	}
+	return ""
+}
+
+func (x *RetryResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *RetryResponse) GetNextRetryAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.NextRetryAt
+	}
+	return nil
+}
+
+func (x *RetryResponse) GetRemainingRetry() int32 {
+	if x != nil {
+		return x.RemainingRetry
+	}
+	return 0
+}
+
+var File_ding_proto protoreflect.FileDescriptor
+
+const file_ding_proto_rawDesc = "" +
+	"\n" +
+	"\n" +
+	"ding.proto\x12\ading.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xef\x01\n" +
+	"\x13AuthenticateRequest\x12!\n" +
+	"\fphone_number\x18\x01 \x01(\tR\vphoneNumber\x12!\n" +
+	"\fphone_region\x18\x02 \x01(\tR\vphoneRegion\x12'\n" +
+	"\x06device\x18\x03 \x01(\v2\x0f.ding.v1.DeviceR\x06device\x12!\n" +
+	"\fcallback_url\x18\x04 \x01(\tR\vcallbackUrl\x12*\n" +
+	"\x11is_returning_user\x18\x05 \x01(\bR\x0fisReturningUser\x12\x1a\n" +
+	"\bchannels\x18\x06 \x03(\tR\bchannels\"\xb1\x01\n" +
+	"\x06Device\x12\x0e\n" +
+	"\x02ip\x18\x01 \x01(\tR\x02ip\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\x12\x12\n" +
+	"\x04type\x18\x03 \x01(\tR\x04type\x12\x1f\n" +
+	"\vapp_version\x18\x04 \x01(\tR\n" +
+	"appVersion\x12\x1d\n" +
+	"\n" +
+	"os_version\x18\x05 \x01(\tR\tosVersion\x12\x14\n" +
+	"\x05model\x18\x06 \x01(\tR\x05model\x12\x1d\n" +
+	"\n" +
+	"user_agent\x18\a \x01(\tR\tuserAgent\"\x92\x02\n" +
+	"\x14AuthenticateResponse\x12/\n" +
+	"\x13authentication_uuid\x18\x01 \x01(\tR\x12authenticationUuid\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x12!\n" +
+	"\fphone_number\x18\x03 \x01(\tR\vphoneNumber\x12\x18\n" +
+	"\achannel\x18\x04 \x01(\tR\achannel\x129\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"expires_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\"S\n" +
+	"\fCheckRequest\x12/\n" +
+	"\x13authentication_uuid\x18\x01 \x01(\tR\x12authenticationUuid\x12\x12\n" +
+	"\x04code\x18\x02 \x01(\tR\x04code\"X\n" +
+	"\rCheckResponse\x12/\n" +
+	"\x13authentication_uuid\x18\x01 \x01(\tR\x12authenticationUuid\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\"?\n" +
+	"\fRetryRequest\x12/\n" +
+	"\x13authentication_uuid\x18\x01 \x01(\tR\x12authenticationUuid\"\xc1\x01\n" +
+	"\rRetryResponse\x12/\n" +
+	"\x13authentication_uuid\x18\x01 \x01(\tR\x12authenticationUuid\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x12>\n" +
+	"\rnext_retry_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\vnextRetryAt\x12'\n" +
+	"\x0fremaining_retry\x18\x04 \x01(\x05R\x0eremainingRetry2\xc3\x01\n" +
+	"\x04Ding\x12K\n" +
+	"\fAuthenticate\x12\x1c.ding.v1.AuthenticateRequest\x1a\x1d.ding.v1.AuthenticateResponse\x126\n" +
+	"\x05Check\x12\x15.ding.v1.CheckRequest\x1a\x16.ding.v1.CheckResponse\x126\n" +
+	"\x05Retry\x12\x15.ding.v1.RetryRequest\x1a\x16.ding.v1.RetryResponseB4Z2github.com/ding-live/ding-go/cmd/ding-grpcd/dingpbb\x06proto3"
+
+var (
+	file_ding_proto_rawDescOnce sync.Once
+	file_ding_proto_rawDescData []byte
+)
+
+func file_ding_proto_rawDescGZIP() []byte {
+	file_ding_proto_rawDescOnce.Do(func() {
+		file_ding_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_ding_proto_rawDesc), len(file_ding_proto_rawDesc)))
+	})
+	return file_ding_proto_rawDescData
+}
+
+var file_ding_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_ding_proto_goTypes = []any{
+	(*AuthenticateRequest)(nil),   // 0: ding.v1.AuthenticateRequest
+	(*Device)(nil),                // 1: ding.v1.Device
+	(*AuthenticateResponse)(nil),  // 2: ding.v1.AuthenticateResponse
+	(*CheckRequest)(nil),          // 3: ding.v1.CheckRequest
+	(*CheckResponse)(nil),         // 4: ding.v1.CheckResponse
+	(*RetryRequest)(nil),          // 5: ding.v1.RetryRequest
+	(*RetryResponse)(nil),         // 6: ding.v1.RetryResponse
+	(*timestamppb.Timestamp)(nil), // 7: google.protobuf.Timestamp
+}
+var file_ding_proto_depIdxs = []int32{
+	1, // 0: ding.v1.AuthenticateRequest.device:type_name -> ding.v1.Device
+	7, // 1: ding.v1.AuthenticateResponse.created_at:type_name -> google.protobuf.Timestamp
+	7, // 2: ding.v1.AuthenticateResponse.expires_at:type_name -> google.protobuf.Timestamp
+	7, // 3: ding.v1.RetryResponse.next_retry_at:type_name -> google.protobuf.Timestamp
+	0, // 4: ding.v1.Ding.Authenticate:input_type -> ding.v1.AuthenticateRequest
+	3, // 5: ding.v1.Ding.Check:input_type -> ding.v1.CheckRequest
+	5, // 6: ding.v1.Ding.Retry:input_type -> ding.v1.RetryRequest
+	2, // 7: ding.v1.Ding.Authenticate:output_type -> ding.v1.AuthenticateResponse
+	4, // 8: ding.v1.Ding.Check:output_type -> ding.v1.CheckResponse
+	6, // 9: ding.v1.Ding.Retry:output_type -> ding.v1.RetryResponse
+	7, // [7:10] is the sub-list for method output_type
+	4, // [4:7] is the sub-list for method input_type
+	4, // [4:4] is the sub-list for extension type_name
+	4, // [4:4] is the sub-list for extension extendee
+	0, // [0:4] is the sub-list for field type_name
+}
+
+func init() { file_ding_proto_init() }
+func file_ding_proto_init() {
+	if File_ding_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_ding_proto_rawDesc), len(file_ding_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   7,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_ding_proto_goTypes,
+		DependencyIndexes: file_ding_proto_depIdxs,
+		MessageInfos:      file_ding_proto_msgTypes,
+	}.Build()
+	File_ding_proto = out.File
+	file_ding_proto_goTypes = nil
+	file_ding_proto_depIdxs = nil
+}
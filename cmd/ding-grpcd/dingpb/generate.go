@@ -0,0 +1,4 @@
+// Package dingpb contains the protocol buffers and gRPC service of ding-grpcd.
+package dingpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative ding.proto
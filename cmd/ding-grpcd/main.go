@@ -0,0 +1,257 @@
+// Command ding-grpcd exposes the Ding client over gRPC, so that the services
+// of your infrastructure, whatever their language, can send and check codes
+// through a single service that owns the Ding credentials.
+//
+// Usage:
+//
+//	ding-grpcd [-addr :50051] [-config ding.yaml]
+//
+// The service is described by dingpb/ding.proto. The client is configured with
+// the file given by -config, see ding.LoadConfigFile for its format, or with
+// the DING_CUSTOMER_UUID, DING_API_KEY and DING_BASE_URL environment
+// variables.
+//
+// When the DING_GRPCD_TOKEN environment variable is set, calls must carry it
+// in an "authorization: Bearer <token>" metadata entry. The standard gRPC
+// health service is registered as well.
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"flag"
+	"log"
+	"net"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	ding "github.com/ding-live/ding-go"
+	"github.com/ding-live/ding-go/cmd/ding-grpcd/dingpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func main() {
+	addr := flag.String("addr", ":50051", "address to listen on")
+	config := flag.String("config", "", "path of the client configuration file")
+	flag.Parse()
+
+	client, err := newClient(*config)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	lis, err := net.Listen("tcp", *addr)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	srv := newServer(client, os.Getenv("DING_GRPCD_TOKEN"))
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		<-ctx.Done()
+
+		// Let the calls in progress complete before closing the client.
+		srv.GracefulStop()
+
+		closeCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		_ = client.Close(closeCtx)
+	}()
+
+	log.Printf("listening on %s", lis.Addr())
+
+	if err := srv.Serve(lis); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func newClient(config string) (*ding.Client, error) {
+	if config != "" {
+		return ding.NewClientFromFile(config)
+	}
+
+	return ding.NewClient(ding.Config{
+		CustomerUUID: os.Getenv("DING_CUSTOMER_UUID"),
+		APIKey:       os.Getenv("DING_API_KEY"),
+		BaseURL:      os.Getenv("DING_BASE_URL"),
+	})
+}
+
+// newServer returns a gRPC server exposing client. Calls must carry token as
+// a bearer token unless it is empty.
+func newServer(client *ding.Client, token string) *grpc.Server {
+	var opts []grpc.ServerOption
+	if token != "" {
+		opts = append(opts, grpc.UnaryInterceptor(authorize(token)))
+	}
+
+	srv := grpc.NewServer(opts...)
+	dingpb.RegisterDingServer(srv, &service{client: client})
+	grpc_health_v1.RegisterHealthServer(srv, health.NewServer())
+
+	return srv
+}
+
+// authorize rejects the calls that don't carry token as a bearer token. The
+// health service is left open for probes.
+func authorize(token string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if strings.HasPrefix(info.FullMethod, "/grpc.health.v1.Health/") {
+			return handler(ctx, req)
+		}
+
+		md, _ := metadata.FromIncomingContext(ctx)
+
+		var got string
+		if v := md.Get("authorization"); len(v) > 0 {
+			got = strings.TrimPrefix(v[0], "Bearer ")
+		}
+
+		if subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			return nil, status.Error(codes.Unauthenticated, "invalid token")
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// ----------------------------------------------------------------------------
+
+type service struct {
+	dingpb.UnimplementedDingServer
+
+	client *ding.Client
+}
+
+func (s *service) Authenticate(ctx context.Context, req *dingpb.AuthenticateRequest) (*dingpb.AuthenticateResponse, error) {
+	opt := ding.NewAuth(req.GetPhoneNumber()).WithPhoneRegion(req.GetPhoneRegion())
+
+	if d := req.GetDevice(); d != nil {
+		opt = opt.WithDeviceSignals(ding.DeviceSignals{
+			IP:         d.GetIp(),
+			ID:         d.GetId(),
+			Type:       ding.DeviceType(strings.ToUpper(d.GetType())),
+			AppVersion: d.GetAppVersion(),
+			OSVersion:  d.GetOsVersion(),
+			Model:      d.GetModel(),
+			UserAgent:  d.GetUserAgent(),
+		})
+	}
+
+	if req.GetCallbackUrl() != "" {
+		opt = opt.WithCallback(req.GetCallbackUrl())
+	}
+
+	if req.GetIsReturningUser() {
+		opt = opt.WithReturningUser()
+	}
+
+	if len(req.GetChannels()) > 0 {
+		chs := make([]ding.Channel, len(req.GetChannels()))
+		for i, ch := range req.GetChannels() {
+			chs[i] = ding.Channel(ch)
+		}
+
+		opt = opt.WithChannels(chs...)
+	}
+
+	auth, err := s.client.AuthenticateWithContext(ctx, opt)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &dingpb.AuthenticateResponse{
+		AuthenticationUuid: auth.AuthenticationUUID,
+		Status:             auth.Status.String(),
+		PhoneNumber:        auth.PhoneNumber.E164,
+		Channel:            auth.Channel.String(),
+		CreatedAt:          timestamp(auth.CreatedAt),
+		ExpiresAt:          timestamp(auth.ExpiresAt),
+	}, nil
+}
+
+func (s *service) Check(ctx context.Context, req *dingpb.CheckRequest) (*dingpb.CheckResponse, error) {
+	check, err := s.client.CheckWithContext(ctx, req.GetAuthenticationUuid(), req.GetCode())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &dingpb.CheckResponse{
+		AuthenticationUuid: check.AuthenticationUUID,
+		Status:             check.Status.String(),
+	}, nil
+}
+
+func (s *service) Retry(ctx context.Context, req *dingpb.RetryRequest) (*dingpb.RetryResponse, error) {
+	retry, err := s.client.RetryWithContext(ctx, req.GetAuthenticationUuid())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &dingpb.RetryResponse{
+		AuthenticationUuid: retry.AuthenticationUUID,
+		Status:             retry.Status.String(),
+		NextRetryAt:        timestamp(retry.NextRetryAt),
+		RemainingRetry:     int32(retry.RemainingRetry),
+	}, nil
+}
+
+// toStatus converts the errors of the Ding client to gRPC statuses. Errors
+// caused by the configuration of ding-grpcd, such as a wrong API key, are
+// reported as FailedPrecondition since callers can't fix them.
+func toStatus(err error) error {
+	var code codes.Code
+
+	switch {
+	case errors.Is(err, ding.ErrInvalidPhoneNumber),
+		errors.Is(err, ding.ErrUnsupportedRegion),
+		errors.Is(err, ding.ErrInvalidAuthUUID),
+		errors.Is(err, ding.ErrInvalidCallbackURL),
+		errors.Is(err, ding.ErrInvalidChannels),
+		errors.Is(err, ding.ErrInvalidCodeFormat),
+		errors.Is(err, ding.ErrInvalidDeviceSignals),
+		errors.Is(err, ding.ErrInvalidIP),
+		errors.Is(err, ding.ErrInvalidTTL):
+		code = codes.InvalidArgument
+	case errors.Is(err, ding.ErrUnauthorized),
+		errors.Is(err, ding.ErrInvalidCustomerUUID),
+		errors.Is(err, ding.ErrNegativeBalance):
+		code = codes.FailedPrecondition
+	case errors.Is(err, ding.ErrOptedOut):
+		code = codes.PermissionDenied
+	case errors.Is(err, ding.ErrDuplicateRequest):
+		code = codes.AlreadyExists
+	case errors.Is(err, ding.ErrUnreachable), errors.Is(err, ding.ErrClosed):
+		code = codes.Unavailable
+	case errors.Is(err, context.DeadlineExceeded):
+		code = codes.DeadlineExceeded
+	case errors.Is(err, context.Canceled):
+		code = codes.Canceled
+	default:
+		code = codes.Internal
+	}
+
+	return status.Error(code, err.Error())
+}
+
+func timestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+
+	return timestamppb.New(t)
+}
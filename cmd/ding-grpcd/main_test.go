@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	ding "github.com/ding-live/ding-go"
+	"github.com/ding-live/ding-go/cmd/ding-grpcd/dingpb"
+	"github.com/ding-live/ding-go/pkg/dingtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func newTestConn(t *testing.T, token string) *grpc.ClientConn {
+	api := dingtest.NewServer()
+	t.Cleanup(api.Close)
+
+	client, err := ding.NewClient(ding.Config{
+		CustomerUUID: dingtest.CustomerUUID,
+		APIKey:       dingtest.APIKey,
+		BaseURL:      api.URL,
+	})
+	require.NoError(t, err)
+
+	lis := bufconn.Listen(1 << 20)
+	srv := newServer(client, token)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return conn
+}
+
+func TestService(t *testing.T) {
+	ctx := context.Background()
+	c := dingpb.NewDingClient(newTestConn(t, ""))
+
+	auth, err := c.Authenticate(ctx, &dingpb.AuthenticateRequest{
+		PhoneNumber: "06 12 34 56 78",
+		PhoneRegion: "FR",
+		Device:      &dingpb.Device{Type: "ios", Ip: "192.168.0.1"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "pending", auth.GetStatus())
+	assert.Equal(t, "+33612345678", auth.GetPhoneNumber())
+	assert.NotNil(t, auth.GetExpiresAt())
+
+	retry, err := c.Retry(ctx, &dingpb.RetryRequest{AuthenticationUuid: auth.GetAuthenticationUuid()})
+	require.NoError(t, err)
+	assert.Equal(t, "approved", retry.GetStatus())
+
+	check, err := c.Check(ctx, &dingpb.CheckRequest{AuthenticationUuid: auth.GetAuthenticationUuid(), Code: dingtest.Code})
+	require.NoError(t, err)
+	assert.Equal(t, "valid", check.GetStatus())
+
+	_, err = c.Authenticate(ctx, &dingpb.AuthenticateRequest{PhoneNumber: "invalid"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = c.Check(ctx, &dingpb.CheckRequest{AuthenticationUuid: "invalid", Code: "1234"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestToken(t *testing.T) {
+	ctx := context.Background()
+	conn := newTestConn(t, "secret")
+	c := dingpb.NewDingClient(conn)
+
+	_, err := c.Authenticate(ctx, &dingpb.AuthenticateRequest{PhoneNumber: "+33612345678"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = c.Authenticate(metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer wrong"),
+		&dingpb.AuthenticateRequest{PhoneNumber: "+33612345678"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = c.Authenticate(metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer secret"),
+		&dingpb.AuthenticateRequest{PhoneNumber: "+33612345678"})
+	assert.NoError(t, err)
+
+	// Health checks don't need the token.
+	res, err := grpc_health_v1.NewHealthClient(conn).Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, res.GetStatus())
+}
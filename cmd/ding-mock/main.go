@@ -0,0 +1,113 @@
+// Command ding-mock runs the fake Ding API of the dingtest package as a
+// standalone server, so that services written in any language and end-to-end
+// test environments can run against a local Ding emulator.
+//
+// Usage:
+//
+//	ding-mock [-addr :8080] [-scenarios scenarios.json]
+//
+// The server only accepts the customer UUID dingtest.CustomerUUID,
+// a74ee547-564d-487a-91df-37fb25413a91, and the API key dingtest.APIKey,
+// dingtest_api_key. Every code it sends is dingtest.Code, 1234.
+//
+// Scenarios script its behavior for given phone numbers. They are read from a
+// JSON file mapping phone numbers in E.164 format to dingtest.Scenario values:
+//
+//	{
+//	  "+33600000001": {"error": "negative_balance"},
+//	  "+33600000002": {"status": "spam_detected"},
+//	  "+33600000003": {"code": "000000", "channel": "whatsapp"}
+//	}
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/ding-live/ding-go/pkg/dingtest"
+)
+
+func main() {
+	addr := flag.String("addr", ":8080", "address to listen on")
+	scenarios := flag.String("scenarios", "", "path of a JSON file of scenarios")
+	flag.Parse()
+
+	srv := dingtest.NewHandler()
+
+	if *scenarios != "" {
+		if err := loadScenarios(srv, *scenarios); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	hs := &http.Server{
+		Addr:              *addr,
+		Handler:           logRequests(srv),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		_ = hs.Shutdown(shutdownCtx)
+	}()
+
+	log.Printf("fake Ding API listening on %s", *addr)
+
+	if err := hs.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		log.Fatal(err)
+	}
+}
+
+// loadScenarios sets the scenarios of the JSON file at path on srv.
+func loadScenarios(srv *dingtest.Server, path string) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read scenarios: %w", err)
+	}
+
+	var scenarios map[string]dingtest.Scenario
+	if err := json.Unmarshal(b, &scenarios); err != nil {
+		return fmt.Errorf("parse scenarios %s: %w", path, err)
+	}
+
+	for phoneNumber, sc := range scenarios {
+		srv.SetScenario(phoneNumber, sc)
+	}
+
+	return nil
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		log.Printf("%s %s %d", r.Method, r.URL.Path, rec.status)
+	})
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	ding "github.com/ding-live/ding-go"
+	"github.com/ding-live/ding-go/pkg/dingtest"
+	"github.com/ding-live/ding-go/pkg/status"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScenarios(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scenarios.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{
+		"+33600000001": {"error": "negative_balance"},
+		"+33600000002": {"status": "spam_detected"},
+		"+33600000003": {"code": "000000", "channel": "whatsapp"}
+	}`), 0o600))
+
+	srv := dingtest.NewHandler()
+	require.NoError(t, loadScenarios(srv, path))
+
+	hs := httptest.NewServer(logRequests(srv))
+	defer hs.Close()
+
+	client, err := ding.NewClient(ding.Config{
+		CustomerUUID: dingtest.CustomerUUID,
+		APIKey:       dingtest.APIKey,
+		BaseURL:      hs.URL,
+	})
+	require.NoError(t, err)
+
+	_, err = client.Authenticate(ding.NewAuth("+33600000001"))
+	assert.ErrorIs(t, err, ding.ErrNegativeBalance)
+
+	auth, err := client.Authenticate(ding.NewAuth("+33600000002"))
+	require.NoError(t, err)
+	assert.Equal(t, status.AuthSpamDetected, auth.Status)
+
+	auth, err = client.Authenticate(ding.NewAuth("+33600000003"))
+	require.NoError(t, err)
+	assert.Equal(t, ding.ChannelWhatsApp, auth.Channel)
+
+	check, err := client.Check(auth.AuthenticationUUID, dingtest.Code)
+	require.NoError(t, err)
+	assert.Equal(t, status.CheckInvalid, check.Status)
+
+	check, err = client.Check(auth.AuthenticationUUID, "000000")
+	require.NoError(t, err)
+	assert.Equal(t, status.CheckValid, check.Status)
+
+	// Other numbers keep the default behavior.
+	auth, err = client.Authenticate(ding.NewAuth("+33612345678"))
+	require.NoError(t, err)
+	assert.Equal(t, status.AuthPending, auth.Status)
+}
+
+func TestLoadScenariosErrors(t *testing.T) {
+	srv := dingtest.NewHandler()
+
+	assert.Error(t, loadScenarios(srv, filepath.Join(t.TempDir(), "missing.json")))
+
+	path := filepath.Join(t.TempDir(), "scenarios.json")
+	require.NoError(t, os.WriteFile(path, []byte(`[]`), 0o600))
+	assert.Error(t, loadScenarios(srv, path))
+}
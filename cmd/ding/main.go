@@ -0,0 +1,379 @@
+// Command ding sends requests to the Ding API from the command line, which is
+// handy to reproduce an issue without writing a Go program.
+//
+// Usage:
+//
+//	ding [flags] <command> [command flags]
+//
+// The commands are:
+//
+//	authenticate  send a code to a phone number
+//	check         check a code entered by a user
+//	retry         send a new code for an authentication
+//
+// Credentials are read from the -customer-uuid and -api-key flags, or from the
+// DING_CUSTOMER_UUID and DING_API_KEY environment variables. Results are
+// printed to stdout, as JSON with the -json flag, in which case errors are
+// printed to stdout as JSON objects with an "error" key too.
+//
+// The exit status is:
+//
+//	0  the command succeeded
+//	1  the command failed for any other reason
+//	2  the command line is invalid
+//	3  the API rejected the input, such as an invalid phone number
+//	4  the credentials were rejected
+//	5  the request was rate limited
+//
+// There are no balance or usage commands because the Ding API doesn't expose
+// them yet: they are only available in the dashboard.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	ding "github.com/ding-live/ding-go"
+	"github.com/ding-live/ding-go/pkg/status"
+)
+
+type command struct {
+	usage string
+	run   func(ctx context.Context, client *ding.Client, fs *flag.FlagSet, args []string) (interface{}, error)
+}
+
+var commands = map[string]command{
+	"authenticate": {"send a code to a phone number", runAuthenticate},
+	"check":        {"check a code entered by a user", runCheck},
+	"retry":        {"send a new code for an authentication", runRetry},
+}
+
+var errUsage = errors.New("invalid usage")
+
+const (
+	exitOK = iota
+	exitError
+	exitUsage
+	exitInvalidInput
+	exitUnauthorized
+	exitRateLimited
+)
+
+// rateLimiter is implemented by the outputs of commands that can be rate
+// limited.
+type rateLimiter interface {
+	rateLimited() bool
+}
+
+func main() {
+	os.Exit(run(context.Background(), os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("ding", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() { usage(fs) }
+
+	customerUUID := fs.String("customer-uuid", os.Getenv("DING_CUSTOMER_UUID"), "customer UUID of your Ding account (DING_CUSTOMER_UUID)")
+	apiKey := fs.String("api-key", os.Getenv("DING_API_KEY"), "API key of your Ding account (DING_API_KEY)")
+	baseURL := fs.String("base-url", os.Getenv("DING_BASE_URL"), "base URL of the Ding API (DING_BASE_URL)")
+	jsonOutput := fs.Bool("json", false, "print results and errors as JSON")
+
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return exitUsage
+	}
+
+	cmd, ok := commands[fs.Arg(0)]
+	if !ok {
+		fmt.Fprintf(stderr, "ding: unknown command %q\n", fs.Arg(0))
+		fs.Usage()
+		return exitUsage
+	}
+
+	fail := func(err error) int {
+		code := exitCode(err)
+
+		if *jsonOutput {
+			writeJSON(stdout, errorOutput{Error: err.Error(), ExitCode: code})
+		} else {
+			fmt.Fprintf(stderr, "ding: %v\n", err)
+		}
+
+		return code
+	}
+
+	client, err := ding.NewClient(ding.Config{
+		CustomerUUID: *customerUUID,
+		APIKey:       *apiKey,
+		BaseURL:      *baseURL,
+	})
+	if err != nil {
+		return fail(err)
+	}
+	defer client.Close(ctx)
+
+	cmdFlags := flag.NewFlagSet(fs.Arg(0), flag.ContinueOnError)
+	cmdFlags.SetOutput(stderr)
+
+	res, err := cmd.run(ctx, client, cmdFlags, fs.Args()[1:])
+	if errors.Is(err, errUsage) {
+		return exitUsage
+	}
+
+	if err != nil {
+		return fail(err)
+	}
+
+	if *jsonOutput {
+		err = writeJSON(stdout, res)
+	} else {
+		err = writeText(stdout, res)
+	}
+
+	if err != nil {
+		fmt.Fprintf(stderr, "ding: %v\n", err)
+		return exitError
+	}
+
+	if r, ok := res.(rateLimiter); ok && r.rateLimited() {
+		return exitRateLimited
+	}
+
+	return exitOK
+}
+
+// exitCode returns the exit status of the command for err.
+func exitCode(err error) int {
+	switch {
+	case errors.Is(err, ding.ErrUnauthorized), errors.Is(err, ding.ErrInvalidCustomerUUID):
+		return exitUnauthorized
+	case errors.Is(err, ding.ErrInvalidPhoneNumber),
+		errors.Is(err, ding.ErrUnsupportedRegion),
+		errors.Is(err, ding.ErrInvalidAuthUUID),
+		errors.Is(err, ding.ErrInvalidCallbackURL),
+		errors.Is(err, ding.ErrInvalidChannels),
+		errors.Is(err, ding.ErrInvalidCodeFormat),
+		errors.Is(err, ding.ErrInvalidDeviceSignals),
+		errors.Is(err, ding.ErrInvalidIP),
+		errors.Is(err, ding.ErrInvalidTTL):
+		return exitInvalidInput
+	default:
+		return exitError
+	}
+}
+
+type errorOutput struct {
+	Error    string `json:"error"`
+	ExitCode int    `json:"exit_code"`
+}
+
+func writeJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(v)
+}
+
+// writeText writes the fields of the struct v as aligned "name: value" lines,
+// named after their JSON keys. Empty strings are skipped.
+func writeText(w io.Writer, v interface{}) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 1, ' ', 0)
+
+	rv := reflect.ValueOf(v)
+	for i := 0; i < rv.NumField(); i++ {
+		f := rv.Field(i)
+		if f.Kind() == reflect.String && f.Len() == 0 {
+			continue
+		}
+
+		name := strings.Split(rv.Type().Field(i).Tag.Get("json"), ",")[0]
+		fmt.Fprintf(tw, "%s:\t%v\n", name, f.Interface())
+	}
+
+	return tw.Flush()
+}
+
+func usage(fs *flag.FlagSet) {
+	out := fs.Output()
+
+	fmt.Fprintf(out, "Usage: ding [flags] <command> [command flags]\n\nCommands:\n")
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Fprintf(out, "  %-14s%s\n", name, commands[name].usage)
+	}
+
+	fmt.Fprintf(out, "\nFlags:\n")
+	fs.PrintDefaults()
+}
+
+// parse parses the flags of a command and checks that required ones are set.
+func parse(fs *flag.FlagSet, args []string, required ...string) error {
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+
+	for _, name := range required {
+		if fs.Lookup(name).Value.String() == "" {
+			fmt.Fprintf(fs.Output(), "ding %s: missing -%s\n", fs.Name(), name)
+			fs.Usage()
+			return errUsage
+		}
+	}
+
+	return nil
+}
+
+// ----------------------------------------------------------------------------
+
+type authenticationOutput struct {
+	AuthenticationUUID string `json:"authentication_uuid"`
+	Status             string `json:"status"`
+	PhoneNumber        string `json:"phone_number"`
+	Channel            string `json:"channel,omitempty"`
+	CreatedAt          string `json:"created_at"`
+	ExpiresAt          string `json:"expires_at"`
+}
+
+func (o authenticationOutput) rateLimited() bool {
+	return o.Status == status.AuthRateLimited.String()
+}
+
+func runAuthenticate(ctx context.Context, client *ding.Client, fs *flag.FlagSet, args []string) (interface{}, error) {
+	phone := fs.String("phone", "", "phone number to send the code to")
+	region := fs.String("region", "", "region of the phone number, if in national format")
+	ip := fs.String("ip", "", "IP address of the user")
+	deviceType := fs.String("device-type", "", "type of device: IOS, ANDROID or WEB")
+	deviceID := fs.String("device-id", "", "ID of the device")
+	appVersion := fs.String("app-version", "", "version of your app")
+	callback := fs.String("callback", "", "callback URL")
+	channels := fs.String("channels", "", "comma-separated list of channels to try, such as whatsapp,sms")
+
+	if err := parse(fs, args, "phone"); err != nil {
+		return nil, err
+	}
+
+	opt := ding.NewAuth(*phone).WithPhoneRegion(*region)
+
+	opt = opt.WithDeviceSignals(ding.DeviceSignals{
+		IP:         *ip,
+		ID:         *deviceID,
+		Type:       ding.DeviceType(strings.ToUpper(*deviceType)),
+		AppVersion: *appVersion,
+	})
+
+	if *callback != "" {
+		opt = opt.WithCallback(*callback)
+	}
+
+	if *channels != "" {
+		var chs []ding.Channel
+		for _, ch := range strings.Split(*channels, ",") {
+			chs = append(chs, ding.Channel(strings.TrimSpace(ch)))
+		}
+
+		opt = opt.WithChannels(chs...)
+	}
+
+	auth, err := client.AuthenticateWithContext(ctx, opt)
+	if err != nil {
+		return nil, err
+	}
+
+	return authenticationOutput{
+		AuthenticationUUID: auth.AuthenticationUUID,
+		Status:             auth.Status.String(),
+		PhoneNumber:        auth.PhoneNumber.E164,
+		Channel:            auth.Channel.String(),
+		CreatedAt:          formatTime(auth.CreatedAt),
+		ExpiresAt:          formatTime(auth.ExpiresAt),
+	}, nil
+}
+
+type checkOutput struct {
+	AuthenticationUUID string `json:"authentication_uuid"`
+	Status             string `json:"status"`
+}
+
+func (o checkOutput) rateLimited() bool {
+	return o.Status == status.CheckRateLimited.String()
+}
+
+func runCheck(ctx context.Context, client *ding.Client, fs *flag.FlagSet, args []string) (interface{}, error) {
+	authUUID := fs.String("auth-uuid", "", "UUID of the authentication")
+	code := fs.String("code", "", "code entered by the user")
+
+	if err := parse(fs, args, "auth-uuid", "code"); err != nil {
+		return nil, err
+	}
+
+	check, err := client.CheckWithContext(ctx, *authUUID, *code)
+	if err != nil {
+		return nil, err
+	}
+
+	return checkOutput{
+		AuthenticationUUID: check.AuthenticationUUID,
+		Status:             check.Status.String(),
+	}, nil
+}
+
+type retryOutput struct {
+	AuthenticationUUID string `json:"authentication_uuid"`
+	Status             string `json:"status"`
+	NextRetryAt        string `json:"next_retry_at"`
+	RemainingRetry     int    `json:"remaining_retry"`
+}
+
+func (o retryOutput) rateLimited() bool {
+	return o.Status == status.RetryRateLimited.String()
+}
+
+func runRetry(ctx context.Context, client *ding.Client, fs *flag.FlagSet, args []string) (interface{}, error) {
+	authUUID := fs.String("auth-uuid", "", "UUID of the authentication")
+
+	if err := parse(fs, args, "auth-uuid"); err != nil {
+		return nil, err
+	}
+
+	retry, err := client.RetryWithContext(ctx, *authUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	return retryOutput{
+		AuthenticationUUID: retry.AuthenticationUUID,
+		Status:             retry.Status.String(),
+		NextRetryAt:        formatTime(retry.NextRetryAt),
+		RemainingRetry:     retry.RemainingRetry,
+	}, nil
+}
+
+// formatTime formats t as RFC 3339, or returns an empty string if t is zero.
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.Format(time.RFC3339)
+}
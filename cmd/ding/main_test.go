@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ding-live/ding-go/pkg/dingtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runTest(t *testing.T, srv *dingtest.Server, args ...string) (int, map[string]interface{}, string) {
+	var stdout, stderr bytes.Buffer
+
+	args = append([]string{
+		"-customer-uuid", dingtest.CustomerUUID,
+		"-api-key", dingtest.APIKey,
+		"-base-url", srv.URL,
+		"-json",
+	}, args...)
+
+	code := run(context.Background(), args, &stdout, &stderr)
+
+	var out map[string]interface{}
+	if stdout.Len() > 0 {
+		require.NoError(t, json.Unmarshal(stdout.Bytes(), &out))
+	}
+
+	return code, out, stderr.String()
+}
+
+func TestRun(t *testing.T) {
+	srv := dingtest.NewServer()
+	defer srv.Close()
+
+	code, out, _ := runTest(t, srv, "authenticate", "-phone", "+33612345678", "-channels", "whatsapp,sms")
+	require.Equal(t, 0, code)
+	assert.Equal(t, "pending", out["status"])
+	assert.Equal(t, "+33612345678", out["phone_number"])
+	assert.Equal(t, "whatsapp", out["channel"])
+
+	authUUID := out["authentication_uuid"].(string)
+
+	code, out, _ = runTest(t, srv, "retry", "-auth-uuid", authUUID)
+	require.Equal(t, 0, code)
+	assert.Equal(t, "approved", out["status"])
+
+	code, out, _ = runTest(t, srv, "check", "-auth-uuid", authUUID, "-code", dingtest.Code)
+	require.Equal(t, 0, code)
+	assert.Equal(t, "valid", out["status"])
+}
+
+func TestRunErrors(t *testing.T) {
+	srv := dingtest.NewServer()
+	defer srv.Close()
+
+	code, _, stderr := runTest(t, srv, "authenticate")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, "missing -phone")
+
+	code, _, stderr = runTest(t, srv, "unknown")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, `unknown command "unknown"`)
+
+	code, out, _ := runTest(t, srv, "check", "-auth-uuid", "invalid", "-code", "1234")
+	assert.Equal(t, exitInvalidInput, code)
+	assert.Equal(t, "invalid authentication UUID", out["error"])
+	assert.EqualValues(t, exitInvalidInput, out["exit_code"])
+}
+
+func TestRunTextOutput(t *testing.T) {
+	srv := dingtest.NewServer()
+	defer srv.Close()
+
+	var stdout, stderr bytes.Buffer
+
+	code := run(context.Background(), []string{
+		"-customer-uuid", dingtest.CustomerUUID,
+		"-api-key", dingtest.APIKey,
+		"-base-url", srv.URL,
+		"authenticate", "-phone", "+33612345678",
+	}, &stdout, &stderr)
+	require.Equal(t, exitOK, code)
+	assert.Contains(t, stdout.String(), "status:              pending\n")
+	assert.Contains(t, stdout.String(), "phone_number:        +33612345678\n")
+
+	stdout.Reset()
+
+	code = run(context.Background(), []string{
+		"-customer-uuid", dingtest.CustomerUUID,
+		"-api-key", "invalid",
+		"-base-url", srv.URL,
+		"authenticate", "-phone", "+33612345678",
+	}, &stdout, &stderr)
+	assert.Equal(t, exitUnauthorized, code)
+	assert.Empty(t, stdout.String())
+	assert.Contains(t, stderr.String(), "unauthorized")
+}
+
+func TestRunRateLimited(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"authentication_uuid":"5071dbf5-78d0-497a-b844-c1231808c3e9","status":"rate_limited"}`)
+	}))
+	defer srv.Close()
+
+	var stdout, stderr bytes.Buffer
+
+	code := run(context.Background(), []string{
+		"-customer-uuid", dingtest.CustomerUUID,
+		"-base-url", srv.URL,
+		"-json",
+		"authenticate", "-phone", "+33612345678",
+	}, &stdout, &stderr)
+	assert.Equal(t, exitRateLimited, code)
+	assert.Contains(t, stdout.String(), `"status": "rate_limited"`)
+}
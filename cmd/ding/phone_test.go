@@ -0,0 +1,20 @@
+//go:build !ding_nophonelib
+
+package main
+
+import (
+	"testing"
+
+	"github.com/ding-live/ding-go/pkg/dingtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunNationalNumber(t *testing.T) {
+	srv := dingtest.NewServer()
+	defer srv.Close()
+
+	code, out, _ := runTest(t, srv, "authenticate", "-phone", "06 12 34 56 78", "-region", "FR")
+	require.Equal(t, 0, code)
+	assert.Equal(t, "+33612345678", out["phone_number"])
+}
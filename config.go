@@ -0,0 +1,342 @@
+package ding
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// fileConfig is the format of the configuration files read by LoadConfigFile.
+type fileConfig struct {
+	CustomerUUID       string                     `yaml:"customer_uuid"`
+	APIKey             string                     `yaml:"api_key"`
+	BaseURL            string                     `yaml:"base_url"`
+	APIVersion         string                     `yaml:"api_version"`
+	Environment        string                     `yaml:"environment"`
+	Environments       map[string]fileEnvironment `yaml:"environments"`
+	MaxNetworkRetries  *int                       `yaml:"max_network_retries"`
+	Backoff            fileBackoff                `yaml:"backoff"`
+	Timeout            string                     `yaml:"timeout"`
+	Timeouts           map[string]string          `yaml:"timeouts"`
+	LogLevel           string                     `yaml:"log_level"`
+	RedactPhoneNumbers string                     `yaml:"redact_phone_numbers"`
+	DefaultPhoneRegion string                     `yaml:"default_phone_region"`
+	CodeLength         int                        `yaml:"code_length"`
+	CallbackSecret     string                     `yaml:"callback_secret"`
+	SigningKey         string                     `yaml:"signing_key"`
+	TLS                struct {
+		MinVersion   string   `yaml:"min_version"`
+		CipherSuites []string `yaml:"cipher_suites"`
+		RootCAFile   string   `yaml:"root_ca_file"`
+	} `yaml:"tls"`
+}
+
+type fileBackoff struct {
+	Initial        string  `yaml:"initial"`
+	Multiplier     float64 `yaml:"multiplier"`
+	MaxInterval    string  `yaml:"max_interval"`
+	MaxElapsedTime string  `yaml:"max_elapsed_time"`
+	FullJitter     bool    `yaml:"full_jitter"`
+}
+
+type fileEnvironment struct {
+	CustomerUUID string `yaml:"customer_uuid"`
+	APIKey       string `yaml:"api_key"`
+	BaseURL      string `yaml:"base_url"`
+	Production   bool   `yaml:"production"`
+}
+
+var tlsVersions = map[string]uint16{
+	"":    0,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+var phoneRedactions = map[string]PhoneRedaction{
+	"":         PhoneRedactionNone,
+	"none":     PhoneRedactionNone,
+	"truncate": PhoneRedactionTruncate,
+	"hash":     PhoneRedactionHash,
+}
+
+var logLevels = map[string]Level{
+	"":      LevelNull,
+	"none":  LevelNull,
+	"debug": LevelDebug,
+	"info":  LevelInfo,
+	"warn":  LevelWarn,
+	"error": LevelError,
+}
+
+// LoadConfigFile reads a client configuration from the YAML or JSON file at
+// path. It supports the following keys:
+//
+//	customer_uuid: ${DING_CUSTOMER_UUID}
+//	api_key: ${DING_API_KEY}
+//	base_url: https://api.ding.live/v1
+//	api_version: v1
+//	max_network_retries: 3
+//	backoff:
+//	  initial: 500ms
+//	  multiplier: 2
+//	  max_interval: 5s
+//	  max_elapsed_time: 3s
+//	  full_jitter: true
+//	timeout: 10s
+//	timeouts: # per endpoint, see Config.Timeouts
+//	  authentication: 15s
+//	  check: 3s
+//	log_level: warn # none, debug, info, warn or error
+//	redact_phone_numbers: hash # none, truncate or hash
+//	default_phone_region: FR
+//	code_length: 4
+//	callback_secret: ${DING_CALLBACK_SECRET}
+//	signing_key: ${DING_SIGNING_KEY}
+//	tls:
+//	  min_version: "1.3" # 1.2 or 1.3
+//	  cipher_suites: [TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384]
+//	  root_ca_file: /etc/ssl/ding-ca.pem
+//
+// Instead of customer_uuid, api_key and base_url, the file can describe every
+// environment and select one of them, as Config.Environments does. Defaults
+// to empty values let each deployment only set the variables of its own
+// environment:
+//
+//	environment: ${DING_ENVIRONMENT}
+//	environments:
+//	  staging:
+//	    customer_uuid: c9f826e0-deca-41ec-871f-ecd6e8efeb46
+//	    api_key: ${DING_STAGING_API_KEY:-}
+//	    base_url: http://ding-mock:8080
+//	  production:
+//	    customer_uuid: 4a2b9a4c-4d8e-4e36-a5d9-0e8f5b7c1d2e
+//	    api_key: ${DING_API_KEY:-}
+//	    production: true
+//
+// ${NAME} in values is replaced by the environment variable NAME, which must
+// be set, and ${NAME:-default} by default when NAME is unset or empty. Use $$
+// for a literal $.
+func LoadConfigFile(path string) (Config, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("read config: %w", err)
+	}
+
+	return parseConfig(path, b)
+}
+
+// DecryptFunc decrypts the content of an encrypted configuration file, for
+// instance with age or a KMS.
+type DecryptFunc func(ctx context.Context, ciphertext []byte) ([]byte, error)
+
+// LoadEncryptedConfigFile is like LoadConfigFile for files encrypted as a
+// whole, such as configuration bundles shipped to edge devices. The content
+// of the file is passed to decrypt, and the plaintext it returns is parsed as
+// by LoadConfigFile then zeroed.
+func LoadEncryptedConfigFile(ctx context.Context, path string, decrypt DecryptFunc) (Config, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("read config: %w", err)
+	}
+
+	plaintext, err := decrypt(ctx, b)
+	if err != nil {
+		return Config{}, fmt.Errorf("decrypt config %s: %w", path, err)
+	}
+
+	defer func() {
+		for i := range plaintext {
+			plaintext[i] = 0
+		}
+	}()
+
+	return parseConfig(path, plaintext)
+}
+
+// parseConfig parses the content b of the configuration file at path.
+func parseConfig(path string, b []byte) (Config, error) {
+	var (
+		root yaml.Node
+		err  error
+	)
+
+	if err := yaml.Unmarshal(b, &root); err != nil {
+		return Config{}, fmt.Errorf("parse config %s: %w", path, err)
+	}
+
+	// Variables are expanded in the parsed values rather than in the raw
+	// file, so that secrets containing YAML syntax are kept verbatim.
+	if err := expandNode(&root); err != nil {
+		return Config{}, fmt.Errorf("parse config %s: %w", path, err)
+	}
+
+	var fc fileConfig
+	if err := root.Decode(&fc); err != nil {
+		return Config{}, fmt.Errorf("parse config %s: %w", path, err)
+	}
+
+	cfg := Config{
+		CustomerUUID:       fc.CustomerUUID,
+		APIKey:             fc.APIKey,
+		BaseURL:            fc.BaseURL,
+		APIVersion:         fc.APIVersion,
+		Environment:        fc.Environment,
+		MaxNetworkRetries:  fc.MaxNetworkRetries,
+		DefaultPhoneRegion: fc.DefaultPhoneRegion,
+		CodeLength:         fc.CodeLength,
+		CallbackSecret:     fc.CallbackSecret,
+		SigningKey:         fc.SigningKey,
+	}
+
+	if fc.Environments != nil {
+		cfg.Environments = make(map[string]Environment, len(fc.Environments))
+		for name, env := range fc.Environments {
+			cfg.Environments[name] = Environment(env)
+		}
+	}
+
+	for _, d := range []struct {
+		name  string
+		value string
+		dst   *time.Duration
+	}{
+		{"timeout", fc.Timeout, &cfg.Timeout},
+		{"backoff initial delay", fc.Backoff.Initial, &cfg.Backoff.Initial},
+		{"backoff max interval", fc.Backoff.MaxInterval, &cfg.Backoff.MaxInterval},
+		{"backoff max elapsed time", fc.Backoff.MaxElapsedTime, &cfg.Backoff.MaxElapsedTime},
+	} {
+		if d.value == "" {
+			continue
+		}
+
+		*d.dst, err = time.ParseDuration(d.value)
+		if err != nil {
+			return Config{}, fmt.Errorf("parse config %s: invalid %s: %w", path, d.name, err)
+		}
+	}
+
+	for endpoint, value := range fc.Timeouts {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return Config{}, fmt.Errorf("parse config %s: invalid %s timeout: %w", path, endpoint, err)
+		}
+
+		if cfg.Timeouts == nil {
+			cfg.Timeouts = make(map[string]time.Duration, len(fc.Timeouts))
+		}
+		cfg.Timeouts[endpoint] = d
+	}
+
+	cfg.Backoff.Multiplier = fc.Backoff.Multiplier
+	cfg.Backoff.FullJitter = fc.Backoff.FullJitter
+
+	level, ok := logLevels[strings.ToLower(fc.LogLevel)]
+	if !ok {
+		return Config{}, fmt.Errorf("parse config %s: invalid log level %q", path, fc.LogLevel)
+	}
+
+	if fc.LogLevel != "" {
+		cfg.LeveledLogger = &Logger{Level: level}
+	}
+
+	cfg.RedactPhoneNumbers, ok = phoneRedactions[strings.ToLower(fc.RedactPhoneNumbers)]
+	if !ok {
+		return Config{}, fmt.Errorf("parse config %s: invalid phone number redaction %q", path, fc.RedactPhoneNumbers)
+	}
+
+	cfg.TLS.MinVersion, ok = tlsVersions[fc.TLS.MinVersion]
+	if !ok {
+		return Config{}, fmt.Errorf("parse config %s: invalid TLS version %q", path, fc.TLS.MinVersion)
+	}
+
+	for _, name := range fc.TLS.CipherSuites {
+		id, ok := cipherSuiteIDs()[name]
+		if !ok {
+			return Config{}, fmt.Errorf("parse config %s: unknown or insecure cipher suite %q", path, name)
+		}
+
+		cfg.TLS.CipherSuites = append(cfg.TLS.CipherSuites, id)
+	}
+
+	if fc.TLS.RootCAFile != "" {
+		cfg.TLS.RootCAs, err = LoadRootCAs(fc.TLS.RootCAFile)
+		if err != nil {
+			return Config{}, fmt.Errorf("parse config %s: %w", path, err)
+		}
+	}
+
+	return cfg, nil
+}
+
+// NewClientFromFile returns a new Ding client configured with the file at
+// path. See LoadConfigFile for its format. Use LoadConfigFile and NewClient
+// to set options that can't be written in a file, such as hooks.
+func NewClientFromFile(path string) (*Client, error) {
+	cfg, err := LoadConfigFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewClient(cfg)
+}
+
+var envVarPattern = regexp.MustCompile(`\$\$|\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+// expandNode replaces the environment variables referenced in the scalar
+// values of n and its children.
+func expandNode(n *yaml.Node) error {
+	if n.Kind == yaml.ScalarNode {
+		v, err := expandEnv(n.Value)
+		if err != nil {
+			return err
+		}
+
+		// Let the type of plain values be inferred again, so that numbers
+		// can come from the environment.
+		if v != n.Value && n.Style == 0 {
+			n.Tag = ""
+		}
+		n.Value = v
+
+		return nil
+	}
+
+	for _, c := range n.Content {
+		if err := expandNode(c); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func expandEnv(s string) (string, error) {
+	var err error
+
+	out := envVarPattern.ReplaceAllStringFunc(s, func(m string) string {
+		if m == "$$" {
+			return "$"
+		}
+
+		sub := envVarPattern.FindStringSubmatch(m)
+		name, hasDefault, def := sub[1], sub[2] != "", sub[3]
+
+		if v := os.Getenv(name); v != "" {
+			return v
+		}
+
+		if _, set := os.LookupEnv(name); !set && !hasDefault && err == nil {
+			err = fmt.Errorf("environment variable %s is not set", name)
+		}
+
+		return def
+	})
+
+	return out, err
+}
@@ -0,0 +1,195 @@
+package ding
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ding-live/ding-go/pkg/dingtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfigFile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	return path
+}
+
+func TestLoadConfigFileYAML(t *testing.T) {
+	t.Setenv("DING_TEST_API_KEY", "secret: #1")
+	t.Setenv("DING_TEST_RETRIES", "5")
+
+	path := writeConfigFile(t, "ding.yaml", `
+customer_uuid: c9f826e0-deca-41ec-871f-ecd6e8efeb46
+api_key: ${DING_TEST_API_KEY}
+base_url: ${DING_TEST_BASE_URL:-https://ding.example.com}
+max_network_retries: ${DING_TEST_RETRIES}
+backoff:
+  initial: 200ms
+  max_elapsed_time: 2s
+  full_jitter: true
+timeout: 10s
+timeouts:
+  check: 3s
+log_level: info
+redact_phone_numbers: truncate
+code_length: 6
+callback_secret: pa$$word
+tls:
+  min_version: 1.3
+  cipher_suites: [TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384]
+`)
+
+	cfg, err := LoadConfigFile(path)
+	require.NoError(t, err)
+
+	assert.Equal(t, "c9f826e0-deca-41ec-871f-ecd6e8efeb46", cfg.CustomerUUID)
+	assert.Equal(t, "secret: #1", cfg.APIKey)
+	assert.Equal(t, "https://ding.example.com", cfg.BaseURL)
+	require.NotNil(t, cfg.MaxNetworkRetries)
+	assert.Equal(t, 5, *cfg.MaxNetworkRetries)
+	assert.Equal(t, Backoff{
+		Initial:        200 * time.Millisecond,
+		MaxElapsedTime: 2 * time.Second,
+		FullJitter:     true,
+	}, cfg.Backoff)
+	assert.Equal(t, 10*time.Second, cfg.Timeout)
+	assert.Equal(t, map[string]time.Duration{"check": 3 * time.Second}, cfg.Timeouts)
+	assert.Equal(t, &Logger{Level: LevelInfo}, cfg.LeveledLogger)
+	assert.Equal(t, PhoneRedactionTruncate, cfg.RedactPhoneNumbers)
+	assert.Equal(t, 6, cfg.CodeLength)
+	assert.Equal(t, "pa$word", cfg.CallbackSecret)
+	assert.Equal(t, TLSPolicy{
+		MinVersion:   tls.VersionTLS13,
+		CipherSuites: []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384},
+	}, cfg.TLS)
+}
+
+func TestLoadConfigFileJSON(t *testing.T) {
+	t.Setenv("DING_TEST_API_KEY", "secret")
+
+	path := writeConfigFile(t, "ding.json", `{
+		"customer_uuid": "c9f826e0-deca-41ec-871f-ecd6e8efeb46",
+		"api_key": "${DING_TEST_API_KEY}",
+		"max_network_retries": 0
+	}`)
+
+	cfg, err := LoadConfigFile(path)
+	require.NoError(t, err)
+
+	assert.Equal(t, "secret", cfg.APIKey)
+	require.NotNil(t, cfg.MaxNetworkRetries)
+	assert.Equal(t, 0, *cfg.MaxNetworkRetries)
+	assert.Nil(t, cfg.LeveledLogger)
+}
+
+func TestLoadConfigFileEnvironments(t *testing.T) {
+	t.Setenv("DING_TEST_ENVIRONMENT", "staging")
+
+	path := writeConfigFile(t, "ding.yaml", `
+environment: ${DING_TEST_ENVIRONMENT}
+environments:
+  staging:
+    customer_uuid: `+dingtest.CustomerUUID+`
+    api_key: ${DING_TEST_STAGING_API_KEY:-}
+    base_url: http://localhost:8080
+  production:
+    customer_uuid: c9f826e0-deca-41ec-871f-ecd6e8efeb46
+    api_key: ${DING_TEST_API_KEY:-}
+    production: true
+`)
+
+	cfg, err := LoadConfigFile(path)
+	require.NoError(t, err)
+
+	assert.Equal(t, "staging", cfg.Environment)
+	assert.Equal(t, map[string]Environment{
+		"staging": {
+			CustomerUUID: dingtest.CustomerUUID,
+			BaseURL:      "http://localhost:8080",
+		},
+		"production": {
+			CustomerUUID: "c9f826e0-deca-41ec-871f-ecd6e8efeb46",
+			Production:   true,
+		},
+	}, cfg.Environments)
+}
+
+func TestLoadConfigFileErrors(t *testing.T) {
+	for name, content := range map[string]string{
+		"unset variable": "api_key: ${DING_TEST_UNSET}",
+		"bad timeout":    "timeout: soon",
+		"bad backoff":    "backoff: {initial: fast}",
+		"bad log level":  "log_level: loud",
+		"bad redaction":  "redact_phone_numbers: blur",
+		"bad TLS":        "tls: {min_version: 1.1}",
+		"bad suite":      "tls: {cipher_suites: [TLS_RSA_WITH_RC4_128_SHA]}",
+		"missing CA":     "tls: {root_ca_file: /nonexistent/ca.pem}",
+		"bad syntax":     "api_key: [",
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := LoadConfigFile(writeConfigFile(t, "ding.yaml", content))
+			assert.Error(t, err)
+		})
+	}
+
+	_, err := LoadConfigFile(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestNewClientFromFile(t *testing.T) {
+	srv := dingtest.NewServer()
+	defer srv.Close()
+
+	t.Setenv("DING_TEST_BASE_URL", srv.URL)
+
+	path := writeConfigFile(t, "ding.yaml", `
+customer_uuid: `+dingtest.CustomerUUID+`
+api_key: `+dingtest.APIKey+`
+base_url: ${DING_TEST_BASE_URL}
+timeout: 5s
+`)
+
+	client, err := NewClientFromFile(path)
+	require.NoError(t, err)
+
+	_, err = client.Authenticate(NewAuth("+33612345678"))
+	require.NoError(t, err)
+}
+
+func TestLoadEncryptedConfigFile(t *testing.T) {
+	// A toy cipher stands in for age or a KMS.
+	xor := func(_ context.Context, b []byte) ([]byte, error) {
+		out := make([]byte, len(b))
+		for i := range b {
+			out[i] = b[i] ^ 0x5a
+		}
+
+		return out, nil
+	}
+
+	plaintext := []byte("customer_uuid: " + dingtest.CustomerUUID + "\napi_key: secret\n")
+	ciphertext, _ := xor(context.Background(), plaintext)
+
+	path := writeConfigFile(t, "ding.yaml.enc", string(ciphertext))
+
+	cfg, err := LoadEncryptedConfigFile(context.Background(), path, xor)
+	require.NoError(t, err)
+
+	assert.Equal(t, dingtest.CustomerUUID, cfg.CustomerUUID)
+	assert.Equal(t, "secret", cfg.APIKey)
+
+	errDecrypt := errors.New("wrong key")
+	_, err = LoadEncryptedConfigFile(context.Background(), path, func(context.Context, []byte) ([]byte, error) {
+		return nil, errDecrypt
+	})
+	assert.ErrorIs(t, err, errDecrypt)
+}
@@ -0,0 +1,33 @@
+package ding
+
+import "time"
+
+// Consent records the consent of the user to receive a code, as evidence for
+// compliance audits. The Ding API has no field for it: the client validates it
+// and returns it on the Authentication, so that it can be stored along with
+// the AuthenticationUUID.
+type Consent struct {
+	// GivenAt is when the user gave their consent. Defaults to the time of
+	// the call to Authenticate.
+	GivenAt time.Time
+
+	// TermsVersion identifies the terms the user agreed to, such as "2024-03".
+	TermsVersion string
+
+	// Channel is the channel the user agreed to be contacted through, if the
+	// consent is specific to one.
+	Channel Channel
+}
+
+// normalize validates c and returns it with its defaults set.
+func (c Consent) normalize(now time.Time) (Consent, error) {
+	if c.Channel != "" && !isValidChannels([]Channel{c.Channel}) {
+		return c, ErrInvalidChannels
+	}
+
+	if c.GivenAt.IsZero() {
+		c.GivenAt = now
+	}
+
+	return c, nil
+}
@@ -0,0 +1,40 @@
+package ding
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ding-live/ding-go/pkg/dingtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConsent(t *testing.T) {
+	srv := dingtest.NewServer()
+	defer srv.Close()
+
+	client := newTestClient(t, srv)
+	givenAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	auth, err := client.Authenticate(NewAuth("+33612345678").WithConsent(Consent{
+		GivenAt:      givenAt,
+		TermsVersion: "2024-03",
+		Channel:      ChannelSMS,
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, &Consent{GivenAt: givenAt, TermsVersion: "2024-03", Channel: ChannelSMS}, auth.Consent)
+
+	before := time.Now()
+
+	auth, err = client.Authenticate(NewAuth("+33612345678").WithConsent(Consent{TermsVersion: "2024-03"}))
+	require.NoError(t, err)
+	require.NotNil(t, auth.Consent)
+	assert.False(t, auth.Consent.GivenAt.Before(before))
+
+	auth, err = client.Authenticate(NewAuth("+33612345678"))
+	require.NoError(t, err)
+	assert.Nil(t, auth.Consent)
+
+	_, err = client.Authenticate(NewAuth("+33612345678").WithConsent(Consent{Channel: "PIGEON"}))
+	assert.ErrorIs(t, err, ErrInvalidChannels)
+}
@@ -0,0 +1,144 @@
+package ding
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/ding-live/ding-go/internal/api"
+)
+
+// dedup coalesces identical authentications made within a short window, or
+// rejects them if reject is set.
+type dedup struct {
+	window time.Duration
+	reject bool
+
+	mu    sync.Mutex
+	calls map[string]*dedupCall
+}
+
+type dedupCall struct {
+	done chan struct{}
+	auth *Authentication
+	err  error
+
+	// cancelled is set when the context of the caller running the call was
+	// done, in which case its error is not shared with the other callers.
+	cancelled bool
+}
+
+// key returns the key of an authentication sending req with the extra fields.
+// Rejected duplicates share nothing with the first call, so they are keyed on
+// the phone number alone, which a retry storm with changing device signals
+// can't get around. Only identical requests are coalesced, so that a call
+// can't receive an authentication sent with the device, callback or template
+// of another.
+func (d *dedup) key(req api.AuthRequest, extra map[string]interface{}) (string, error) {
+	if d.reject {
+		return req.CustomerUUID + "/" + req.PhoneNumber, nil
+	}
+
+	h := sha256.New()
+	enc := json.NewEncoder(h)
+
+	if err := enc.Encode(req); err != nil {
+		return "", err
+	}
+
+	// Maps are encoded with sorted keys, so equal fields give equal keys.
+	if err := enc.Encode(extra); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func newDedup(window time.Duration, reject bool) *dedup {
+	if window <= 0 {
+		return nil
+	}
+
+	return &dedup{
+		window: window,
+		reject: reject,
+		calls:  make(map[string]*dedupCall),
+	}
+}
+
+// do calls fn unless a call for key is in flight or succeeded less than
+// d.window ago, in which case its result is returned instead, or
+// ErrDuplicateRequest if d.reject is set. fn must run with ctx: if ctx is done
+// before fn returns, the callers waiting for it call fn themselves instead of
+// receiving the context error of another.
+func (d *dedup) do(ctx context.Context, key string, fn func() (*Authentication, error)) (*Authentication, error) {
+	d.mu.Lock()
+
+	for {
+		call, ok := d.calls[key]
+		if !ok {
+			break
+		}
+
+		d.mu.Unlock()
+
+		if d.reject {
+			return nil, ErrDuplicateRequest
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-call.done:
+		}
+
+		if !call.cancelled {
+			return call.result()
+		}
+
+		d.mu.Lock()
+	}
+
+	call := &dedupCall{done: make(chan struct{})}
+	d.calls[key] = call
+	d.mu.Unlock()
+
+	call.auth, call.err = fn()
+	call.cancelled = call.err != nil && ctx.Err() != nil
+
+	forget := func() {
+		d.mu.Lock()
+		if d.calls[key] == call {
+			delete(d.calls, key)
+		}
+		d.mu.Unlock()
+	}
+
+	// Failed calls are not kept, so that the next call tries again. They are
+	// forgotten before the waiting callers are released, so that those that
+	// call fn themselves don't find the call again.
+	if call.err != nil {
+		forget()
+	} else {
+		time.AfterFunc(d.window, forget)
+	}
+
+	close(call.done)
+
+	return call.result()
+}
+
+// result returns a copy of the result of the call, so that callers can't
+// modify each other's authentication.
+func (c *dedupCall) result() (*Authentication, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	auth := *c.auth
+
+	return &auth, nil
+}
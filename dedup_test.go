@@ -0,0 +1,145 @@
+package ding
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ding-live/ding-go/pkg/dingtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeduplicationWindow(t *testing.T) {
+	srv := dingtest.NewServer()
+	defer srv.Close()
+
+	client, err := NewClient(Config{
+		CustomerUUID:        dingtest.CustomerUUID,
+		APIKey:              dingtest.APIKey,
+		BaseURL:             srv.URL,
+		DeduplicationWindow: 50 * time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	var (
+		wg    sync.WaitGroup
+		uuids [5]string
+	)
+
+	for i := range uuids {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			// Both formats normalize to the same number.
+			phone := "+33612345678"
+			if i%2 == 1 {
+				phone = "+33 6 12 34 56 78"
+			}
+
+			auth, err := client.Authenticate(NewAuth(phone))
+			require.NoError(t, err)
+
+			uuids[i] = auth.AuthenticationUUID
+		}(i)
+	}
+
+	wg.Wait()
+
+	for _, u := range uuids {
+		assert.Equal(t, uuids[0], u)
+	}
+
+	other, err := client.Authenticate(NewAuth("+33612345679"))
+	require.NoError(t, err)
+	assert.NotEqual(t, uuids[0], other.AuthenticationUUID)
+
+	time.Sleep(100 * time.Millisecond)
+
+	again, err := client.Authenticate(NewAuth("+33612345678"))
+	require.NoError(t, err)
+	assert.NotEqual(t, uuids[0], again.AuthenticationUUID)
+
+	// Calls with other options are not coalesced.
+	withIP, err := client.Authenticate(NewAuth("+33612345678").WithIP("192.0.2.1"))
+	require.NoError(t, err)
+	assert.NotEqual(t, again.AuthenticationUUID, withIP.AuthenticationUUID)
+
+	withExtra, err := client.Authenticate(NewAuth("+33612345678").WithExtra("locale", "fr"))
+	require.NoError(t, err)
+	assert.NotEqual(t, again.AuthenticationUUID, withExtra.AuthenticationUUID)
+	assert.NotEqual(t, withIP.AuthenticationUUID, withExtra.AuthenticationUUID)
+}
+
+func TestDeduplicationCancelled(t *testing.T) {
+	d := newDedup(time.Minute, false)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	started, first := make(chan struct{}), make(chan error, 1)
+
+	go func() {
+		_, err := d.do(ctx, "key", func() (*Authentication, error) {
+			close(started)
+			<-ctx.Done()
+			return nil, ctx.Err()
+		})
+		first <- err
+	}()
+
+	<-started
+
+	result := make(chan error, 1)
+
+	go func() {
+		auth, err := d.do(context.Background(), "key", func() (*Authentication, error) {
+			return &Authentication{AuthenticationUUID: "second"}, nil
+		})
+		if err == nil && auth.AuthenticationUUID != "second" {
+			err = ErrInternal
+		}
+		result <- err
+	}()
+
+	// The waiting call runs its own request instead of sharing the
+	// cancellation of the first.
+	cancel()
+	assert.ErrorIs(t, <-first, context.Canceled)
+	assert.NoError(t, <-result)
+}
+
+func TestRejectDuplicates(t *testing.T) {
+	srv := dingtest.NewServer()
+	defer srv.Close()
+
+	client, err := NewClient(Config{
+		CustomerUUID:        dingtest.CustomerUUID,
+		APIKey:              dingtest.APIKey,
+		BaseURL:             srv.URL,
+		DeduplicationWindow: 50 * time.Millisecond,
+		RejectDuplicates:    true,
+	})
+	require.NoError(t, err)
+
+	first, err := client.Authenticate(NewAuth("+33612345678"))
+	require.NoError(t, err)
+
+	_, err = client.Authenticate(NewAuth("+33 6 12 34 56 78"))
+	require.ErrorIs(t, err, ErrDuplicateRequest)
+
+	// Calls with other options are rejected as well.
+	_, err = client.Authenticate(NewAuth("+33612345678").WithIP("192.0.2.1"))
+	require.ErrorIs(t, err, ErrDuplicateRequest)
+
+	// Other numbers are not affected.
+	_, err = client.Authenticate(NewAuth("+33612345679"))
+	require.NoError(t, err)
+
+	time.Sleep(100 * time.Millisecond)
+
+	again, err := client.Authenticate(NewAuth("+33612345678"))
+	require.NoError(t, err)
+	assert.NotEqual(t, first.AuthenticationUUID, again.AuthenticationUUID)
+}
@@ -0,0 +1,133 @@
+package ding
+
+import (
+	"net/netip"
+	"strings"
+)
+
+// DeviceSignals describes the device of the user being authenticated. All
+// fields are optional, but the more are set, the better the Ding antispam
+// system performs. Along with AuthenticateOptions.IsReturningUser, they are
+// the only antispam signals accepted by the API.
+type DeviceSignals struct {
+	// IP is the IPv4 or IPv6 address of the user. It can be given with a
+	// port or a zone, such as the RemoteAddr of an http.Request, which are
+	// stripped before sending. Addresses that can't be parsed are rejected
+	// with ErrInvalidIP.
+	IP string
+
+	// ID uniquely identifies the device, such as the identifierForVendor on
+	// iOS or the ANDROID_ID on Android.
+	ID string
+
+	// Type is the type of the device. It must be one of the DeviceType values.
+	Type DeviceType
+
+	// AppVersion is the version of your app running on the device.
+	AppVersion string
+
+	// OSVersion is the version of the operating system of the device.
+	OSVersion string
+
+	// Model is the model of the device, such as "iPhone15,2".
+	Model string
+
+	// UserAgent is the user agent of the browser, for web authentications.
+	UserAgent string
+}
+
+// normalize validates d and returns it with the IP address in canonical form.
+func (d DeviceSignals) normalize() (DeviceSignals, error) {
+	if d.IP != "" {
+		addr, err := parseIP(d.IP)
+		if err != nil {
+			return d, err
+		}
+
+		d.IP = addr.Unmap().String()
+	}
+
+	switch d.Type {
+	case "", DeviceTypeAndroid, DeviceTypeIOS, DeviceTypeWeb:
+	default:
+		return d, ErrInvalidDeviceSignals
+	}
+
+	return d, nil
+}
+
+// deviceSignals merges the legacy device fields of opt with opt.Device, the
+// latter taking precedence.
+func (opt AuthenticateOptions) deviceSignals() DeviceSignals {
+	var d DeviceSignals
+
+	if opt.IP != nil {
+		d.IP = *opt.IP
+	}
+
+	if opt.DeviceID != nil {
+		d.ID = *opt.DeviceID
+	}
+
+	if opt.DeviceType != nil {
+		d.Type = *opt.DeviceType
+	}
+
+	if opt.AppVersion != nil {
+		d.AppVersion = *opt.AppVersion
+	}
+
+	if opt.Device == nil {
+		return d
+	}
+
+	if opt.Device.IP != "" {
+		d.IP = opt.Device.IP
+	}
+
+	if opt.Device.ID != "" {
+		d.ID = opt.Device.ID
+	}
+
+	if opt.Device.Type != "" {
+		d.Type = opt.Device.Type
+	}
+
+	if opt.Device.AppVersion != "" {
+		d.AppVersion = opt.Device.AppVersion
+	}
+
+	d.OSVersion = opt.Device.OSVersion
+	d.Model = opt.Device.Model
+	d.UserAgent = opt.Device.UserAgent
+
+	return d
+}
+
+// parseIP parses an IP address, optionally followed by a port as in
+// "192.168.0.1:443" or "[2001:db8::1]:443", and drops its zone.
+func parseIP(s string) (netip.Addr, error) {
+	if addrPort, err := netip.ParseAddrPort(s); err == nil {
+		return addrPort.Addr().WithZone(""), nil
+	}
+
+	if strings.HasPrefix(s, "[") && strings.HasSuffix(s, "]") {
+		s = s[1 : len(s)-1]
+	}
+
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Addr{}, ErrInvalidIP
+	}
+
+	return addr.WithZone(""), nil
+}
+
+// optionalString returns a pointer to s, or nil if s is empty.
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+
+	return &s
+}
@@ -0,0 +1,77 @@
+package ding
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/ding-live/ding-go/pkg/dingtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeviceSignals(t *testing.T) {
+	opt := NewAuth("+33612345678").
+		WithIP("192.168.0.1").
+		WithDevice(DeviceTypeAndroid, "legacy-id")
+
+	assert.Equal(t, DeviceSignals{
+		IP:   "192.168.0.1",
+		ID:   "legacy-id",
+		Type: DeviceTypeAndroid,
+	}, opt.deviceSignals())
+
+	opt = opt.WithDeviceSignals(DeviceSignals{
+		ID:        "device-id",
+		Type:      DeviceTypeWeb,
+		UserAgent: "Mozilla/5.0",
+	})
+
+	assert.Equal(t, DeviceSignals{
+		IP:        "192.168.0.1",
+		ID:        "device-id",
+		Type:      DeviceTypeWeb,
+		UserAgent: "Mozilla/5.0",
+	}, opt.deviceSignals())
+}
+
+func TestInvalidDeviceSignals(t *testing.T) {
+	srv := dingtest.NewServer()
+	defer srv.Close()
+
+	client := newTestClient(t, srv)
+
+	_, err := client.Authenticate(NewAuth("+33612345678").WithDeviceSignals(DeviceSignals{
+		Type: DeviceType("TOASTER"),
+	}))
+	assert.ErrorIs(t, err, ErrInvalidDeviceSignals)
+}
+
+func TestDeviceSignalsIP(t *testing.T) {
+	for _, tc := range []struct {
+		ip   string
+		want string
+		err  error
+	}{
+		{ip: "192.168.0.1", want: "192.168.0.1"},
+		{ip: "2001:DB8::1", want: "2001:db8::1"},
+		{ip: "::ffff:192.168.0.1", want: "192.168.0.1"},
+		{ip: "fe80::1%eth0", want: "fe80::1"},
+		{ip: "192.168.0.1:52431", want: "192.168.0.1"},
+		{ip: "[2001:db8::1]:52431", want: "2001:db8::1"},
+		{ip: "[fe80::1%eth0]:52431", want: "fe80::1"},
+		{ip: "[::ffff:192.168.0.1]", want: "192.168.0.1"},
+		{ip: "192.168.0.1:", err: ErrInvalidIP},
+		{ip: "192.168.0", err: ErrInvalidIP},
+		{ip: "localhost", err: ErrInvalidIP},
+	} {
+		d, err := DeviceSignals{IP: tc.ip}.normalize()
+		if tc.err != nil {
+			assert.ErrorIs(t, err, tc.err, tc.ip)
+			continue
+		}
+
+		assert.NoError(t, err, tc.ip)
+		assert.Equal(t, tc.want, d.IP, tc.ip)
+	}
+
+	assert.Equal(t, String("192.168.0.1"), NewAuth("+33612345678").WithIPAddr(netip.MustParseAddr("::ffff:192.168.0.1")).IP)
+}
@@ -0,0 +1,84 @@
+package ding
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+// Environment holds the credentials and the API used by the client in one
+// deployment environment, such as staging. See Config.Environments.
+type Environment struct {
+	// CustomerUUID is the UUID of the account used in this environment.
+	CustomerUUID string
+
+	// APIKey is the API key used in this environment.
+	APIKey string
+
+	// BaseURL is the URL of the Ding API used in this environment, such as
+	// the URL of a ding-mock server.
+	//
+	// Defaults to https://api.ding.live/v1, which requires Production.
+	BaseURL string
+
+	// Production allows the environment to send requests to the production
+	// Ding API, which delivers real codes and is billed. Other environments
+	// fail to create a client rather than reach it by mistake.
+	//
+	// Defaults to false.
+	Production bool
+}
+
+var (
+	// ErrUnknownEnvironment is returned when Config.Environment is not a key
+	// of Config.Environments, or is empty while Config.Environments is not.
+	ErrUnknownEnvironment = errors.New("unknown environment")
+
+	// ErrProductionNotAllowed is returned when an environment which isn't
+	// marked as Production would send requests to the production Ding API.
+	ErrProductionNotAllowed = errors.New("environment is not allowed to use the production Ding API")
+)
+
+// withEnvironment returns cfg with the credentials and the base URL of the
+// environment it selects, if any.
+func (cfg Config) withEnvironment() (Config, error) {
+	if cfg.Environment == "" && len(cfg.Environments) == 0 {
+		return cfg, nil
+	}
+
+	env, ok := cfg.Environments[cfg.Environment]
+	if !ok {
+		return Config{}, fmt.Errorf("%w %q", ErrUnknownEnvironment, cfg.Environment)
+	}
+
+	if cfg.CustomerUUID != "" || cfg.APIKey != "" || len(cfg.APIKeys) > 0 || cfg.BaseURL != "" {
+		return Config{}, fmt.Errorf("environment %q: CustomerUUID, APIKey and BaseURL must be set on the environment only", cfg.Environment)
+	}
+
+	if !env.Production && isProductionURL(env.BaseURL) {
+		return Config{}, fmt.Errorf("environment %q: %w", cfg.Environment, ErrProductionNotAllowed)
+	}
+
+	cfg.CustomerUUID = env.CustomerUUID
+	cfg.APIKey = env.APIKey
+	cfg.BaseURL = env.BaseURL
+
+	return cfg, nil
+}
+
+// isProductionURL reports whether baseURL, or the default base URL when empty,
+// points at the production Ding API.
+func isProductionURL(baseURL string) bool {
+	if baseURL == "" {
+		return true
+	}
+
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return false
+	}
+
+	prod, _ := url.Parse(apiBaseURL)
+
+	return u.Hostname() == prod.Hostname()
+}
@@ -0,0 +1,71 @@
+package ding
+
+import (
+	"testing"
+
+	"github.com/ding-live/ding-go/pkg/dingtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvironment(t *testing.T) {
+	srv := dingtest.NewServer()
+	defer srv.Close()
+
+	environments := map[string]Environment{
+		"staging": {
+			CustomerUUID: dingtest.CustomerUUID,
+			APIKey:       dingtest.APIKey,
+			BaseURL:      srv.URL,
+		},
+		"production": {
+			CustomerUUID: "c9f826e0-deca-41ec-871f-ecd6e8efeb46",
+			APIKey:       "production_api_key",
+			Production:   true,
+		},
+	}
+
+	client, err := NewClient(Config{Environments: environments, Environment: "staging"})
+	require.NoError(t, err)
+
+	_, err = client.Authenticate(NewAuth("+33612345678"))
+	require.NoError(t, err)
+
+	_, err = NewClient(Config{Environments: environments, Environment: "production"})
+	require.NoError(t, err)
+}
+
+func TestEnvironmentErrors(t *testing.T) {
+	staging := map[string]Environment{
+		"staging": {CustomerUUID: dingtest.CustomerUUID, APIKey: dingtest.APIKey},
+	}
+
+	_, err := NewClient(Config{Environments: staging, Environment: "staging"})
+	assert.ErrorIs(t, err, ErrProductionNotAllowed)
+
+	staging["staging"] = Environment{
+		CustomerUUID: dingtest.CustomerUUID,
+		APIKey:       dingtest.APIKey,
+		BaseURL:      "https://api.ding.live/v2",
+	}
+
+	_, err = NewClient(Config{Environments: staging, Environment: "staging"})
+	assert.ErrorIs(t, err, ErrProductionNotAllowed)
+
+	_, err = NewClient(Config{Environments: staging})
+	assert.ErrorIs(t, err, ErrUnknownEnvironment)
+
+	_, err = NewClient(Config{Environments: staging, Environment: "prod"})
+	assert.ErrorIs(t, err, ErrUnknownEnvironment)
+
+	_, err = NewClient(Config{Environment: "staging"})
+	assert.ErrorIs(t, err, ErrUnknownEnvironment)
+
+	// Credentials are only taken from the environment.
+	_, err = NewClient(Config{
+		Environments: staging,
+		Environment:  "staging",
+		CustomerUUID: dingtest.CustomerUUID,
+	})
+	assert.Error(t, err)
+}
@@ -0,0 +1,241 @@
+package ding_test
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	ding "github.com/ding-live/ding-go"
+	"github.com/ding-live/ding-go/pkg/dingtest"
+)
+
+// newExampleClient returns a client talking to a fake Ding API server, so that
+// the examples below can run without credentials.
+func newExampleClient(srv *dingtest.Server) *ding.Client {
+	client, err := ding.NewClient(ding.Config{
+		CustomerUUID: dingtest.CustomerUUID,
+		APIKey:       dingtest.APIKey,
+		BaseURL:      srv.URL,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return client
+}
+
+func ExampleNewClient() {
+	client, err := ding.NewClient(ding.Config{
+		CustomerUUID:      os.Getenv("DING_CUSTOMER_UUID"),
+		APIKey:            os.Getenv("DING_API_KEY"),
+		MaxNetworkRetries: ding.Int(4),
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	_ = client
+}
+
+func ExampleNewClient_customHTTPClient() {
+	client, err := ding.NewClient(ding.Config{
+		CustomerUUID:      os.Getenv("DING_CUSTOMER_UUID"),
+		APIKey:            os.Getenv("DING_API_KEY"),
+		MaxNetworkRetries: ding.Int(4),
+		CustomHTTPClient:  &http.Client{Timeout: 10 * time.Second},
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	_ = client
+}
+
+type exampleLogger struct{}
+
+func (l *exampleLogger) Debugf(format string, v ...interface{}) {
+	log.Printf("DEBUG: "+format, v...)
+}
+
+func (l *exampleLogger) Errorf(format string, v ...interface{}) {
+	log.Printf("ERROR: "+format, v...)
+}
+
+func (l *exampleLogger) Infof(format string, v ...interface{}) {
+	log.Printf("INFO: "+format, v...)
+}
+
+func (l *exampleLogger) Warnf(format string, v ...interface{}) {
+	log.Printf("WARN: "+format, v...)
+}
+
+func ExampleNewClient_customLogger() {
+	client, err := ding.NewClient(ding.Config{
+		CustomerUUID:      os.Getenv("DING_CUSTOMER_UUID"),
+		APIKey:            os.Getenv("DING_API_KEY"),
+		MaxNetworkRetries: ding.Int(4),
+		LeveledLogger:     &exampleLogger{},
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	_ = client
+}
+
+func ExampleNewClient_disableLogging() {
+	client, err := ding.NewClient(ding.Config{
+		CustomerUUID: os.Getenv("DING_CUSTOMER_UUID"),
+		APIKey:       os.Getenv("DING_API_KEY"),
+		LeveledLogger: &ding.Logger{
+			Level: ding.LevelNull,
+		},
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	_ = client
+}
+
+func ExampleClient_Authenticate() {
+	srv := dingtest.NewServer()
+	defer srv.Close()
+
+	client := newExampleClient(srv)
+
+	auth, err := client.Authenticate(ding.AuthenticateOptions{
+		PhoneNumber: "+33612345678",
+		Device: &ding.DeviceSignals{
+			IP:         "192.168.0.1",
+			Type:       ding.DeviceTypeIOS,
+			AppVersion: "1.2.0",
+			OSVersion:  "17.4",
+		},
+		CallbackURL:     ding.String("https://example.com/callback"),
+		IsReturningUser: true,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println(auth.Status)
+	// Output: pending
+}
+
+func ExampleClient_Check() {
+	srv := dingtest.NewServer()
+	defer srv.Close()
+
+	client := newExampleClient(srv)
+
+	auth, err := client.Authenticate(ding.AuthenticateOptions{
+		PhoneNumber: "+33612345678",
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	check, err := client.Check(auth.AuthenticationUUID, dingtest.Code)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println(check.Status)
+	// Output: valid
+}
+
+func ExampleClient_Retry() {
+	srv := dingtest.NewServer()
+	defer srv.Close()
+
+	client := newExampleClient(srv)
+
+	auth, err := client.Authenticate(ding.AuthenticateOptions{
+		PhoneNumber: "+33612345678",
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	retry, err := client.Retry(auth.AuthenticationUUID)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println(retry.Status, retry.RemainingRetry)
+	// Output: approved 2
+}
+
+func ExampleNewAuth() {
+	srv := dingtest.NewServer()
+	defer srv.Close()
+
+	client := newExampleClient(srv)
+
+	auth, err := client.Authenticate(ding.NewAuth("+33612345678").
+		WithIP("192.168.0.1").
+		WithDevice(ding.DeviceTypeAndroid, "0b8f2a4e").
+		WithCallback("https://example.com/callback"))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println(auth.Status)
+	// Output: pending
+}
+
+func ExampleClient_VerifyCode() {
+	srv := dingtest.NewServer()
+	defer srv.Close()
+
+	client := newExampleClient(srv)
+
+	auth, err := client.Authenticate(ding.NewAuth("+33612345678"))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for _, code := range []string{"0000", dingtest.Code} {
+		ok, err := client.VerifyCode(context.Background(), auth.AuthenticationUUID, code)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		fmt.Println(code, ok)
+	}
+	// Output:
+	// 0000 false
+	// 1234 true
+}
+
+func ExampleClient_Do() {
+	srv := dingtest.NewServer()
+	defer srv.Close()
+
+	client := newExampleClient(srv)
+
+	auth, err := client.Authenticate(ding.NewAuth("+33612345678"))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	in := map[string]string{
+		"customer_uuid":       dingtest.CustomerUUID,
+		"authentication_uuid": auth.AuthenticationUUID,
+	}
+
+	var out struct {
+		Status string `json:"status"`
+	}
+
+	if err := client.Do(context.Background(), http.MethodPost, "status", in, &out); err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println(out.Status)
+	// Output: pending
+}
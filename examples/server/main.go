@@ -0,0 +1,274 @@
+// Command server is a reference OTP server built on the session package. It
+// exposes the whole verification flow behind a small JSON API:
+//
+//	POST /verifications         {"phone_number": "+33612345678"}  send a code
+//	POST /verifications/resend                                    send a new code
+//	POST /verifications/check   {"code": "1234"}                  check a code
+//	POST /callbacks/ding                                          receive callbacks
+//
+// Verifications belong to the user named by the X-User-ID header. In your app,
+// the user would come from your own authentication instead, such as a session
+// cookie: never let clients pick the user they verify.
+//
+// The server reads its credentials from the DING_CUSTOMER_UUID, DING_API_KEY
+// and DING_CALLBACK_SECRET environment variables. Run it with -fake to try it
+// against the fake server of the dingtest package, which always sends the code
+// dingtest.Code.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"time"
+
+	ding "github.com/ding-live/ding-go"
+	"github.com/ding-live/ding-go/pkg/dingtest"
+	"github.com/ding-live/ding-go/pkg/session"
+	"github.com/ding-live/ding-go/pkg/webhook"
+)
+
+func main() {
+	addr := flag.String("addr", "localhost:8080", "address to listen on")
+	fake := flag.Bool("fake", false, "use a fake Ding API")
+	callbackURL := flag.String("callback-url", "", "public URL of /callbacks/ding, to receive callbacks")
+	flag.Parse()
+
+	cfg := ding.Config{
+		CustomerUUID:   os.Getenv("DING_CUSTOMER_UUID"),
+		APIKey:         os.Getenv("DING_API_KEY"),
+		CallbackSecret: os.Getenv("DING_CALLBACK_SECRET"),
+	}
+
+	if *fake {
+		srv := dingtest.NewServer()
+		defer srv.Close()
+
+		cfg.CustomerUUID = dingtest.CustomerUUID
+		cfg.APIKey = dingtest.APIKey
+		cfg.BaseURL = srv.URL
+	}
+
+	client, err := ding.NewClient(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	manager, err := session.NewManager(session.Config{
+		Client:         client,
+		ResendCooldown: 30 * time.Second,
+		MaxAttempts:    5,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	srv := &http.Server{
+		Addr:              *addr,
+		Handler:           newHandler(client, manager, *callbackURL),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	go func() {
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		// Stop accepting requests, then let the calls in flight to the Ding
+		// API complete.
+		_ = srv.Shutdown(shutdownCtx)
+		_ = client.Close(shutdownCtx)
+	}()
+
+	log.Printf("listening on %s", *addr)
+
+	if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		log.Fatal(err)
+	}
+}
+
+type server struct {
+	client      *ding.Client
+	manager     *session.Manager
+	callbackURL string
+}
+
+func newHandler(client *ding.Client, manager *session.Manager, callbackURL string) http.Handler {
+	s := &server{client: client, manager: manager, callbackURL: callbackURL}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/verifications", s.post(s.start))
+	mux.HandleFunc("/verifications/resend", s.post(s.resend))
+	mux.HandleFunc("/verifications/check", s.post(s.check))
+	mux.HandleFunc("/callbacks/ding", s.callback)
+
+	return mux
+}
+
+// post only accepts POST requests from identified users.
+func (s *server) post(h func(w http.ResponseWriter, r *http.Request, userID string)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+
+		userID := r.Header.Get("X-User-ID")
+		if userID == "" {
+			writeError(w, http.StatusUnauthorized, "missing X-User-ID header")
+			return
+		}
+
+		h(w, r, userID)
+	}
+}
+
+type verificationResponse struct {
+	PhoneNumber       string    `json:"phone_number"`
+	ExpiresAt         time.Time `json:"expires_at"`
+	ResendAvailableAt time.Time `json:"resend_available_at"`
+}
+
+func (s *server) start(w http.ResponseWriter, r *http.Request, userID string) {
+	var req struct {
+		PhoneNumber string `json:"phone_number"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid body")
+		return
+	}
+
+	opt := ding.NewAuth(req.PhoneNumber).WithDeviceSignals(ding.DeviceSignals{
+		IP:        clientIP(r),
+		Type:      ding.DeviceTypeWeb,
+		UserAgent: r.UserAgent(),
+	})
+
+	if s.callbackURL != "" {
+		opt = opt.WithCallback(s.callbackURL)
+	}
+
+	sess, err := s.manager.Start(r.Context(), userID, opt)
+	if err != nil {
+		writeSessionError(w, sess, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, newVerificationResponse(sess))
+}
+
+func (s *server) resend(w http.ResponseWriter, r *http.Request, userID string) {
+	sess, err := s.manager.Resend(r.Context(), userID)
+	if err != nil {
+		writeSessionError(w, sess, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newVerificationResponse(sess))
+}
+
+func (s *server) check(w http.ResponseWriter, r *http.Request, userID string) {
+	var req struct {
+		Code string `json:"code"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid body")
+		return
+	}
+
+	ok, err := s.manager.Verify(r.Context(), userID, req.Code)
+	if err != nil {
+		writeSessionError(w, nil, err)
+		return
+	}
+
+	// This is where your app would mark the phone number of the user as
+	// verified, or complete the sign in.
+	writeJSON(w, http.StatusOK, map[string]bool{"verified": ok})
+}
+
+// callback receives the callbacks sent by Ding to the callback URL given when
+// authenticating. Use it to follow the delivery of codes.
+func (s *server) callback(w http.ResponseWriter, r *http.Request) {
+	body, err := s.client.VerifyCallback(r)
+	if errors.Is(err, webhook.ErrInvalidSignature) || errors.Is(err, webhook.ErrExpiredSignature) {
+		writeError(w, http.StatusUnauthorized, "invalid signature")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid callback")
+		return
+	}
+
+	log.Printf("callback: %s", body)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func newVerificationResponse(sess *session.Session) verificationResponse {
+	return verificationResponse{
+		PhoneNumber:       sess.PhoneNumber,
+		ExpiresAt:         sess.ExpiresAt,
+		ResendAvailableAt: sess.ResendAvailableAt,
+	}
+}
+
+// writeSessionError maps the errors of the session manager and of the Ding
+// client to HTTP statuses. sess is the verification in progress, if known.
+func writeSessionError(w http.ResponseWriter, sess *session.Session, err error) {
+	switch {
+	case errors.Is(err, session.ErrCooldown):
+		if sess != nil {
+			wait := time.Until(sess.ResendAvailableAt).Seconds()
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait))))
+		}
+		writeError(w, http.StatusTooManyRequests, err.Error())
+	case errors.Is(err, session.ErrTooManyAttempts), errors.Is(err, session.ErrResendDenied):
+		writeError(w, http.StatusTooManyRequests, err.Error())
+	case errors.Is(err, session.ErrNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, session.ErrExpired):
+		writeError(w, http.StatusGone, err.Error())
+	case errors.Is(err, session.ErrAlreadyVerified):
+		writeError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, ding.ErrInvalidPhoneNumber), errors.Is(err, ding.ErrUnsupportedRegion),
+		errors.Is(err, ding.ErrInvalidCodeFormat):
+		writeError(w, http.StatusBadRequest, err.Error())
+	default:
+		log.Printf("verification failed: %v", err)
+		writeError(w, http.StatusInternalServerError, "internal error")
+	}
+}
+
+func writeError(w http.ResponseWriter, code int, message string) {
+	writeJSON(w, code, map[string]string{"error": message})
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// clientIP returns the IP address of the client of r. Behind a proxy, read it
+// from the header set by the proxy instead.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return ""
+	}
+
+	return host
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	ding "github.com/ding-live/ding-go"
+	"github.com/ding-live/ding-go/pkg/dingtest"
+	"github.com/ding-live/ding-go/pkg/session"
+	"github.com/ding-live/ding-go/pkg/webhook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const callbackSecret = "callback_secret_test"
+
+func newTestHandler(t *testing.T) http.Handler {
+	srv := dingtest.NewServer()
+	t.Cleanup(srv.Close)
+
+	client, err := ding.NewClient(ding.Config{
+		CustomerUUID:   dingtest.CustomerUUID,
+		APIKey:         dingtest.APIKey,
+		BaseURL:        srv.URL,
+		CallbackSecret: callbackSecret,
+	})
+	require.NoError(t, err)
+
+	manager, err := session.NewManager(session.Config{Client: client})
+	require.NoError(t, err)
+
+	return newHandler(client, manager, "")
+}
+
+func post(t *testing.T, h http.Handler, path, userID string, body interface{}) *httptest.ResponseRecorder {
+	b, err := json.Marshal(body)
+	require.NoError(t, err)
+
+	r := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(b))
+	if userID != "" {
+		r.Header.Set("X-User-ID", userID)
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	return w
+}
+
+func TestVerificationFlow(t *testing.T) {
+	h := newTestHandler(t)
+
+	w := post(t, h, "/verifications", "alice", map[string]string{"phone_number": "+33612345678"})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	w = post(t, h, "/verifications/resend", "alice", nil)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+
+	w = post(t, h, "/verifications/check", "alice", map[string]string{"code": "0000"})
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"verified":false}`, w.Body.String())
+
+	w = post(t, h, "/verifications/check", "alice", map[string]string{"code": dingtest.Code})
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"verified":true}`, w.Body.String())
+
+	w = post(t, h, "/verifications/check", "alice", map[string]string{"code": dingtest.Code})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestVerificationErrors(t *testing.T) {
+	h := newTestHandler(t)
+
+	w := post(t, h, "/verifications", "", map[string]string{"phone_number": "+33612345678"})
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = post(t, h, "/verifications", "bob", map[string]string{"phone_number": "invalid"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = post(t, h, "/verifications/resend", "bob", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/verifications", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+}
+
+func TestCallback(t *testing.T) {
+	h := newTestHandler(t)
+	body := []byte(`{"authentication_uuid":"5071dbf5-78d0-497a-b844-c1231808c3e9"}`)
+
+	r := httptest.NewRequest(http.MethodPost, "/callbacks/ding", bytes.NewReader(body))
+	r.Header.Set(webhook.SignatureHeader, webhook.Sign(callbackSecret, body, time.Now()))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	r = httptest.NewRequest(http.MethodPost, "/callbacks/ding", bytes.NewReader(body))
+	r.Header.Set(webhook.SignatureHeader, webhook.Sign("wrong_callback_secret", body, time.Now()))
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
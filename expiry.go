@@ -0,0 +1,80 @@
+package ding
+
+import (
+	"fmt"
+	"time"
+)
+
+// ExpiresIn returns how long the authentication remains valid at now, or 0 if
+// it has expired.
+//
+// The result does not depend on the local clock being in sync with the Ding
+// servers: the difference between the clocks, given by the Date header of the
+// response, is compensated. Without a Date header, the lifetime of an
+// authentication returned by Authenticate is counted down from the moment its
+// response was received, and the local clock is used for the others.
+func (a *Authentication) ExpiresIn(now time.Time) time.Duration {
+	return a.until(a.ExpiresAt, now)
+}
+
+// Expired reports whether the authentication has expired at now. See ExpiresIn
+// for how clock skew is handled.
+func (a *Authentication) Expired(now time.Time) bool {
+	return a.ExpiresIn(now) == 0
+}
+
+// RetryIn returns how long to wait at now before Retry can send a new code, or
+// 0 if it can be called right away. See ExpiresIn for how clock skew is
+// handled.
+func (a *Authentication) RetryIn(now time.Time) time.Duration {
+	return a.until(a.NextRetryAt, now)
+}
+
+// until returns the time left at now before t, a time given by the API.
+func (a *Authentication) until(t, now time.Time) time.Duration {
+	remaining := t.Sub(now.Add(a.clockOffset))
+
+	// Authentications are created when Authenticate receives them, which
+	// tells the clock of the API when the response has no Date header.
+	if !a.hasClockOffset && !a.receivedAt.IsZero() && !a.CreatedAt.IsZero() {
+		remaining = t.Sub(a.CreatedAt) - now.Sub(a.receivedAt)
+	}
+
+	if remaining < 0 {
+		return 0
+	}
+
+	return remaining
+}
+
+// RetryIn returns how long to wait at now before another retry can be
+// requested, or 0 if it can be requested right away. The difference between
+// the local clock and the one of the Ding servers, as given by the Date header
+// of the response, is compensated.
+func (r *Retry) RetryIn(now time.Time) time.Duration {
+	remaining := r.NextRetryAt.Sub(now.Add(r.clockOffset))
+
+	if remaining < 0 {
+		return 0
+	}
+
+	return remaining
+}
+
+// FormatCountdown formats d for display to users, such as "4:59" or
+// "1:02:03". Durations are rounded up to the second, so that a countdown only
+// shows "0:00" once it is over, and negative durations are shown as "0:00".
+func FormatCountdown(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+
+	secs := int64((d + time.Second - 1) / time.Second)
+	h, m, s := secs/3600, secs/60%60, secs%60
+
+	if h > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", h, m, s)
+	}
+
+	return fmt.Sprintf("%d:%02d", m, s)
+}
@@ -0,0 +1,176 @@
+package ding
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ding-live/ding-go/pkg/dingtest"
+	"github.com/ding-live/ding-go/pkg/status"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpiresIn(t *testing.T) {
+	now := time.Now()
+
+	// The server clock is one hour ahead of the local one.
+	serverNow := now.Add(time.Hour)
+
+	a := &Authentication{
+		CreatedAt:  serverNow,
+		ExpiresAt:  serverNow.Add(5 * time.Minute),
+		receivedAt: now,
+	}
+
+	assert.Equal(t, 5*time.Minute, a.ExpiresIn(now))
+	assert.Equal(t, 2*time.Minute, a.ExpiresIn(now.Add(3*time.Minute)))
+	assert.False(t, a.Expired(now.Add(3*time.Minute)))
+
+	assert.Equal(t, time.Duration(0), a.ExpiresIn(now.Add(6*time.Minute)))
+	assert.True(t, a.Expired(now.Add(6*time.Minute)))
+}
+
+func TestExpiresInWithoutReceivedAt(t *testing.T) {
+	now := time.Now()
+
+	a := &Authentication{
+		ExpiresAt: now.Add(time.Minute),
+	}
+
+	assert.Equal(t, time.Minute, a.ExpiresIn(now))
+	assert.True(t, a.Expired(now.Add(time.Minute)))
+}
+
+func TestRetryIn(t *testing.T) {
+	now := time.Now()
+
+	// The server clock is one hour ahead of the local one.
+	serverNow := now.Add(time.Hour)
+
+	a := &Authentication{
+		CreatedAt:   serverNow,
+		NextRetryAt: serverNow.Add(30 * time.Second),
+		receivedAt:  now,
+	}
+
+	assert.Equal(t, 30*time.Second, a.RetryIn(now))
+	assert.Equal(t, time.Duration(0), a.RetryIn(now.Add(time.Minute)))
+
+	r := &Retry{
+		NextRetryAt: serverNow.Add(30 * time.Second),
+		clockOffset: time.Hour,
+	}
+
+	assert.Equal(t, 30*time.Second, r.RetryIn(now))
+	assert.Equal(t, time.Duration(0), r.RetryIn(now.Add(time.Minute)))
+}
+
+func TestClockOffset(t *testing.T) {
+	hc := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The server clock is ten minutes behind the local one.
+		w.Header().Set("Date", time.Now().Add(-10*time.Minute).UTC().Format(http.TimeFormat))
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"authentication_uuid":%q,"status":"approved","next_retry_at":%q}`,
+			dingtest.CustomerUUID, time.Now().Add(-10*time.Minute+time.Minute).Format(time.RFC3339))
+	}))
+	defer hc.Close()
+
+	client, err := NewClient(Config{
+		CustomerUUID: dingtest.CustomerUUID,
+		BaseURL:      hc.URL,
+	})
+	require.NoError(t, err)
+
+	retry, err := client.Retry(dingtest.CustomerUUID)
+	require.NoError(t, err)
+
+	// A naive countdown would be negative.
+	assert.InDelta(t, time.Minute, retry.RetryIn(time.Now()), float64(2*time.Second))
+}
+
+func TestExpiresInStatus(t *testing.T) {
+	hc := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The server clock is one hour ahead of the local one, and the
+		// authentication was created four minutes ago.
+		serverNow := time.Now().Add(time.Hour)
+		createdAt := serverNow.Add(-4 * time.Minute)
+
+		w.Header().Set("Date", serverNow.UTC().Format(http.TimeFormat))
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"authentication_uuid":%q,"status":"pending","created_at":%q,"expires_at":%q}`,
+			dingtest.CustomerUUID, createdAt.Format(time.RFC3339), createdAt.Add(5*time.Minute).Format(time.RFC3339))
+	}))
+	defer hc.Close()
+
+	client, err := NewClient(Config{
+		CustomerUUID: dingtest.CustomerUUID,
+		BaseURL:      hc.URL,
+	})
+	require.NoError(t, err)
+
+	a, err := client.AuthenticationStatus(dingtest.CustomerUUID)
+	require.NoError(t, err)
+
+	// The authentication was not created when it was received.
+	assert.InDelta(t, time.Minute, a.ExpiresIn(time.Now()), float64(2*time.Second))
+}
+
+func TestFormatCountdown(t *testing.T) {
+	assert.Equal(t, "4:59", FormatCountdown(4*time.Minute+58*time.Second+time.Millisecond))
+	assert.Equal(t, "0:00", FormatCountdown(0))
+	assert.Equal(t, "0:00", FormatCountdown(-time.Second))
+	assert.Equal(t, "0:01", FormatCountdown(time.Millisecond))
+	assert.Equal(t, "1:02:03", FormatCountdown(time.Hour+2*time.Minute+3*time.Second))
+}
+
+func TestAuthenticationTTL(t *testing.T) {
+	srv := dingtest.NewServer()
+	defer srv.Close()
+
+	client := newTestClient(t, srv)
+
+	auth, err := client.Authenticate(NewAuth("+33612345678").WithTTL(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, auth.CreatedAt.Add(time.Minute), auth.ExpiresAt)
+	assert.True(t, auth.Expired(time.Now().Add(2*time.Minute)))
+
+	// The TTL can't extend the default lifetime.
+	auth, err = client.Authenticate(NewAuth("+33612345678").WithTTL(24 * time.Hour))
+	require.NoError(t, err)
+	assert.True(t, auth.ExpiresAt.Before(auth.CreatedAt.Add(24*time.Hour)))
+
+	_, err = client.Authenticate(NewAuth("+33612345678").WithTTL(time.Second))
+	assert.ErrorIs(t, err, ErrInvalidTTL)
+}
+
+func TestAuthenticationTTLCheck(t *testing.T) {
+	srv := dingtest.NewServer()
+	defer srv.Close()
+
+	client := newTestClient(t, srv)
+
+	auth, err := client.Authenticate(NewAuth("+33612345678").WithTTL(time.Minute))
+	require.NoError(t, err)
+
+	assert.False(t, client.deadlines.expired(auth.AuthenticationUUID, time.Now()))
+	assert.True(t, client.deadlines.expired(auth.AuthenticationUUID, time.Now().Add(time.Minute)))
+
+	// Past the TTL, the code is rejected although the API would accept it,
+	// including by derived clients.
+	client.deadlines.set(auth.AuthenticationUUID, time.Now())
+
+	check, err := client.With(WithTimeout(time.Second)).Check(auth.AuthenticationUUID, dingtest.Code)
+	require.NoError(t, err)
+	assert.Equal(t, status.CheckExpiredAuth, check.Status)
+
+	// Authentications sent without a TTL are left to the API.
+	auth, err = client.Authenticate(NewAuth("+33612345679"))
+	require.NoError(t, err)
+
+	check, err = client.Check(auth.AuthenticationUUID, dingtest.Code)
+	require.NoError(t, err)
+	assert.Equal(t, status.CheckValid, check.Status)
+}
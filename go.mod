@@ -1,10 +1,17 @@
 module github.com/ding-live/ding-go
 
-go 1.16
+go 1.18
 
 require (
 	github.com/google/uuid v1.3.0
-	github.com/hashicorp/go-retryablehttp v0.7.2
 	github.com/nyaruka/phonenumbers v1.1.6
 	github.com/stretchr/testify v1.8.2
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/golang/protobuf v1.3.2 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/text v0.3.7 // indirect
 )
@@ -0,0 +1,164 @@
+package ding
+
+import (
+	"time"
+
+	"github.com/ding-live/ding-go/internal/api"
+	"github.com/ding-live/ding-go/pkg/status"
+)
+
+// Hooks are functions called by the client as it talks to the Ding API, for
+// instance to export metrics. Hooks are called synchronously and must be safe
+// for concurrent use.
+//
+// A panic in a hook, or in any other function given to the client such as
+// Config.ShouldRetry, Config.Transport or Config.OptOutList, is recovered and
+// logged, and the call it happened in fails with an error wrapping ErrPanic.
+// Panics of Config.LeveledLogger are written to os.Stderr and otherwise
+// ignored.
+type Hooks struct {
+	// OnRequest is called after every request to the Ding API, once its
+	// response headers are received or once it failed. A call retried because
+	// of network errors counts as a single request.
+	OnRequest func(RequestStats)
+
+	// OnAuthenticationBlocked is called when the API refuses to send a code
+	// because it detected spam or rate limited the number, for instance to
+	// monitor toll fraud per country.
+	OnAuthenticationBlocked func(BlockedAuthentication)
+
+	// OnAPIKeyFailover is called once, when the client switches to
+	// Config.FallbackAPIKey because the API refused the primary key, so that
+	// you can look into why the key was refused. The client keeps using the
+	// fallback key for the rest of its life, so create a new one to switch
+	// back to the primary key.
+	OnAPIKeyFailover func()
+
+	// OnDeprecation is called for every response of the Ding API marking its
+	// endpoint as deprecated with the Deprecation or Sunset header, for
+	// instance to raise an alert before the endpoint is removed. The client
+	// also logs a warning the first time each endpoint is reported.
+	OnDeprecation func(Deprecation)
+}
+
+// Deprecation describes an endpoint of the Ding API that is deprecated.
+type Deprecation struct {
+	Method string
+
+	// Path is the path of the endpoint, relative to Config.BaseURL.
+	Path string
+
+	// Since is the date of the deprecation, or zero if the API didn't give
+	// one.
+	Since time.Time
+
+	// Sunset is the date after which the endpoint may be removed, or zero if
+	// the API didn't give one.
+	Sunset time.Time
+}
+
+func (h Hooks) onDeprecation() func(api.DeprecationNotice) {
+	if h.OnDeprecation == nil {
+		return nil
+	}
+
+	return func(n api.DeprecationNotice) {
+		h.OnDeprecation(Deprecation(n))
+	}
+}
+
+// BlockedAuthentication describes an authentication for which no code was
+// sent. It carries the country of the phone number but never the number
+// itself.
+type BlockedAuthentication struct {
+	// Status is status.AuthSpamDetected or status.AuthRateLimited.
+	Status status.Auth
+
+	// CountryCode is the country calling code of the number, such as 33, or
+	// 0 if it is unknown.
+	CountryCode int
+
+	// Region is the two-letter ISO code of the region of the number, or an
+	// empty string if it is unknown.
+	Region string
+}
+
+// authenticated calls OnAuthenticationBlocked if a was blocked.
+func (h Hooks) authenticated(a *Authentication) {
+	if h.OnAuthenticationBlocked == nil {
+		return
+	}
+
+	switch a.Status {
+	case status.AuthSpamDetected, status.AuthRateLimited:
+		h.OnAuthenticationBlocked(BlockedAuthentication{
+			Status:      a.Status,
+			CountryCode: a.PhoneNumber.CountryCode,
+			Region:      a.PhoneNumber.Region,
+		})
+	}
+}
+
+// RequestStats describes a request sent to the Ding API.
+type RequestStats struct {
+	Method string
+
+	// Path is the path of the endpoint, relative to Config.BaseURL.
+	Path string
+
+	// StatusCode is the HTTP status of the response, or 0 if none was
+	// received.
+	StatusCode int
+
+	// Duration is the time it took to receive the response headers.
+	Duration time.Duration
+
+	// Err is the error that prevented a response from being received.
+	Err error
+
+	// Conn describes the connection used by the last attempt of the request.
+	Conn ConnStats
+}
+
+// ConnStats tells how the connection of a request was obtained, which helps
+// telling apart connection churn from API latency. It is only filled when
+// requests are sent over HTTP; durations are zero when the corresponding step
+// didn't happen, such as when a connection is reused.
+type ConnStats struct {
+	// Reused is true if the connection was already used by a previous
+	// request.
+	Reused bool
+
+	// WasIdle is true if the connection was taken from the idle pool, in
+	// which case IdleTime is how long it sat there.
+	WasIdle  bool
+	IdleTime time.Duration
+
+	DNS     time.Duration
+	Connect time.Duration
+	TLS     time.Duration
+}
+
+func (h Hooks) onRequest() func(api.RequestStats) {
+	if h.OnRequest == nil {
+		return nil
+	}
+
+	return func(s api.RequestStats) {
+		h.OnRequest(RequestStats{
+			Method:     s.Method,
+			Path:       s.Path,
+			StatusCode: s.StatusCode,
+			Duration:   s.Duration,
+			Err:        s.Err,
+			Conn: ConnStats{
+				Reused:   s.Conn.Reused,
+				WasIdle:  s.Conn.WasIdle,
+				IdleTime: s.Conn.IdleTime,
+				DNS:      s.Conn.DNS,
+				Connect:  s.Conn.Connect,
+				TLS:      s.Conn.TLS,
+			},
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package ding
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/ding-live/ding-go/pkg/dingtest"
+	"github.com/ding-live/ding-go/pkg/status"
+	"github.com/ding-live/ding-go/pkg/transport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHooks(t *testing.T) {
+	srv := dingtest.NewServer()
+	defer srv.Close()
+
+	var (
+		mu    sync.Mutex
+		stats []RequestStats
+	)
+
+	client, err := NewClient(Config{
+		CustomerUUID: dingtest.CustomerUUID,
+		APIKey:       dingtest.APIKey,
+		BaseURL:      srv.URL,
+		Hooks: Hooks{
+			OnRequest: func(s RequestStats) {
+				mu.Lock()
+				stats = append(stats, s)
+				mu.Unlock()
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	auth, err := client.Authenticate(NewAuth("+33612345678"))
+	require.NoError(t, err)
+
+	_, err = client.Check(auth.AuthenticationUUID, dingtest.Code)
+	require.NoError(t, err)
+
+	require.Len(t, stats, 2)
+
+	assert.Equal(t, "authentication", stats[0].Path)
+	assert.Equal(t, 200, stats[0].StatusCode)
+	assert.NoError(t, stats[0].Err)
+	assert.False(t, stats[0].Conn.Reused)
+	assert.NotZero(t, stats[0].Conn.Connect)
+
+	assert.Equal(t, "check", stats[1].Path)
+	assert.True(t, stats[1].Conn.Reused)
+	assert.Zero(t, stats[1].Conn.Connect)
+}
+
+func TestOnAuthenticationBlocked(t *testing.T) {
+	srv := dingtest.NewServer()
+	defer srv.Close()
+
+	srv.SetScenario("+33612345679", dingtest.Scenario{Status: "spam_detected"})
+
+	var blocked []BlockedAuthentication
+
+	client, err := NewClient(Config{
+		CustomerUUID: dingtest.CustomerUUID,
+		APIKey:       dingtest.APIKey,
+		BaseURL:      srv.URL,
+		Hooks: Hooks{
+			OnAuthenticationBlocked: func(b BlockedAuthentication) {
+				blocked = append(blocked, b)
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = client.Authenticate(NewAuth("+33612345678"))
+	require.NoError(t, err)
+	assert.Empty(t, blocked)
+
+	_, err = client.Authenticate(NewAuth("+33612345679"))
+	require.NoError(t, err)
+	require.Len(t, blocked, 1)
+	assert.Equal(t, status.AuthSpamDetected, blocked[0].Status)
+}
+
+type panickingLogger struct{}
+
+func (panickingLogger) Debugf(string, ...interface{}) { panic("debug") }
+func (panickingLogger) Errorf(string, ...interface{}) { panic("error") }
+func (panickingLogger) Infof(string, ...interface{})  { panic("info") }
+func (panickingLogger) Warnf(string, ...interface{})  { panic("warn") }
+
+type panickingOptOutList struct {
+	*MemoryOptOutList
+}
+
+func (panickingOptOutList) Contains(context.Context, string) (bool, error) {
+	panic("contains")
+}
+
+func TestPanickingHooks(t *testing.T) {
+	srv := dingtest.NewServer()
+	defer srv.Close()
+
+	srv.SetScenario("+33612345679", dingtest.Scenario{Status: "spam_detected"})
+
+	newClient := func(cfg Config) *Client {
+		cfg.CustomerUUID = dingtest.CustomerUUID
+		cfg.APIKey = dingtest.APIKey
+		cfg.BaseURL = srv.URL
+		cfg.LeveledLogger = panickingLogger{}
+
+		client, err := NewClient(cfg)
+		require.NoError(t, err)
+
+		return client
+	}
+
+	tests := map[string]struct {
+		cfg   Config
+		phone string
+	}{
+		"OnRequest": {
+			cfg:   Config{Hooks: Hooks{OnRequest: func(RequestStats) { panic("boom") }}},
+			phone: "+33612345678",
+		},
+		"OnAuthenticationBlocked": {
+			cfg:   Config{Hooks: Hooks{OnAuthenticationBlocked: func(BlockedAuthentication) { panic("boom") }}},
+			phone: "+33612345679",
+		},
+		"Transport": {
+			cfg: Config{Transport: transport.Func(func(context.Context, transport.Request) (transport.Response, error) {
+				panic("boom")
+			})},
+			phone: "+33612345678",
+		},
+		"ShouldRetry": {
+			cfg: Config{
+				Transport: transport.Func(func(context.Context, transport.Request) (transport.Response, error) {
+					return transport.Response{}, &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+				}),
+				ShouldRetry: func(*http.Response, error, int) bool { panic("boom") },
+			},
+			phone: "+33612345678",
+		},
+		"OptOutList": {
+			cfg:   Config{OptOutList: panickingOptOutList{NewMemoryOptOutList()}},
+			phone: "+33612345678",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			client := newClient(tt.cfg)
+
+			_, err := client.Authenticate(NewAuth(tt.phone))
+			require.ErrorIs(t, err, ErrPanic)
+		})
+	}
+
+	// Panics of the logger alone don't fail calls.
+	client := newClient(Config{})
+
+	_, err := client.Authenticate(NewAuth("+33612345678"))
+	require.NoError(t, err)
+}
@@ -1,22 +1,44 @@
 package api
 
 import (
-	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net"
 	"net/http"
+	"strings"
+	"sync"
 	"time"
 
-	"github.com/ding-live/ding-go/pkg/status"
-	"github.com/hashicorp/go-retryablehttp"
+	"github.com/ding-live/ding-go/pkg/transport"
 )
 
 type API struct {
-	baseURL       string
-	apiKey        string
-	hc            *http.Client
-	leveledLogger LeveledLogger
+	baseURL        string
+	apiKey         string
+	keys           *keyRing
+	failover       *failover
+	hc             *http.Client
+	maxRetries     int
+	backoff        Backoff
+	budget         *retryBudget
+	retryPredicate ShouldRetryFunc
+	timeout        time.Duration
+	timeouts       map[string]time.Duration
+	transport      transport.Transport
+	maxBodySize    int64
+	onRequest      func(RequestStats)
+	onDeprecation  func(DeprecationNotice)
+	deprecations   *sync.Map
+	leveledLogger  LeveledLogger
+	header         http.Header
+	extra          map[string]interface{}
+	onResponse     func(transport.Request, transport.Response)
+	signingKey     string
+	clock          *clock
 }
 
 type Config struct {
@@ -25,28 +47,107 @@ type Config struct {
 	MaxNetworkRetries *int
 	CustomHTTPClient  *http.Client
 	LeveledLogger     LeveledLogger
+
+	// APIKeys replaces APIKey when set, spreading requests across the keys.
+	APIKeys []WeightedAPIKey
+
+	// FallbackAPIKey replaces the other keys once a request is refused with
+	// them, after which OnFailover is called.
+	FallbackAPIKey string
+	OnFailover     func()
+
+	// TLSConfig configures the default HTTP client when set. It is ignored
+	// when CustomHTTPClient is set.
+	TLSConfig *tls.Config
+
+	// DialContext opens the connections of the default HTTP client when set.
+	// It is ignored when CustomHTTPClient is set.
+	DialContext func(ctx context.Context, network, addr string) (net.Conn, error)
+
+	// Backoff sets the delays between retries.
+	Backoff Backoff
+
+	// RetryBudget caps the retries of all the requests of the client.
+	RetryBudget RetryBudget
+
+	// ShouldRetry decides which failed attempts of requests that are safe to
+	// send twice are retried. Defaults to IsRetryable.
+	ShouldRetry ShouldRetryFunc
+
+	// Timeout bounds every request, retries included. Zero means no timeout.
+	Timeout time.Duration
+
+	// Timeouts overrides Timeout for the endpoints at the given paths, such
+	// as "check".
+	Timeouts map[string]time.Duration
+
+	// SigningKey signs every request with the SignatureHeader when set.
+	SigningKey string
+
+	// Transport replaces CustomHTTPClient when set.
+	Transport transport.Transport
+
+	// MaxBodySize bounds the size of response bodies. Defaults to
+	// DefaultMaxBodySize.
+	MaxBodySize int64
+
+	// OnRequest is called once the response headers of every request are
+	// received, or when it fails.
+	OnRequest func(RequestStats)
+
+	// OnDeprecation is called for every response marking its endpoint as
+	// deprecated.
+	OnDeprecation func(DeprecationNotice)
 }
 
 func New(cfg Config) (*API, error) {
-	client := retryablehttp.NewClient()
+	a := &API{
+		baseURL:        cfg.BaseURL,
+		apiKey:         cfg.APIKey,
+		keys:           newKeyRing(cfg.APIKeys),
+		failover:       newFailover(cfg.FallbackAPIKey, cfg.OnFailover),
+		hc:             cfg.CustomHTTPClient,
+		maxRetries:     defaultMaxRetries,
+		backoff:        cfg.Backoff.withDefaults(),
+		budget:         newRetryBudget(cfg.RetryBudget),
+		retryPredicate: cfg.ShouldRetry,
+		timeout:        cfg.Timeout,
+		transport:      cfg.Transport,
+		maxBodySize:    cfg.MaxBodySize,
+		onRequest:      cfg.OnRequest,
+		onDeprecation:  cfg.OnDeprecation,
+		deprecations:   &sync.Map{},
+		leveledLogger:  cfg.LeveledLogger,
+		signingKey:     cfg.SigningKey,
+		clock:          &clock{},
+	}
 
-	if cfg.MaxNetworkRetries != nil {
-		client.RetryMax = *cfg.MaxNetworkRetries
+	if a.hc == nil {
+		t := http.DefaultTransport.(*http.Transport).Clone()
+		if cfg.TLSConfig != nil {
+			t.TLSClientConfig = cfg.TLSConfig
+		}
+
+		if cfg.DialContext != nil {
+			t.DialContext = cfg.DialContext
+		}
+
+		a.hc = &http.Client{Transport: t}
 	}
 
-	if cfg.CustomHTTPClient != nil {
-		client.HTTPClient = cfg.CustomHTTPClient
+	if len(cfg.Timeouts) > 0 {
+		a.timeouts = make(map[string]time.Duration, len(cfg.Timeouts))
+		for path, d := range cfg.Timeouts {
+			a.timeouts[strings.TrimPrefix(path, "/")] = d
+		}
 	}
 
-	if cfg.LeveledLogger != nil {
-		client.Logger = convertLogger(cfg.LeveledLogger)
+	if cfg.MaxNetworkRetries != nil {
+		a.maxRetries = *cfg.MaxNetworkRetries
 	}
 
-	a := &API{
-		baseURL:       cfg.BaseURL,
-		apiKey:        cfg.APIKey,
-		hc:            client.StandardClient(),
-		leveledLogger: cfg.LeveledLogger,
+	if a.maxBodySize <= 0 {
+		a.maxBodySize = DefaultMaxBodySize
 	}
 
 	if a.leveledLogger == nil {
@@ -56,235 +157,419 @@ func New(cfg Config) (*API, error) {
 	return a, nil
 }
 
-const APIKeyHeader = "x-api-key"
+// WithLogger returns a copy of a that logs to logger. The copy shares the
+// underlying HTTP client, and thus its connection pool, with a.
+func (a *API) WithLogger(logger LeveledLogger) *API {
+	b := *a
+	b.leveledLogger = logger
 
-type AuthSuccessResponse struct {
-	AuthenticationUUID string      `json:"authentication_uuid"`
-	Status             status.Auth `json:"status"`
-	CreatedAt          time.Time   `json:"created_at"`
-	ExpiresAt          time.Time   `json:"expires_at"`
+	return &b
 }
 
-type ErrorResponse struct {
-	Code    ErrorCode `json:"code"`
-	Message string    `json:"message"`
-	DocURL  string    `json:"doc_url"`
+// WithTimeout returns a copy of a whose requests, retries included, time out
+// after d, whatever their endpoint. The copy shares the underlying HTTP client
+// with a.
+func (a *API) WithTimeout(d time.Duration) *API {
+	b := *a
+	b.timeout = d
+	b.timeouts = nil
+
+	return &b
 }
 
-type ErrorCode string
-
-const (
-	ErrorCodeInternalServer     ErrorCode = "internal_server_error"
-	ErrorCodeBadRequest         ErrorCode = "bad_request"
-	ErrorCodeInvalidPhoneNumber ErrorCode = "invalid_phone_number"
-	ErrorCodeAccountInvalid     ErrorCode = "account_invalid"
-	ErrorCodeNegativeBalance    ErrorCode = "negative_balance"
-	ErrorCodeInvalidLine        ErrorCode = "invalid_line"
-	ErrorCodeUnsupportedRegion  ErrorCode = "unsupported_region"
-	ErrorCodeInvalidAuthUUID    ErrorCode = "invalid_auth_uuid"
-)
+// WithMaxRetries returns a copy of a that retries failed requests at most n
+// times. The copy shares the underlying HTTP client with a.
+func (a *API) WithMaxRetries(n int) *API {
+	b := *a
+	b.maxRetries = n
 
-type AuthRequest struct {
-	PhoneNumber     string  `json:"phone_number,omitempty"`
-	CustomerUUID    string  `json:"customer_uuid,omitempty"`
-	IP              *string `json:"ip,omitempty"`
-	DeviceID        *string `json:"device_id,omitempty"`
-	DeviceType      *string `json:"device_type,omitempty"`
-	AppVersion      *string `json:"app_version,omitempty"`
-	CallbackURL     *string `json:"callback_url,omitempty"`
-	IsReturningUser *bool   `json:"is_returning_user,omitempty"`
+	return &b
 }
 
-type CheckRequest struct {
-	CustomerUUID       string `json:"customer_uuid"`
-	AuthenticationUUID string `json:"authentication_uuid"`
-	CheckCode          string `json:"check_code"`
-}
+// WithHeader returns a copy of a that sends the key header with value on every
+// request. The copy shares the underlying HTTP client with a.
+func (a *API) WithHeader(key, value string) *API {
+	b := *a
+	b.header = a.header.Clone()
+
+	if b.header == nil {
+		b.header = make(http.Header)
+	}
 
-type CheckSuccessResponse struct {
-	AuthenticationUUID string       `json:"authentication_uuid"`
-	Status             status.Check `json:"status"`
+	b.header.Set(key, value)
+
+	return &b
 }
 
-type RetryRequest struct {
-	CustomerUUID       string `json:"customer_uuid"`
-	AuthenticationUUID string `json:"authentication_uuid"`
+// WithOnResponse returns a copy of a that calls fn with the final response of
+// every request, before its body is read. The copy shares the underlying HTTP
+// client with a.
+func (a *API) WithOnResponse(fn func(transport.Request, transport.Response)) *API {
+	b := *a
+	b.onResponse = fn
+
+	return &b
 }
 
-type RetrySuccessResponse struct {
-	AuthenticationUUID string       `json:"authentication_uuid"`
-	Status             status.Retry `json:"status"`
-	CreatedAt          time.Time    `json:"created_at"`
-	NextRetryAt        time.Time    `json:"next_retry_at"`
-	RemainingRetry     int          `json:"remaining_retry"`
+// Logger returns the logger of a.
+func (a *API) Logger() LeveledLogger {
+	return a.leveledLogger
 }
 
-type GatewayErrorMessage struct {
-	Message string `json:"message"`
+// CloseIdleConnections closes the idle connections of the underlying HTTP
+// client.
+func (a *API) CloseIdleConnections() {
+	if a.transport != nil {
+		return
+	}
+
+	a.hc.CloseIdleConnections()
 }
 
+//go:generate go run ./gen -spec openapi.json -out types_gen.go
+
+const APIKeyHeader = "x-api-key"
+
+// RequestIDHeader is the header identifying a request in the logs of the Ding
+// API.
+const RequestIDHeader = "x-request-id"
+
+// DeprecationHeader is the standard header of RFC 9745 marking an endpoint as
+// deprecated.
+const DeprecationHeader = "Deprecation"
+
 var (
 	ErrInternal     = fmt.Errorf("internal error")
 	ErrUnauthorized = fmt.Errorf("unauthorized")
+	ErrUnreachable  = fmt.Errorf("unreachable")
+
+	// ErrNotSent is returned when a request failed before being sent, such as
+	// when the connection was refused, so that the API can't have processed
+	// it. It wraps ErrUnreachable.
+	ErrNotSent = fmt.Errorf("%w: request not sent", ErrUnreachable)
 )
 
+// ResponseError is an error that occurred once a response was received, such
+// as ErrUnauthorized or a response that couldn't be decoded.
+type ResponseError struct {
+	// RequestID is the value of the RequestIDHeader of the response.
+	RequestID string
+	Err       error
+}
+
+func (e *ResponseError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ResponseError) Unwrap() error {
+	return e.Err
+}
+
 // ----------------------------------------------------------------------------
 
-// TODO(2024-25) -> abstract each endpoint parsing logic using generics
-type AuthenticationResponse struct {
+// Response is the result of a call to an endpoint: exactly one of Error and
+// Success is set.
+type Response[T any] struct {
 	Error   *ErrorResponse
-	Success *AuthSuccessResponse
+	Success *T
+
+	// Raw holds the top-level fields of a successful response that don't
+	// map to a field of T, such as fields added to the API since.
+	Raw map[string]json.RawMessage
+
+	// RequestID is the value of the RequestIDHeader of the response.
+	RequestID string
+
+	// Deprecation is the value of the DeprecationHeader of the response, set
+	// when the endpoint is deprecated.
+	Deprecation string
+
+	// Sunset is the value of the SunsetHeader of the response, set when the
+	// removal of the endpoint is scheduled.
+	Sunset string
+
+	// ClockOffset is the difference between the clocks of the API and of
+	// this machine when the response was received, as given by its Date
+	// header. It is zero when too small to be measured.
+	ClockOffset time.Duration
+
+	// HasClockOffset reports whether the response had a valid Date header,
+	// and thus whether ClockOffset was measured.
+	HasClockOffset bool
 }
 
+type AuthenticationResponse = Response[AuthSuccessResponse]
+
 func (a *API) Authentication(ctx context.Context, req AuthRequest) (*AuthenticationResponse, error) {
-	res, err := a.post(ctx, "authentication", req)
+	return do[AuthRequest, AuthSuccessResponse](ctx, a, "authentication", req)
+}
+
+type CheckResponse = Response[CheckSuccessResponse]
+
+func (a *API) Check(ctx context.Context, req CheckRequest) (*CheckResponse, error) {
+	return do[CheckRequest, CheckSuccessResponse](ctx, a, "check", req)
+}
+
+type RetryResponse = Response[RetrySuccessResponse]
+
+func (a *API) Retry(ctx context.Context, req RetryRequest) (*RetryResponse, error) {
+	return do[RetryRequest, RetrySuccessResponse](ctx, a, "retry", req)
+}
+
+type StatusResponse = Response[AuthSuccessResponse]
+
+func (a *API) Status(ctx context.Context, req StatusRequest) (*StatusResponse, error) {
+	return do[StatusRequest, AuthSuccessResponse](ctx, a, "status", req)
+}
+
+// do posts req to the endpoint at path and decodes the response as a TRes, or
+// as an ErrorResponse for non-200 statuses other than 403.
+func do[TReq, TRes any](ctx context.Context, a *API, path string, req TReq) (*Response[TRes], error) {
+	res, err := a.post(ctx, path, req)
 	if err != nil {
-		return nil, ErrInternal
+		return nil, err
 	}
 	defer res.Body.Close()
 
+	receivedAt := time.Now()
+	requestID := res.Header.Get(RequestIDHeader)
+
 	if res.StatusCode != http.StatusOK {
 		a.leveledLogger.Errorf("received a non-200 HTTP status %d", res.StatusCode)
 
-		if res.StatusCode == http.StatusForbidden {
-			return nil, ErrUnauthorized
+		resp, err := a.decodeError(res)
+		if err != nil {
+			return nil, &ResponseError{RequestID: requestID, Err: err}
 		}
 
-		var resp ErrorResponse
-		if err := json.NewDecoder(res.Body).Decode(&resp); err != nil {
-			a.leveledLogger.Errorf("unable to decode response: %s", err)
-			return nil, ErrInternal
-		}
-
-		return &AuthenticationResponse{
-			Error: &resp,
+		return &Response[TRes]{
+			Error:     resp,
+			RequestID: requestID,
 		}, nil
 	}
 
-	var resp AuthSuccessResponse
-	if err := json.NewDecoder(res.Body).Decode(&resp); err != nil {
+	// The body is decoded once into its fields, which are then assigned to
+	// resp, so that the unknown ones can be kept in Raw.
+	var (
+		fields map[string]json.RawMessage
+		resp   TRes
+		raw    map[string]json.RawMessage
+	)
+
+	err = decodeResponse(res, a.maxBodySize, &fields)
+	if err == nil {
+		if raw, err = decodeFields(fields, &resp); err != nil {
+			err = &UnexpectedResponseError{
+				Reason: fmt.Sprintf("invalid JSON body with HTTP status %d: %v", res.StatusCode, err),
+			}
+		}
+	}
+
+	if err != nil {
 		a.leveledLogger.Errorf("unable to decode response of HTTP OK status: %s", err)
-		return nil, ErrInternal
+		return nil, &ResponseError{RequestID: requestID, Err: err}
 	}
 
-	return &AuthenticationResponse{
-		Success: &resp,
-	}, nil
+	offset, hasOffset := clockOffset(*res, receivedAt)
 
+	return &Response[TRes]{
+		Success:        &resp,
+		Raw:            raw,
+		RequestID:      requestID,
+		Deprecation:    res.Header.Get(DeprecationHeader),
+		Sunset:         res.Header.Get(SunsetHeader),
+		ClockOffset:    offset,
+		HasClockOffset: hasOffset,
+	}, nil
 }
 
-type CheckResponse struct {
-	Error   *ErrorResponse
-	Success *CheckSuccessResponse
+// decodeError decodes the body of an unsuccessful response.
+func (a *API) decodeError(res *transport.Response) (*ErrorResponse, error) {
+	if res.StatusCode == http.StatusForbidden {
+		return nil, ErrUnauthorized
+	}
+
+	var resp ErrorResponse
+	if err := decodeResponse(res, a.maxBodySize, &resp); err != nil {
+		a.leveledLogger.Errorf("unable to decode response: %s", err)
+		return nil, err
+	}
+
+	return &resp, nil
 }
 
-func (a *API) Check(ctx context.Context, req CheckRequest) (*CheckResponse, error) {
-	res, err := a.post(ctx, "check", req)
+// ----------------------------------------------------------------------------
+
+// Do sends a request to an arbitrary endpoint. payload is encoded as the JSON
+// body of the request unless nil, and a successful response is decoded into out
+// unless nil. Non-2XX responses other than 403 are decoded as the Error of the
+// returned Response, whose Success is always nil.
+func (a *API) Do(ctx context.Context, method string, path string, payload interface{}, out interface{}) (*Response[struct{}], error) {
+	res, err := a.send(ctx, method, strings.TrimPrefix(path, "/"), payload)
 	if err != nil {
-		return nil, ErrInternal
+		return nil, err
 	}
 	defer res.Body.Close()
 
-	if res.StatusCode != http.StatusOK {
-		a.leveledLogger.Errorf("received a non-200 HTTP status %d", res.StatusCode)
+	resp := &Response[struct{}]{RequestID: res.Header.Get(RequestIDHeader)}
 
-		if res.StatusCode == http.StatusForbidden {
-			return nil, ErrUnauthorized
-		}
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		a.leveledLogger.Errorf("received a non-2XX HTTP status %d", res.StatusCode)
 
-		var resp ErrorResponse
-		if err := json.NewDecoder(res.Body).Decode(&resp); err != nil {
-			a.leveledLogger.Errorf("unable to decode response: %s", err)
-			return nil, ErrInternal
+		resp.Error, err = a.decodeError(res)
+		if err != nil {
+			return nil, &ResponseError{RequestID: resp.RequestID, Err: err}
 		}
 
-		return &CheckResponse{
-			Error: &resp,
-		}, nil
+		return resp, nil
 	}
 
-	var resp CheckSuccessResponse
-	if err := json.NewDecoder(res.Body).Decode(&resp); err != nil {
-		a.leveledLogger.Errorf("unable to decode response of HTTP OK status: %s", err)
-		return nil, ErrInternal
+	if out == nil || res.StatusCode == http.StatusNoContent {
+		return resp, nil
 	}
 
-	return &CheckResponse{
-		Success: &resp,
-	}, nil
+	if err := decodeResponse(res, a.maxBodySize, out); err != nil {
+		a.leveledLogger.Errorf("unable to decode response of HTTP status %d: %s", res.StatusCode, err)
+		return nil, &ResponseError{RequestID: resp.RequestID, Err: err}
+	}
+
+	return resp, nil
 }
 
-type RetryResponse struct {
-	Error   *ErrorResponse
-	Success *RetrySuccessResponse
+// key returns the API key the next request is sent with.
+func (a *API) key() string {
+	if a.failover.isActive() {
+		return a.failover.key
+	}
+
+	if a.keys != nil {
+		return a.keys.next()
+	}
+
+	return a.apiKey
 }
 
-func (a *API) Retry(ctx context.Context, req RetryRequest) (*RetryResponse, error) {
-	res, err := a.post(ctx, "retry", req)
-	if err != nil {
-		return nil, ErrInternal
+func (a *API) post(ctx context.Context, url string, payload interface{}) (*transport.Response, error) {
+	return a.send(ctx, http.MethodPost, url, payload)
+}
+
+func (a *API) send(ctx context.Context, method string, url string, payload interface{}) (*transport.Response, error) {
+	req := transport.Request{
+		Method: method,
+		URL:    fmt.Sprintf("%s/%s", a.baseURL, url),
+		Header: a.header.Clone(),
 	}
-	defer res.Body.Close()
 
-	if res.StatusCode != http.StatusOK {
-		a.leveledLogger.Errorf("received a non-200 HTTP status %d", res.StatusCode)
+	if req.Header == nil {
+		req.Header = make(http.Header)
+	}
 
-		if res.StatusCode == http.StatusForbidden {
-			return nil, ErrUnauthorized
-		}
+	req.Header.Set(APIKeyHeader, a.key())
 
-		var resp ErrorResponse
-		if err := json.NewDecoder(res.Body).Decode(&resp); err != nil {
-			a.leveledLogger.Errorf("unable to decode response: %s", err)
+	if payload != nil {
+		b, err := json.Marshal(payload)
+		if err != nil {
+			a.leveledLogger.Errorf("marshal request payload %v: %v", payload, err)
 			return nil, ErrInternal
 		}
 
-		return &RetryResponse{
-			Error: &resp,
-		}, nil
-	}
+		if len(a.extra) > 0 {
+			if b, err = mergeExtra(b, payload, a.extra); err != nil {
+				a.leveledLogger.Errorf("merge extra fields into request payload: %v", err)
+				return nil, err
+			}
+		}
 
-	var resp RetrySuccessResponse
-	if err := json.NewDecoder(res.Body).Decode(&resp); err != nil {
-		a.leveledLogger.Errorf("unable to decode response of HTTP OK status: %s", err)
-		return nil, ErrInternal
+		req.Body = b
+		req.Header.Set("content-type", "application/json")
 	}
 
-	return &RetryResponse{
-		Success: &resp,
-	}, nil
-}
+	timeout := a.timeout
+	if d, ok := a.timeouts[url]; ok {
+		timeout = d
+	}
 
-// ----------------------------------------------------------------------------
+	cancel := context.CancelFunc(func() {})
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	}
 
-func (a *API) post(ctx context.Context, url string, payload interface{}) (*http.Response, error) {
-	body, err := json.Marshal(payload)
-	if err != nil {
-		a.leveledLogger.Errorf("marshal request payload %v: %v", payload, err)
-		return nil, ErrInternal
+	t := a.transport
+	if t == nil {
+		t = transport.HTTP{Client: a.hc}
 	}
 
-	req, err := http.NewRequestWithContext(
-		ctx,
-		http.MethodPost,
-		fmt.Sprintf("%s/%s", a.baseURL, url),
-		bytes.NewBuffer(body),
-	)
-	if err != nil {
-		a.leveledLogger.Errorf("create HTTP request %v", err)
-		return nil, ErrInternal
+	var tracer connTracer
+	if a.onRequest != nil {
+		ctx = tracer.withTrace(ctx)
 	}
 
-	req.Header.Set(APIKeyHeader, a.apiKey)
-	req.Header.Set("content-type", "application/json")
+	start := time.Now()
+
+	res, err := a.roundTrip(ctx, t, req)
+
+	if a.onRequest != nil {
+		stats := RequestStats{
+			Method:     method,
+			Path:       url,
+			StatusCode: res.StatusCode,
+			Duration:   time.Since(start),
+			Err:        err,
+			Conn:       tracer.conn(),
+		}
+
+		if perr := a.Protect("request hook", func() { a.onRequest(stats) }); perr != nil && err == nil {
+			discard(res)
+			err = perr
+		}
+	}
 
-	res, err := a.hc.Do(req)
 	if err != nil {
+		cancel()
+
+		var panicErr *PanicError
+		if errors.As(err, &panicErr) {
+			return nil, panicErr
+		}
+
 		a.leveledLogger.Errorf("perform HTTP request %v", err)
-		return nil, ErrInternal
+
+		if notSent(err) {
+			return nil, ErrNotSent
+		}
+
+		return nil, ErrUnreachable
+	}
+
+	if res.Body == nil {
+		res.Body = http.NoBody
+	}
+
+	if err := a.deprecated(method, url, res); err != nil {
+		cancel()
+		discard(res)
+		return nil, err
 	}
 
-	return res, nil
+	if a.onResponse != nil {
+		a.onResponse(req, res)
+	}
+
+	// The timeout also covers reading the body, so the context is only
+	// released once the body is closed.
+	res.Body = &cancelOnClose{ReadCloser: res.Body, cancel: cancel}
+
+	return &res, nil
+}
+
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	defer c.cancel()
+	return c.ReadCloser.Close()
 }
 
 // ----------------------------------------------------------------------------
@@ -298,27 +583,3 @@ type LeveledLogger interface {
 	Infof(format string, v ...interface{})
 	Warnf(format string, v ...interface{})
 }
-
-type loggerShim struct {
-	baseLogger LeveledLogger
-}
-
-func (l loggerShim) Error(msg string, keysAndValues ...interface{}) {
-	l.baseLogger.Errorf(fmt.Sprint(msg, keysAndValues))
-}
-
-func (l loggerShim) Info(msg string, keysAndValues ...interface{}) {
-	l.baseLogger.Infof(fmt.Sprint(msg, keysAndValues))
-}
-
-func (l loggerShim) Debug(msg string, keysAndValues ...interface{}) {
-	l.baseLogger.Debugf(fmt.Sprint(msg, keysAndValues))
-}
-
-func (l loggerShim) Warn(msg string, keysAndValues ...interface{}) {
-	l.baseLogger.Warnf(fmt.Sprint(msg, keysAndValues))
-}
-
-func convertLogger(logger LeveledLogger) retryablehttp.LeveledLogger {
-	return loggerShim{logger}
-}
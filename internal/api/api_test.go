@@ -2,12 +2,20 @@ package api
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net"
 	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/ding-live/ding-go/pkg/code"
 	"github.com/ding-live/ding-go/pkg/status"
+	"github.com/ding-live/ding-go/pkg/transport"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -56,6 +64,7 @@ func TestParseAuthSuccess(t *testing.T) {
 	require.NoError(t, err)
 
 	assert.Equal(t, &AuthenticationResponse{
+		HasClockOffset: true,
 		Success: &AuthSuccessResponse{
 			AuthenticationUUID: testUUID.String(),
 			Status:             status.AuthPending,
@@ -87,7 +96,7 @@ func TestParseError(t *testing.T) {
 
 	assert.Equal(t, &AuthenticationResponse{
 		Error: &ErrorResponse{
-			Code:    ErrorCodeInvalidPhoneNumber,
+			Code:    code.InvalidPhoneNumber,
 			Message: "+invalid is not a valid phone number",
 			DocURL:  "https://docs.example.com/api/error-handling#invalid_phone_number",
 		},
@@ -116,6 +125,7 @@ func TestParseCheckSuccess(t *testing.T) {
 	require.NoError(t, err)
 
 	assert.Equal(t, &CheckResponse{
+		HasClockOffset: true,
 		Success: &CheckSuccessResponse{
 			AuthenticationUUID: testUUID.String(),
 			Status:             status.CheckValid,
@@ -150,6 +160,7 @@ func TestParseRetrySuccess(t *testing.T) {
 	require.NoError(t, err)
 
 	assert.Equal(t, &RetryResponse{
+		HasClockOffset: true,
 		Success: &RetrySuccessResponse{
 			AuthenticationUUID: id.String(),
 			Status:             status.RetryExpiredAuth,
@@ -177,6 +188,46 @@ func TestParseInvalidResponse(t *testing.T) {
 	require.ErrorIs(t, authErr, ErrInternal)
 }
 
+func TestResponseTooLarge(t *testing.T) {
+	rawRes := fmt.Sprintf(`{"authentication_uuid": "%s", "padding": "%s"}`, uuid.New(), strings.Repeat("a", 1024))
+
+	ts := testServer(rawRes)
+
+	a, err := New(Config{
+		BaseURL:          ts.URL,
+		APIKey:           testApiKey,
+		CustomHTTPClient: ts.Client(),
+		LeveledLogger:    testLogger{},
+		MaxBodySize:      1024,
+	})
+	require.NoError(t, err)
+
+	_, authErr := a.Authentication(context.Background(), AuthRequest{})
+	require.ErrorIs(t, authErr, ErrInternal)
+
+	a.maxBodySize = 2048
+
+	res, authErr := a.Authentication(context.Background(), AuthRequest{})
+	require.NoError(t, authErr)
+	assert.NotNil(t, res.Success)
+}
+
+func TestUnreachable(t *testing.T) {
+	ts := testServer("")
+	ts.Close()
+
+	a, err := New(Config{
+		BaseURL:           ts.URL,
+		APIKey:            testApiKey,
+		MaxNetworkRetries: intPtr(0),
+		LeveledLogger:     testLogger{},
+	})
+	require.NoError(t, err)
+
+	_, authErr := a.Authentication(context.Background(), AuthRequest{})
+	require.ErrorIs(t, authErr, ErrUnreachable)
+}
+
 func TestUnknownAuthStatus(t *testing.T) {
 	testUUID := uuid.New()
 	testCreatedAt := time.Now().Add(-time.Minute).UTC()
@@ -203,20 +254,349 @@ func TestUnknownAuthStatus(t *testing.T) {
 	require.NoError(t, err)
 
 	assert.Equal(t, &AuthenticationResponse{
+		HasClockOffset: true,
 		Success: &AuthSuccessResponse{
 			AuthenticationUUID: testUUID.String(),
-			Status:             status.AuthUnknown,
+			Status:             status.Auth("--------------------------------"),
 			CreatedAt:          testCreatedAt,
 			ExpiresAt:          testExpiresAt,
 		},
 	}, res)
+	assert.False(t, res.Success.Status.Known())
+	assert.Equal(t, "--------------------------------", res.Success.Status.UnknownValue())
 }
 
 // ----------------------------------------------------------------------------
 
+func intPtr(i int) *int {
+	return &i
+}
+
 type testLogger struct{}
 
 func (testLogger) Debugf(string, ...interface{}) {}
 func (testLogger) Infof(string, ...interface{})  {}
 func (testLogger) Warnf(string, ...interface{})  {}
 func (testLogger) Errorf(string, ...interface{}) {}
+
+func TestRetry(t *testing.T) {
+	var (
+		calls      int
+		statusCode int
+	)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+
+		if calls < 3 {
+			w.Header().Set("retry-after", "0")
+			w.WriteHeader(statusCode)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	a, err := New(Config{
+		BaseURL:       ts.URL,
+		APIKey:        testApiKey,
+		LeveledLogger: testLogger{},
+		Backoff:       Backoff{Initial: time.Millisecond},
+	})
+	require.NoError(t, err)
+
+	statusCode = http.StatusServiceUnavailable
+
+	res, err := a.Do(context.Background(), http.MethodGet, "status", nil, nil)
+	require.NoError(t, err)
+	assert.Nil(t, res.Error)
+	assert.Equal(t, 3, calls)
+
+	// Requests that are not idempotent are retried when the API didn't
+	// process them, such as when rate limited.
+	calls = 0
+	statusCode = http.StatusTooManyRequests
+
+	_, err = a.Do(context.Background(), http.MethodPost, "authentication", AuthRequest{}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 3, calls)
+
+	// They are not retried after ambiguous failures.
+	calls = 0
+	statusCode = http.StatusInternalServerError
+
+	_, err = a.Do(context.Background(), http.MethodPost, "authentication", AuthRequest{}, nil)
+	require.ErrorIs(t, err, ErrInternal)
+	assert.Equal(t, 1, calls)
+
+	// Giving up after MaxNetworkRetries returns the last response.
+	calls = 0
+	statusCode = http.StatusServiceUnavailable
+	a.maxRetries = 1
+
+	_, err = a.Do(context.Background(), http.MethodGet, "status", nil, nil)
+
+	var unexpected *UnexpectedResponseError
+	require.ErrorAs(t, err, &unexpected)
+	assert.NotErrorIs(t, err, ErrUnreachable)
+	assert.Equal(t, 2, calls)
+}
+
+func TestRetryAfterCapped(t *testing.T) {
+	a := &API{backoff: Backoff{MaxInterval: time.Second}.withDefaults()}
+
+	res := transport.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}}
+	res.Header.Set("retry-after", "3600")
+	assert.Equal(t, time.Second, a.backoffDelay(0, res))
+
+	res.Header.Set("retry-after", "0")
+	assert.Equal(t, time.Duration(0), a.backoffDelay(0, res))
+}
+
+func TestRetryNotSent(t *testing.T) {
+	calls := 0
+
+	a, err := New(Config{
+		BaseURL:       "https://api.ding.live/v1",
+		APIKey:        testApiKey,
+		LeveledLogger: testLogger{},
+		Backoff:       Backoff{Initial: time.Millisecond},
+		Transport: transport.Func(func(ctx context.Context, req transport.Request) (transport.Response, error) {
+			calls++
+
+			if calls == 1 {
+				return transport.Response{}, &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+			}
+
+			return transport.Response{}, io.ErrUnexpectedEOF
+		}),
+	})
+	require.NoError(t, err)
+
+	// The POST request is retried once it failed to connect, but not once
+	// it may have been sent.
+	_, err = a.Do(context.Background(), http.MethodPost, "authentication", AuthRequest{}, nil)
+	require.ErrorIs(t, err, ErrUnreachable)
+	assert.Equal(t, 2, calls)
+}
+
+func TestBackoff(t *testing.T) {
+	b := Backoff{}.withDefaults()
+
+	assert.Equal(t, time.Second, b.delay(0))
+	assert.Equal(t, 4*time.Second, b.delay(2))
+	assert.Equal(t, 30*time.Second, b.delay(10))
+	assert.Equal(t, 30*time.Second, b.delay(10000))
+
+	b = Backoff{Initial: 100 * time.Millisecond, Multiplier: 1.5, MaxInterval: time.Second}.withDefaults()
+
+	assert.Equal(t, 150*time.Millisecond, b.delay(1))
+	assert.Equal(t, time.Second, b.delay(10))
+
+	a := &API{backoff: Backoff{Initial: time.Second, FullJitter: true}.withDefaults()}
+	for i := 0; i < 100; i++ {
+		wait := a.backoffDelay(1, transport.Response{})
+		assert.GreaterOrEqual(t, wait, time.Duration(0))
+		assert.LessOrEqual(t, wait, 2*time.Second)
+	}
+}
+
+func TestRetryMaxElapsedTime(t *testing.T) {
+	var calls int
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+
+	a, err := New(Config{
+		BaseURL:       ts.URL,
+		APIKey:        testApiKey,
+		LeveledLogger: testLogger{},
+		Backoff: Backoff{
+			Initial:        20 * time.Millisecond,
+			MaxElapsedTime: 50 * time.Millisecond,
+		},
+	})
+	require.NoError(t, err)
+
+	start := time.Now()
+
+	// A retry is made after 20ms, the next one would start after 60ms.
+	_, err = a.Do(context.Background(), http.MethodGet, "status", nil, nil)
+	require.ErrorIs(t, err, ErrInternal)
+	assert.Equal(t, 2, calls)
+	assert.Less(t, time.Since(start), 50*time.Millisecond)
+}
+
+func TestRetryBudget(t *testing.T) {
+	var calls int
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("retry-after", "0")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	a, err := New(Config{
+		BaseURL:       ts.URL,
+		APIKey:        testApiKey,
+		LeveledLogger: testLogger{},
+		RetryBudget:   RetryBudget{Ratio: 0.5, MinRetries: 2},
+	})
+	require.NoError(t, err)
+
+	// The two retries in reserve are spent by the first request.
+	_, err = a.Do(context.Background(), http.MethodGet, "status", nil, nil)
+	require.ErrorIs(t, err, ErrInternal)
+	assert.Equal(t, 3, calls)
+
+	// The budget is shared with the copies of the client, and every request
+	// only earns half a retry.
+	b := a.WithTimeout(time.Minute)
+
+	calls = 0
+
+	_, err = b.Do(context.Background(), http.MethodGet, "status", nil, nil)
+	require.ErrorIs(t, err, ErrInternal)
+	assert.Equal(t, 1, calls)
+
+	calls = 0
+
+	_, err = a.Do(context.Background(), http.MethodGet, "status", nil, nil)
+	require.ErrorIs(t, err, ErrInternal)
+	assert.Equal(t, 2, calls)
+
+	assert.Nil(t, newRetryBudget(RetryBudget{}))
+	assert.True(t, (*retryBudget)(nil).withdraw())
+}
+
+func TestRetryCancelled(t *testing.T) {
+	ts := testServer("", http.StatusInternalServerError)
+	defer ts.Close()
+
+	a, err := New(Config{
+		BaseURL:       ts.URL,
+		APIKey:        testApiKey,
+		LeveledLogger: testLogger{},
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+
+	_, err = a.Do(ctx, http.MethodGet, "status", nil, nil)
+	require.ErrorIs(t, err, ErrUnreachable)
+	assert.Less(t, time.Since(start), retryWaitMin)
+}
+
+func TestSigning(t *testing.T) {
+	const key = "signing_key_test"
+
+	// The fake API is an hour ahead, and rejects signatures that are more than
+	// a minute off.
+	skew := time.Hour
+
+	var calls int
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+
+		body, _ := io.ReadAll(r.Body)
+		sig := r.Header.Get(SignatureHeader)
+		now := time.Now().Add(skew)
+
+		w.Header().Set("Date", now.UTC().Format(http.TimeFormat))
+
+		var ts int64
+		_, _ = fmt.Sscanf(sig, "t=%d,", &ts)
+
+		if d := now.Sub(time.Unix(ts, 0)); sig != Sign(key, r.Method, r.URL.Path, body, time.Unix(ts, 0)) ||
+			d > time.Minute || d < -time.Minute {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	a, err := New(Config{
+		BaseURL:       ts.URL,
+		APIKey:        testApiKey,
+		LeveledLogger: testLogger{},
+		SigningKey:    key,
+	})
+	require.NoError(t, err)
+
+	// The first request is signed again with the clock of the API.
+	res, err := a.Do(context.Background(), http.MethodPost, "authentication", AuthRequest{}, nil)
+	require.NoError(t, err)
+	assert.Nil(t, res.Error)
+	assert.Equal(t, 2, calls)
+
+	// Later ones are signed with the clock of the API right away.
+	calls = 0
+
+	_, err = a.Do(context.Background(), http.MethodPost, "check", CheckRequest{CheckCode: "1234"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, calls)
+
+	// The clock of the API is followed as it changes.
+	calls = 0
+	skew = 2 * time.Hour
+
+	_, err = a.Do(context.Background(), http.MethodPost, "check", CheckRequest{}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls)
+
+	// Signatures rejected for other reasons are not sent again.
+	calls = 0
+	a.signingKey = "wrong_signing_key"
+
+	_, err = a.Do(context.Background(), http.MethodPost, "check", CheckRequest{}, nil)
+	require.Error(t, err)
+	assert.Equal(t, 1, calls)
+}
+
+func TestMergeExtra(t *testing.T) {
+	extra := map[string]interface{}{"template_id": "login"}
+
+	b, err := mergeExtra([]byte(`{"phone_number":"+33612345678"}`), map[string]string{}, extra)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"phone_number":"+33612345678","template_id":"login"}`, string(b))
+
+	// Fields of the payload are never overwritten, even when omitted.
+	_, err = mergeExtra([]byte(`{"template_id":"signup"}`), map[string]string{}, extra)
+	assert.IsType(t, &ExtraFieldError{}, err)
+
+	_, err = mergeExtra([]byte(`{}`), AuthRequest{}, map[string]interface{}{"IP": "192.168.0.1"})
+	assert.IsType(t, &ExtraFieldError{}, err)
+
+	_, err = mergeExtra([]byte(`[]`), []string{}, extra)
+	assert.Error(t, err)
+}
+
+func TestDecodeFields(t *testing.T) {
+	fields := map[string]json.RawMessage{
+		"authentication_uuid": json.RawMessage(`"e0e7b0e9-739d-424b-922f-1c2cb48ab077"`),
+		"STATUS":              json.RawMessage(`"valid"`),
+		"risk_score":          json.RawMessage(`0.2`),
+	}
+
+	var res CheckSuccessResponse
+
+	raw, err := decodeFields(fields, &res)
+	require.NoError(t, err)
+	assert.Equal(t, "e0e7b0e9-739d-424b-922f-1c2cb48ab077", res.AuthenticationUUID)
+	assert.Equal(t, status.CheckValid, res.Status)
+	assert.Equal(t, map[string]json.RawMessage{"risk_score": json.RawMessage(`0.2`)}, raw)
+
+	_, err = decodeFields(map[string]json.RawMessage{"status": json.RawMessage(`1`)}, &res)
+	assert.Error(t, err)
+}
@@ -0,0 +1,131 @@
+package api
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"strings"
+	"sync"
+
+	"github.com/ding-live/ding-go/pkg/transport"
+)
+
+// DefaultMaxBodySize is the default maximum size of the response bodies read
+// by the client.
+const DefaultMaxBodySize = 4 << 20
+
+var errBodyTooLarge = errors.New("response body too large")
+
+// readerPool holds the buffered readers used to decode response bodies, so
+// that high-throughput clients don't allocate one per response.
+var readerPool = sync.Pool{
+	New: func() interface{} {
+		return bufio.NewReaderSize(nil, 4<<10)
+	},
+}
+
+// UnexpectedResponseError is returned for responses whose body isn't the JSON
+// expected from the Ding API, such as the HTML error page of a proxy. It wraps
+// ErrInternal.
+type UnexpectedResponseError struct {
+	// Reason describes what was wrong with the response.
+	Reason string
+}
+
+func (e *UnexpectedResponseError) Error() string {
+	return "unexpected response: " + e.Reason
+}
+
+func (e *UnexpectedResponseError) Unwrap() error {
+	return ErrInternal
+}
+
+// decodeResponse checks that the body of res is JSON, decompresses it if
+// needed, and decodes it into v as by decode. Failures are reported as an
+// UnexpectedResponseError.
+func decodeResponse(res *transport.Response, limit int64, v interface{}) error {
+	if ct := res.Header.Get("Content-Type"); ct != "" {
+		mediaType, _, err := mime.ParseMediaType(ct)
+		if err != nil || mediaType != "application/json" && !strings.HasSuffix(mediaType, "+json") {
+			return &UnexpectedResponseError{
+				Reason: fmt.Sprintf("content type %q with HTTP status %d", ct, res.StatusCode),
+			}
+		}
+	}
+
+	body := res.Body
+
+	// The HTTP transport only decompresses bodies it asked to be compressed,
+	// and removes the header when it does.
+	if strings.EqualFold(res.Header.Get("Content-Encoding"), "gzip") {
+		gz, err := gzip.NewReader(body)
+		if err != nil {
+			return &UnexpectedResponseError{Reason: fmt.Sprintf("invalid gzip body: %v", err)}
+		}
+		defer gz.Close()
+
+		body = gz
+	}
+
+	if err := decode(body, limit, v); err != nil {
+		return &UnexpectedResponseError{
+			Reason: fmt.Sprintf("invalid JSON body with HTTP status %d: %v", res.StatusCode, err),
+		}
+	}
+
+	return nil
+}
+
+// decode streams the JSON body r into v. It fails with errBodyTooLarge as
+// soon as more than limit bytes are read.
+func decode(r io.Reader, limit int64, v interface{}) error {
+	br := readerPool.Get().(*bufio.Reader)
+	br.Reset(&limitedReader{r: r, n: limit})
+
+	defer func() {
+		br.Reset(nil)
+		readerPool.Put(br)
+	}()
+
+	if err := json.NewDecoder(br).Decode(v); err != nil {
+		return err
+	}
+
+	// Drain what is left of the body, usually a trailing newline, so that the
+	// connection can be reused.
+	_, _ = io.Copy(io.Discard, br)
+
+	return nil
+}
+
+// limitedReader is like io.LimitedReader but reports an error instead of EOF
+// when the limit is exceeded.
+type limitedReader struct {
+	r io.Reader
+	n int64
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.n <= 0 {
+		// Only fail if there actually is more data.
+		var b [1]byte
+		if n, _ := l.r.Read(b[:]); n > 0 {
+			return 0, errBodyTooLarge
+		}
+
+		return 0, io.EOF
+	}
+
+	if int64(len(p)) > l.n {
+		p = p[:l.n]
+	}
+
+	n, err := l.r.Read(p)
+	l.n -= int64(n)
+
+	return n, err
+}
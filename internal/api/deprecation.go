@@ -0,0 +1,70 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ding-live/ding-go/pkg/transport"
+)
+
+// SunsetHeader is the standard header of RFC 8594 giving the date after which
+// an endpoint may be removed.
+const SunsetHeader = "Sunset"
+
+// DeprecationNotice describes a response marking its endpoint as deprecated.
+type DeprecationNotice struct {
+	Method string
+	Path   string
+
+	// Since is the date of the deprecation, or zero if the API didn't give
+	// one.
+	Since time.Time
+
+	// Sunset is the date after which the endpoint may be removed, or zero if
+	// the API didn't give one.
+	Sunset time.Time
+}
+
+// deprecated logs and reports, through a.onDeprecation, that the endpoint of
+// res is deprecated. Each endpoint is only logged once, but reported for every
+// response. It returns a *PanicError if a.onDeprecation panics.
+func (a *API) deprecated(method, path string, res transport.Response) error {
+	deprecation, sunset := res.Header.Get(DeprecationHeader), res.Header.Get(SunsetHeader)
+	if deprecation == "" && sunset == "" {
+		return nil
+	}
+
+	n := DeprecationNotice{
+		Method: method,
+		Path:   path,
+		Since:  ParseDeprecation(deprecation),
+	}
+
+	n.Sunset, _ = http.ParseTime(sunset)
+
+	if _, logged := a.deprecations.LoadOrStore(method+" "+path, struct{}{}); !logged {
+		a.leveledLogger.Warnf("%s %s is deprecated (deprecation: %q, sunset: %q), upgrade the SDK before it is removed",
+			method, path, deprecation, sunset)
+	}
+
+	if a.onDeprecation == nil {
+		return nil
+	}
+
+	return a.Protect("deprecation hook", func() { a.onDeprecation(n) })
+}
+
+// ParseDeprecation parses the value of a DeprecationHeader, either a date as
+// defined by RFC 9745, such as "@1688169599", or an HTTP date as used by
+// earlier drafts. It returns zero for other values, such as "true".
+func ParseDeprecation(v string) time.Time {
+	if sec, err := strconv.ParseInt(strings.TrimPrefix(v, "@"), 10, 64); err == nil && strings.HasPrefix(v, "@") {
+		return time.Unix(sec, 0).UTC()
+	}
+
+	t, _ := http.ParseTime(v)
+
+	return t
+}
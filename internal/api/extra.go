@@ -0,0 +1,86 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ExtraFieldError is returned when an extra field would overwrite a field of
+// the request, or can't be encoded.
+type ExtraFieldError struct {
+	Field  string
+	Reason string
+}
+
+func (e *ExtraFieldError) Error() string {
+	return fmt.Sprintf("extra field %q: %s", e.Field, e.Reason)
+}
+
+// WithExtra returns a copy of a that adds the fields of extra to the JSON body
+// of its requests, for parameters of the API that the request types don't
+// model yet. The copy shares the underlying HTTP client with a.
+func (a *API) WithExtra(extra map[string]interface{}) *API {
+	b := *a
+	b.extra = extra
+
+	return &b
+}
+
+// CheckExtra returns an ExtraFieldError if a field of extra is a field of
+// payload, whatever its case, since encoding/json matches field names
+// case-insensitively.
+func CheckExtra(payload interface{}, extra map[string]interface{}) error {
+	var known map[string]int
+
+	if t := reflect.TypeOf(payload); t != nil {
+		if t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+
+		if t.Kind() == reflect.Struct {
+			known = fieldNames(t)
+		}
+	}
+
+	for name := range extra {
+		if name == "" {
+			return &ExtraFieldError{Field: name, Reason: "empty name"}
+		}
+
+		if _, ok := known[strings.ToLower(name)]; ok {
+			return &ExtraFieldError{Field: name, Reason: "already a field of the request"}
+		}
+	}
+
+	return nil
+}
+
+// mergeExtra returns body, the JSON object encoding payload, with the fields of
+// extra added. The fields of body are never overwritten.
+func mergeExtra(body []byte, payload interface{}, extra map[string]interface{}) ([]byte, error) {
+	if err := CheckExtra(payload, extra); err != nil {
+		return nil, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil || fields == nil {
+		return nil, fmt.Errorf("payload is not a JSON object")
+	}
+
+	for name, v := range extra {
+		if _, ok := fields[name]; ok {
+			return nil, &ExtraFieldError{Field: name, Reason: "already a field of the request"}
+		}
+
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, &ExtraFieldError{Field: name, Reason: err.Error()}
+		}
+
+		fields[name] = b
+	}
+
+	return json.Marshal(fields)
+}
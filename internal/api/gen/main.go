@@ -0,0 +1,329 @@
+// Command gen generates the request and response types of the api package from
+// the OpenAPI spec of the Ding API.
+//
+// It only supports the subset of OpenAPI that the spec uses: object schemas
+// with scalar, array and referenced properties, and string enums.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"sort"
+	"strings"
+)
+
+func main() {
+	spec := flag.String("spec", "openapi.json", "path of the OpenAPI spec")
+	out := flag.String("out", "types_gen.go", "path of the generated file")
+	pkg := flag.String("package", "api", "name of the generated package")
+	flag.Parse()
+
+	b, err := os.ReadFile(*spec)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var doc document
+	if err := json.Unmarshal(b, &doc); err != nil {
+		log.Fatalf("parse %s: %v", *spec, err)
+	}
+
+	src, err := generate(*pkg, *spec, doc)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := os.WriteFile(*out, src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+type document struct {
+	Components struct {
+		Schemas map[string]schema `json:"schemas"`
+	} `json:"components"`
+}
+
+type schema struct {
+	Ref         string     `json:"$ref"`
+	Type        string     `json:"type"`
+	Format      string     `json:"format"`
+	Description string     `json:"description"`
+	Nullable    bool       `json:"nullable"`
+	Required    []string   `json:"required"`
+	Properties  properties `json:"properties"`
+	Items       *schema    `json:"items"`
+	Enum        []string   `json:"enum"`
+	EnumNames   []string   `json:"x-enum-varnames"`
+	GoType      string     `json:"x-go-type"`
+	GoName      string     `json:"x-go-name"`
+}
+
+type property struct {
+	name   string
+	schema schema
+}
+
+// properties keeps the properties of a schema in the order of the spec, so
+// that the fields of the generated structs follow it.
+type properties []property
+
+func (p *properties) UnmarshalJSON(b []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(b))
+
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+
+		name, ok := tok.(string)
+		if !ok {
+			return fmt.Errorf("unexpected token %v", tok)
+		}
+
+		var s schema
+		if err := dec.Decode(&s); err != nil {
+			return fmt.Errorf("property %s: %w", name, err)
+		}
+
+		*p = append(*p, property{name: name, schema: s})
+	}
+
+	return nil
+}
+
+func generate(pkg, spec string, doc document) ([]byte, error) {
+	names := make([]string, 0, len(doc.Components.Schemas))
+	for name := range doc.Components.Schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var body bytes.Buffer
+	imports := map[string]bool{}
+
+	for _, name := range names {
+		s := doc.Components.Schemas[name]
+
+		if s.Description != "" {
+			writeComment(&body, s.Description)
+		}
+
+		switch {
+		case len(s.Enum) > 0:
+			if err := writeEnum(&body, name, s); err != nil {
+				return nil, err
+			}
+		case s.Type == "object":
+			if err := writeStruct(&body, name, s, imports); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("schema %s: unsupported type %q", name, s.Type)
+		}
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by gen -spec %s; DO NOT EDIT.\n\n", spec)
+	fmt.Fprintf(&buf, "package %s\n\n", pkg)
+
+	if len(imports) > 0 {
+		var std, other []string
+		for path := range imports {
+			if strings.Contains(path, ".") {
+				other = append(other, path)
+			} else {
+				std = append(std, path)
+			}
+		}
+		sort.Strings(std)
+		sort.Strings(other)
+
+		buf.WriteString("import (\n")
+		for _, path := range std {
+			fmt.Fprintf(&buf, "\t%q\n", path)
+		}
+		if len(std) > 0 && len(other) > 0 {
+			buf.WriteString("\n")
+		}
+		for _, path := range other {
+			fmt.Fprintf(&buf, "\t%q\n", path)
+		}
+		buf.WriteString(")\n\n")
+	}
+
+	buf.Write(body.Bytes())
+
+	return format.Source(buf.Bytes())
+}
+
+func writeComment(w *bytes.Buffer, text string) {
+	for _, line := range wrap(text, 77) {
+		fmt.Fprintf(w, "// %s\n", line)
+	}
+}
+
+func writeEnum(w *bytes.Buffer, name string, s schema) error {
+	if s.Type != "string" {
+		return fmt.Errorf("schema %s: unsupported enum type %q", name, s.Type)
+	}
+
+	if len(s.EnumNames) != len(s.Enum) {
+		return fmt.Errorf("schema %s: x-enum-varnames must name every value", name)
+	}
+
+	fmt.Fprintf(w, "type %s string\n\nconst (\n", name)
+	for i, v := range s.Enum {
+		fmt.Fprintf(w, "\t%s %s = %q\n", s.EnumNames[i], name, v)
+	}
+	w.WriteString(")\n\n")
+
+	return nil
+}
+
+func writeStruct(w *bytes.Buffer, name string, s schema, imports map[string]bool) error {
+	required := map[string]bool{}
+	for _, r := range s.Required {
+		required[r] = true
+	}
+
+	fmt.Fprintf(w, "type %s struct {\n", name)
+	for _, p := range s.Properties {
+		typ, err := goType(p.schema, imports)
+		if err != nil {
+			return fmt.Errorf("schema %s: property %s: %w", name, p.name, err)
+		}
+
+		if p.schema.Nullable && !strings.HasPrefix(typ, "[]") {
+			typ = "*" + typ
+		}
+
+		tag := p.name
+		if !required[p.name] {
+			tag += ",omitempty"
+		}
+
+		field := p.schema.GoName
+		if field == "" {
+			field = goName(p.name)
+		}
+
+		fmt.Fprintf(w, "\t%s %s `json:%q`\n", field, typ, tag)
+	}
+	w.WriteString("}\n\n")
+
+	return nil
+}
+
+// goType returns the Go type of the values of s, and records the imports it
+// needs.
+func goType(s schema, imports map[string]bool) (string, error) {
+	if s.Ref != "" {
+		return s.Ref[strings.LastIndex(s.Ref, "/")+1:], nil
+	}
+
+	if s.GoType != "" {
+		if i := strings.LastIndex(s.GoType, "."); i >= 0 {
+			path, ok := goTypePackages[s.GoType[:i]]
+			if !ok {
+				return "", fmt.Errorf("unknown package in x-go-type %q", s.GoType)
+			}
+			imports[path] = true
+		}
+		return s.GoType, nil
+	}
+
+	switch s.Type {
+	case "string":
+		if s.Format == "date-time" {
+			imports["time"] = true
+			return "time.Time", nil
+		}
+		return "string", nil
+	case "integer":
+		return "int", nil
+	case "number":
+		return "float64", nil
+	case "boolean":
+		return "bool", nil
+	case "array":
+		if s.Items == nil {
+			return "", fmt.Errorf("array without items")
+		}
+		item, err := goType(*s.Items, imports)
+		if err != nil {
+			return "", err
+		}
+		return "[]" + item, nil
+	}
+
+	return "", fmt.Errorf("unsupported type %q", s.Type)
+}
+
+// goTypePackages maps the package names allowed in x-go-type to their import
+// paths.
+var goTypePackages = map[string]string{
+	"code":   "github.com/ding-live/ding-go/pkg/code",
+	"status": "github.com/ding-live/ding-go/pkg/status",
+}
+
+// initialisms are the words that are written in upper case in Go names.
+var initialisms = map[string]bool{
+	"id":   true,
+	"ip":   true,
+	"os":   true,
+	"url":  true,
+	"uuid": true,
+}
+
+// goName converts a snake_case property name to an exported Go name.
+func goName(name string) string {
+	var b strings.Builder
+	for _, word := range strings.Split(name, "_") {
+		if word == "" {
+			continue
+		}
+
+		if initialisms[word] {
+			b.WriteString(strings.ToUpper(word))
+			continue
+		}
+
+		b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+
+	return b.String()
+}
+
+// wrap splits text into lines of at most width characters.
+func wrap(text string, width int) []string {
+	var lines []string
+	var line string
+	for _, word := range strings.Fields(text) {
+		if line != "" && len(line)+1+len(word) > width {
+			lines = append(lines, line)
+			line = ""
+		}
+
+		if line != "" {
+			line += " "
+		}
+		line += word
+	}
+
+	if line != "" {
+		lines = append(lines, line)
+	}
+
+	return lines
+}
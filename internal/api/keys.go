@@ -0,0 +1,96 @@
+package api
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// WeightedAPIKey is an API key and its share of the requests.
+type WeightedAPIKey struct {
+	Key    string
+	Weight int
+}
+
+// keyRing spreads requests across API keys in proportion to their weights,
+// with the smooth weighted round-robin of nginx, so that a key isn't sent
+// several requests in a row when others are available.
+type keyRing struct {
+	keys    []WeightedAPIKey
+	total   int
+	mu      sync.Mutex
+	current []int
+}
+
+// newKeyRing returns a ring of keys, or nil if there are none. Weights below 1
+// count as 1.
+func newKeyRing(keys []WeightedAPIKey) *keyRing {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	r := &keyRing{
+		keys:    make([]WeightedAPIKey, len(keys)),
+		current: make([]int, len(keys)),
+	}
+
+	for i, k := range keys {
+		if k.Weight < 1 {
+			k.Weight = 1
+		}
+
+		r.keys[i] = k
+		r.total += k.Weight
+	}
+
+	return r
+}
+
+// next returns the key the next request is sent with.
+func (r *keyRing) next() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	best := 0
+
+	for i, k := range r.keys {
+		r.current[i] += k.Weight
+
+		if r.current[i] > r.current[best] {
+			best = i
+		}
+	}
+
+	r.current[best] -= r.total
+
+	return r.keys[best].Key
+}
+
+// failover switches the requests of a client to a fallback API key once the
+// primary one is refused, such as when it was revoked during a rotation. It
+// never switches back.
+type failover struct {
+	key        string
+	onFailover func()
+
+	// active is 1 once requests are sent with key.
+	active int32
+}
+
+func newFailover(key string, onFailover func()) *failover {
+	if key == "" {
+		return nil
+	}
+
+	return &failover{key: key, onFailover: onFailover}
+}
+
+// activate switches to the fallback key, calling onFailover the first time.
+func (f *failover) activate() {
+	if atomic.CompareAndSwapInt32(&f.active, 0, 1) && f.onFailover != nil {
+		f.onFailover()
+	}
+}
+
+func (f *failover) isActive() bool {
+	return f != nil && atomic.LoadInt32(&f.active) == 1
+}
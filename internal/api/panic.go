@@ -0,0 +1,44 @@
+package api
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+// PanicError is returned in place of a panic of a function supplied by the
+// user of the client, such as a hook or a transport, so that it doesn't crash
+// the goroutine of the call.
+type PanicError struct {
+	// Func describes the function that panicked, such as "transport".
+	Func  string
+	Value interface{}
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Func, e.Value)
+}
+
+// protect calls fn, described by name, and returns a *PanicError if it panics.
+func protect(name string, fn func()) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{Func: name, Value: r, Stack: debug.Stack()}
+		}
+	}()
+
+	fn()
+
+	return nil
+}
+
+// Protect calls fn, described by name, and returns a *PanicError if it panics,
+// after logging the panic.
+func (a *API) Protect(name string, fn func()) error {
+	err := protect(name, fn)
+	if err != nil {
+		a.leveledLogger.Errorf("recovered from panic in %v\n%s", err, err.(*PanicError).Stack)
+	}
+
+	return err
+}
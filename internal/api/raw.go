@@ -0,0 +1,72 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// knownFields caches the JSON field names of the response types, lower-cased
+// since encoding/json matches them case-insensitively, along with the index of
+// their field.
+var knownFields sync.Map // map[reflect.Type]map[string]int
+
+// decodeFields assigns the top-level fields of a JSON object, decoded once into
+// fields, to the fields of v, a pointer to a struct, matching their names
+// case-insensitively like encoding/json. It returns the fields that don't map
+// to a field of v, or nil if there are none, reusing fields.
+func decodeFields(fields map[string]json.RawMessage, v interface{}) (map[string]json.RawMessage, error) {
+	rv := reflect.ValueOf(v).Elem()
+	known := fieldNames(rv.Type())
+
+	for name, raw := range fields {
+		i, ok := known[strings.ToLower(name)]
+		if !ok {
+			continue
+		}
+
+		if err := json.Unmarshal(raw, rv.Field(i).Addr().Interface()); err != nil {
+			return nil, fmt.Errorf("field %q: %w", name, err)
+		}
+
+		delete(fields, name)
+	}
+
+	if len(fields) == 0 {
+		return nil, nil
+	}
+
+	return fields, nil
+}
+
+func fieldNames(t reflect.Type) map[string]int {
+	if names, ok := knownFields.Load(t); ok {
+		return names.(map[string]int)
+	}
+
+	names := make(map[string]int, t.NumField())
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+
+		names[strings.ToLower(name)] = i
+	}
+
+	knownFields.Store(t, names)
+
+	return names
+}
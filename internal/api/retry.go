@@ -0,0 +1,370 @@
+package api
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ding-live/ding-go/pkg/transport"
+)
+
+const (
+	defaultMaxRetries = 3
+	retryWaitMin      = time.Second
+	retryWaitMax      = 30 * time.Second
+	retryMultiplier   = 2
+)
+
+// Backoff sets the delays between the attempts of a request. Zero fields take
+// their default values.
+type Backoff struct {
+	Initial        time.Duration
+	Multiplier     float64
+	MaxInterval    time.Duration
+	MaxElapsedTime time.Duration
+	FullJitter     bool
+}
+
+func (b Backoff) withDefaults() Backoff {
+	if b.Initial <= 0 {
+		b.Initial = retryWaitMin
+	}
+
+	if b.Multiplier < 1 {
+		b.Multiplier = retryMultiplier
+	}
+
+	if b.MaxInterval <= 0 {
+		b.MaxInterval = retryWaitMax
+	}
+
+	return b
+}
+
+// delay returns the delay before the attempt following attempt, without
+// jitter.
+func (b Backoff) delay(attempt int) time.Duration {
+	d := float64(b.Initial) * math.Pow(b.Multiplier, float64(attempt))
+	if d > float64(b.MaxInterval) || math.IsInf(d, 0) {
+		return b.MaxInterval
+	}
+
+	return time.Duration(d)
+}
+
+// RetryBudget caps the retries of a client relative to its number of
+// requests. A zero Ratio disables the budget.
+type RetryBudget struct {
+	Ratio      float64
+	MinRetries int
+}
+
+const defaultMinRetries = 10
+
+// retryBudget is a token bucket shared by every request of a client: requests
+// deposit Ratio tokens and retries withdraw one, so that retries can't exceed
+// Ratio times the requests once the MinRetries initial tokens are spent.
+type retryBudget struct {
+	ratio    float64
+	capacity float64
+
+	mu      sync.Mutex
+	balance float64
+}
+
+// newRetryBudget returns the bucket of b, or nil if b is disabled.
+func newRetryBudget(b RetryBudget) *retryBudget {
+	if b.Ratio <= 0 {
+		return nil
+	}
+
+	if b.MinRetries <= 0 {
+		b.MinRetries = defaultMinRetries
+	}
+
+	return &retryBudget{
+		ratio:    b.Ratio,
+		capacity: float64(b.MinRetries),
+		balance:  float64(b.MinRetries),
+	}
+}
+
+// deposit records a new request. A nil budget does nothing.
+func (b *retryBudget) deposit() {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.balance = math.Min(b.balance+b.ratio, b.capacity)
+}
+
+// withdraw reports whether a retry is allowed, and records it if so. A nil
+// budget allows every retry.
+func (b *retryBudget) withdraw() bool {
+	if b == nil {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.balance < 1 {
+		return false
+	}
+
+	b.balance--
+
+	return true
+}
+
+// roundTrip sends req through t, retrying up to a.maxRetries times on network
+// errors, rate limits and server errors. Only requests that are safe to send
+// twice are retried: requests with an idempotent method, and others, such as
+// POST, when the API didn't process them. When retries run out after a
+// response was received, that response is returned.
+func (a *API) roundTrip(ctx context.Context, t transport.Transport, req transport.Request) (transport.Response, error) {
+	resigned, failedOver := false, false
+	start := time.Now()
+
+	a.budget.deposit()
+
+	for attempt := 0; ; attempt++ {
+		// Every attempt is signed anew, so that retries carry a fresh
+		// timestamp.
+		if a.signingKey != "" {
+			a.sign(req)
+		}
+
+		var (
+			res transport.Response
+			err error
+		)
+
+		if perr := a.Protect("transport", func() { res, err = t.Do(ctx, req) }); perr != nil {
+			return transport.Response{}, perr
+		}
+
+		// The signature may have been rejected because the local clock is
+		// off, in which case it is sent again once with the clock of the API.
+		if err == nil && a.signingKey != "" && a.clock.observe(res, time.Now()) &&
+			(res.StatusCode == http.StatusUnauthorized || res.StatusCode == http.StatusForbidden) && !resigned {
+			resigned = true
+			discard(res)
+			attempt--
+
+			continue
+		}
+
+		// A refused key is replaced by the fallback one, if any, and the
+		// request sent again with it.
+		if err == nil && res.StatusCode == http.StatusForbidden && a.failover != nil &&
+			req.Header.Get(APIKeyHeader) != a.failover.key && !failedOver {
+			failedOver = true
+			discard(res)
+			attempt--
+
+			a.leveledLogger.Warnf("API key refused, switching to the fallback key")
+			req.Header.Set(APIKeyHeader, a.failover.key)
+
+			if perr := a.Protect("failover hook", a.failover.activate); perr != nil {
+				return transport.Response{}, perr
+			}
+
+			continue
+		}
+
+		retry, perr := a.shouldRetry(ctx, req, res, err, attempt+1)
+		if perr != nil {
+			if err == nil {
+				discard(res)
+			}
+
+			return transport.Response{}, perr
+		}
+
+		if !retry {
+			return res, err
+		}
+
+		wait := a.backoffDelay(attempt, res)
+
+		// Give up rather than retry past the maximum elapsed time, as the
+		// attempt would be made too late to be useful anyway.
+		elapsed := a.backoff.MaxElapsedTime > 0 && time.Since(start)+wait > a.backoff.MaxElapsedTime
+		exhausted := attempt >= a.maxRetries || elapsed
+
+		// The budget is only spent by retries that would otherwise be made.
+		if !exhausted && !a.budget.withdraw() {
+			a.leveledLogger.Warnf("retry budget exhausted, not retrying %s %s", req.Method, req.URL)
+			exhausted = true
+		}
+
+		if exhausted {
+			// The last response is returned as is, so that its error is
+			// reported rather than the API looking unreachable.
+			if err == nil {
+				return res, nil
+			}
+
+			return transport.Response{}, fmt.Errorf("giving up after %d attempt(s): %w", attempt+1, err)
+		}
+
+		if err != nil {
+			a.leveledLogger.Debugf("%s %s failed, retrying in %s: %v", req.Method, req.URL, wait, err)
+		} else {
+			discard(res)
+			a.leveledLogger.Debugf("%s %s received HTTP status %d, retrying in %s", req.Method, req.URL, res.StatusCode, wait)
+		}
+
+		timer := time.NewTimer(wait)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return transport.Response{}, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// ShouldRetryFunc reports whether the attempt-th attempt of req, which
+// returned res or failed with err, should be retried.
+type ShouldRetryFunc func(req transport.Request, res transport.Response, err error, attempt int) bool
+
+// shouldRetry reports whether to retry the attempt, or returns a *PanicError
+// if a.retryPredicate panicked.
+func (a *API) shouldRetry(ctx context.Context, req transport.Request, res transport.Response, err error, attempt int) (retry bool, perr error) {
+	if ctx.Err() != nil || !isIdempotent(req) && !notProcessed(res, err) {
+		return false, nil
+	}
+
+	if a.retryPredicate != nil {
+		perr = a.Protect("retry predicate", func() { retry = a.retryPredicate(req, res, err, attempt) })
+		return retry, perr
+	}
+
+	return IsRetryable(res.StatusCode, err), nil
+}
+
+// IsRetryable reports whether a request that received a response with
+// statusCode, or failed with err, may succeed if sent again.
+func IsRetryable(statusCode int, err error) bool {
+	if err != nil {
+		return isRetryableError(err)
+	}
+
+	return statusCode == http.StatusTooManyRequests ||
+		(statusCode >= 500 && statusCode != http.StatusNotImplemented)
+}
+
+func isIdempotent(req transport.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// notProcessed reports whether the API can't have processed a request that
+// received res or failed with err: rate limited requests, requests refused by
+// a gateway asking to come back later, and requests that were never sent.
+// Timeouts, dropped connections and other server errors are ambiguous, as the
+// API may have processed the request before failing.
+func notProcessed(res transport.Response, err error) bool {
+	if err != nil {
+		return notSent(err)
+	}
+
+	return res.StatusCode == http.StatusTooManyRequests ||
+		res.StatusCode == http.StatusServiceUnavailable && res.Header.Get("retry-after") != ""
+}
+
+// notSent reports whether err happened before the request was sent, such as
+// when the host can't be resolved or the connection is refused, in which case
+// the API can't have processed it.
+func notSent(err error) bool {
+	var (
+		dnsErr *net.DNSError
+		opErr  *net.OpError
+	)
+
+	switch {
+	case errors.As(err, &dnsErr):
+		return true
+	case errors.As(err, &opErr):
+		return opErr.Op == "dial"
+	}
+
+	return false
+}
+
+// isRetryableError reports whether err may go away by itself, which excludes
+// invalid URLs and certificates.
+func isRetryableError(err error) bool {
+	var (
+		urlErr      *url.Error
+		unknownAuth x509.UnknownAuthorityError
+		invalidCert x509.CertificateInvalidError
+		hostnameErr x509.HostnameError
+	)
+
+	switch {
+	case errors.As(err, &unknownAuth), errors.As(err, &invalidCert), errors.As(err, &hostnameErr):
+		return false
+	case errors.As(err, &urlErr) && urlErr.Op == "parse":
+		return false
+	}
+
+	return true
+}
+
+// backoffDelay returns the delay before the attempt following attempt,
+// honoring the Retry-After header of rate limited and unavailable responses up
+// to the maximum interval of the backoff.
+func (a *API) backoffDelay(attempt int, res transport.Response) time.Duration {
+	if res.StatusCode == http.StatusTooManyRequests || res.StatusCode == http.StatusServiceUnavailable {
+		if sec, err := strconv.ParseInt(res.Header.Get("retry-after"), 10, 64); err == nil && sec >= 0 {
+			// A large Retry-After would block the call for as long.
+			if sec >= int64(a.backoff.MaxInterval/time.Second) {
+				return a.backoff.MaxInterval
+			}
+
+			return time.Duration(sec) * time.Second
+		}
+	}
+
+	wait := a.backoff.delay(attempt)
+
+	// Full jitter spreads the retries of clients that failed at the same
+	// time, such as after an outage of the API.
+	if a.backoff.FullJitter && wait > 0 {
+		wait = time.Duration(rand.Int63n(int64(wait) + 1))
+	}
+
+	return wait
+}
+
+// discard drains and closes the body of a response that won't be used, so
+// that its connection can be reused.
+func discard(res transport.Response) {
+	if res.Body == nil {
+		return
+	}
+
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 4<<10))
+	res.Body.Close()
+}
@@ -30,6 +30,9 @@ func emulateResponse(w http.ResponseWriter, r *http.Request, rawResponse string)
 
 func testServer(res string, status ...int) *httptest.Server {
 	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Headers must be set before the status is written.
+		w.Header().Set("content-type", "application/json")
+
 		if len(status) > 0 {
 			w.WriteHeader(status[0])
 		}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// RequestStats describes a request sent by the API client.
+type RequestStats struct {
+	Method     string
+	Path       string
+	StatusCode int
+	Duration   time.Duration
+	Err        error
+	Conn       ConnStats
+}
+
+// ConnStats describes the connection used by the last attempt of a request.
+type ConnStats struct {
+	Reused   bool
+	WasIdle  bool
+	IdleTime time.Duration
+	DNS      time.Duration
+	Connect  time.Duration
+	TLS      time.Duration
+}
+
+// connTracer records ConnStats through httptrace.
+type connTracer struct {
+	mu    sync.Mutex
+	stats ConnStats
+
+	dnsStart     time.Time
+	connectStart time.Time
+	tlsStart     time.Time
+}
+
+func (t *connTracer) withTrace(ctx context.Context) context.Context {
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			t.mu.Lock()
+			t.dnsStart = time.Now()
+			t.mu.Unlock()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			t.mu.Lock()
+			t.stats.DNS = time.Since(t.dnsStart)
+			t.mu.Unlock()
+		},
+		ConnectStart: func(string, string) {
+			t.mu.Lock()
+			t.connectStart = time.Now()
+			t.mu.Unlock()
+		},
+		ConnectDone: func(string, string, error) {
+			t.mu.Lock()
+			t.stats.Connect = time.Since(t.connectStart)
+			t.mu.Unlock()
+		},
+		TLSHandshakeStart: func() {
+			t.mu.Lock()
+			t.tlsStart = time.Now()
+			t.mu.Unlock()
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			t.mu.Lock()
+			t.stats.TLS = time.Since(t.tlsStart)
+			t.mu.Unlock()
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.mu.Lock()
+			t.stats.Reused = info.Reused
+			t.stats.WasIdle = info.WasIdle
+			t.stats.IdleTime = info.IdleTime
+			t.mu.Unlock()
+		},
+	})
+}
+
+func (t *connTracer) conn() ConnStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.stats
+}
@@ -0,0 +1,75 @@
+// Code generated by gen -spec openapi.json; DO NOT EDIT.
+
+package api
+
+import (
+	"time"
+
+	"github.com/ding-live/ding-go/pkg/code"
+	"github.com/ding-live/ding-go/pkg/status"
+)
+
+type AuthRequest struct {
+	PhoneNumber     string   `json:"phone_number,omitempty"`
+	CustomerUUID    string   `json:"customer_uuid,omitempty"`
+	IP              *string  `json:"ip,omitempty"`
+	DeviceID        *string  `json:"device_id,omitempty"`
+	DeviceType      *string  `json:"device_type,omitempty"`
+	AppVersion      *string  `json:"app_version,omitempty"`
+	OSVersion       *string  `json:"os_version,omitempty"`
+	DeviceModel     *string  `json:"device_model,omitempty"`
+	UserAgent       *string  `json:"user_agent,omitempty"`
+	CallbackURL     *string  `json:"callback_url,omitempty"`
+	IsReturningUser *bool    `json:"is_returning_user,omitempty"`
+	Channels        []string `json:"channels,omitempty"`
+}
+
+type AuthSuccessResponse struct {
+	AuthenticationUUID string      `json:"authentication_uuid"`
+	Status             status.Auth `json:"status"`
+	CreatedAt          time.Time   `json:"created_at"`
+	ExpiresAt          time.Time   `json:"expires_at"`
+	NextRetryAt        time.Time   `json:"next_retry_at"`
+	Channel            string      `json:"channel,omitempty"`
+}
+
+type CheckRequest struct {
+	CustomerUUID       string `json:"customer_uuid"`
+	AuthenticationUUID string `json:"authentication_uuid"`
+	CheckCode          string `json:"check_code"`
+}
+
+type CheckSuccessResponse struct {
+	AuthenticationUUID string       `json:"authentication_uuid"`
+	Status             status.Check `json:"status"`
+}
+
+type ErrorResponse struct {
+	Code    code.Code `json:"code"`
+	Message string    `json:"message"`
+	DocURL  string    `json:"doc_url"`
+}
+
+// GatewayErrorMessage is the body of the responses of the API gateway, such as
+// when the API key is invalid.
+type GatewayErrorMessage struct {
+	Message string `json:"message"`
+}
+
+type RetryRequest struct {
+	CustomerUUID       string `json:"customer_uuid"`
+	AuthenticationUUID string `json:"authentication_uuid"`
+}
+
+type RetrySuccessResponse struct {
+	AuthenticationUUID string       `json:"authentication_uuid"`
+	Status             status.Retry `json:"status"`
+	CreatedAt          time.Time    `json:"created_at"`
+	NextRetryAt        time.Time    `json:"next_retry_at"`
+	RemainingRetry     int          `json:"remaining_retry"`
+}
+
+type StatusRequest struct {
+	CustomerUUID       string `json:"customer_uuid"`
+	AuthenticationUUID string `json:"authentication_uuid"`
+}
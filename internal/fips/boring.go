@@ -0,0 +1,10 @@
+//go:build boringcrypto
+
+package fips
+
+import "crypto/boring"
+
+// Enabled reports whether FIPS 140 mode is enabled.
+func Enabled() bool {
+	return boring.Enabled()
+}
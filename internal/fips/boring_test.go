@@ -0,0 +1,11 @@
+//go:build boringcrypto
+
+package fips
+
+import "testing"
+
+func TestEnabledBoringCrypto(t *testing.T) {
+	if !Enabled() {
+		t.Fatal("FIPS mode should be enabled with GOEXPERIMENT=boringcrypto")
+	}
+}
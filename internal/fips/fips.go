@@ -0,0 +1,11 @@
+// Package fips reports whether the SDK runs with a FIPS 140 validated
+// cryptographic module, either the Go Cryptographic Module enabled with
+// GODEBUG=fips140=on or the BoringCrypto module of GOEXPERIMENT=boringcrypto.
+//
+// Every primitive used by the SDK is approved: HMAC-SHA256 for signatures and
+// SHA-256 for hashing. FIPS mode only restricts the keys they accept.
+package fips
+
+// MinKeySize is the minimum size in bytes of the HMAC keys accepted in FIPS
+// mode, which requires keys of at least 112 bits.
+const MinKeySize = 14
@@ -0,0 +1,10 @@
+//go:build go1.24 && !boringcrypto
+
+package fips
+
+import "crypto/fips140"
+
+// Enabled reports whether FIPS 140 mode is enabled.
+func Enabled() bool {
+	return fips140.Enabled()
+}
@@ -0,0 +1,9 @@
+//go:build !go1.24 && !boringcrypto
+
+package fips
+
+// Enabled reports whether FIPS 140 mode is enabled, which requires Go 1.24 or
+// GOEXPERIMENT=boringcrypto.
+func Enabled() bool {
+	return false
+}
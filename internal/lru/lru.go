@@ -0,0 +1,98 @@
+// Package lru implements a fixed-size least recently used cache whose entries
+// expire after a given time.
+package lru
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Cache is an LRU cache safe for concurrent use.
+type Cache[K comparable, V any] struct {
+	size int
+	ttl  time.Duration
+	now  func() time.Time
+
+	mu    sync.Mutex
+	ll    *list.List
+	items map[K]*list.Element
+}
+
+type entry[K comparable, V any] struct {
+	key       K
+	value     V
+	expiresAt time.Time
+}
+
+// New returns a cache holding at most size entries, each for at most ttl. A
+// zero ttl means entries never expire.
+func New[K comparable, V any](size int, ttl time.Duration) *Cache[K, V] {
+	return &Cache[K, V]{
+		size:  size,
+		ttl:   ttl,
+		now:   time.Now,
+		ll:    list.New(),
+		items: make(map[K]*list.Element),
+	}
+}
+
+// Get returns the value of key, if it is in the cache and not expired.
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var zero V
+
+	el, ok := c.items[key]
+	if !ok {
+		return zero, false
+	}
+
+	e := el.Value.(*entry[K, V])
+	if c.ttl > 0 && !c.now().Before(e.expiresAt) {
+		c.remove(el)
+		return zero, false
+	}
+
+	c.ll.MoveToFront(el)
+
+	return e.value, true
+}
+
+// Add sets the value of key, evicting the least recently used entry if the
+// cache is full.
+func (c *Cache[K, V]) Add(key K, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e := &entry[K, V]{key: key, value: value}
+	if c.ttl > 0 {
+		e.expiresAt = c.now().Add(c.ttl)
+	}
+
+	if el, ok := c.items[key]; ok {
+		el.Value = e
+		c.ll.MoveToFront(el)
+		return
+	}
+
+	c.items[key] = c.ll.PushFront(e)
+
+	if c.ll.Len() > c.size {
+		c.remove(c.ll.Back())
+	}
+}
+
+// Len returns the number of entries in the cache, expired ones included.
+func (c *Cache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.ll.Len()
+}
+
+func (c *Cache[K, V]) remove(el *list.Element) {
+	c.ll.Remove(el)
+	delete(c.items, el.Value.(*entry[K, V]).key)
+}
@@ -0,0 +1,51 @@
+package lru
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache(t *testing.T) {
+	c := New[string, int](2, 0)
+
+	c.Add("a", 1)
+	c.Add("b", 2)
+
+	// a becomes the most recently used entry, so b is evicted.
+	v, ok := c.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, v)
+
+	c.Add("c", 3)
+
+	_, ok = c.Get("b")
+	assert.False(t, ok)
+	assert.Equal(t, 2, c.Len())
+
+	c.Add("a", 4)
+
+	v, _ = c.Get("a")
+	assert.Equal(t, 4, v)
+}
+
+func TestCacheTTL(t *testing.T) {
+	now := time.Now()
+
+	c := New[string, int](2, time.Minute)
+	c.now = func() time.Time { return now }
+
+	c.Add("a", 1)
+
+	now = now.Add(59 * time.Second)
+
+	_, ok := c.Get("a")
+	assert.True(t, ok)
+
+	now = now.Add(time.Second)
+
+	_, ok = c.Get("a")
+	assert.False(t, ok)
+	assert.Equal(t, 0, c.Len())
+}
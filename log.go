@@ -52,31 +52,92 @@ type Logger struct {
 	Level Level
 }
 
+// Enabled reports whether messages of the given level are emitted. Messages
+// of disabled levels are dropped before being formatted, so that they cost
+// nothing in tight loops.
+func (l *Logger) Enabled(level Level) bool {
+	return l.Level != LevelNull && l.Level <= level
+}
+
 // Debugf logs a debug message using Printf conventions.
 func (l *Logger) Debugf(format string, v ...interface{}) {
-	if l.Level >= LevelDebug {
+	if l.Enabled(LevelDebug) {
 		fmt.Fprintf(os.Stdout, "[DEBUG] "+format+"\n", v...)
 	}
 }
 
-// Errorf logs a warning message using Printf conventions.
+// Errorf logs an error message using Printf conventions.
 func (l *Logger) Errorf(format string, v ...interface{}) {
-	// Infof logs a debug message using Printf conventions.
-	if l.Level >= LevelError {
+	if l.Enabled(LevelError) {
 		fmt.Fprintf(os.Stderr, "[ERROR] "+format+"\n", v...)
 	}
 }
 
 // Infof logs an informational message using Printf conventions.
 func (l *Logger) Infof(format string, v ...interface{}) {
-	if l.Level >= LevelInfo {
+	if l.Enabled(LevelInfo) {
 		fmt.Fprintf(os.Stdout, "[INFO] "+format+"\n", v...)
 	}
 }
 
 // Warnf logs a warning message using Printf conventions.
 func (l *Logger) Warnf(format string, v ...interface{}) {
-	if l.Level >= LevelWarn {
+	if l.Enabled(LevelWarn) {
 		fmt.Fprintf(os.Stderr, "[WARN] "+format+"\n", v...)
 	}
 }
+
+// safeLogger recovers from the panics of a logger supplied by the user of the
+// client, so that a faulty logger can't crash calls. Since the logger can't
+// be trusted to report its own panics, they are written to os.Stderr.
+type safeLogger struct {
+	next LeveledLogger
+}
+
+// newSafeLogger returns logger wrapped in a safeLogger, or nil if logger is
+// nil.
+func newSafeLogger(logger LeveledLogger) LeveledLogger {
+	if logger == nil {
+		return nil
+	}
+
+	return safeLogger{next: logger}
+}
+
+// Enabled reports whether next emits messages of level, so that the
+// redactingLogger wrapping the safeLogger can skip formatting them.
+func (l safeLogger) Enabled(level Level) (enabled bool) {
+	defer l.recover()
+
+	if e, ok := l.next.(interface{ Enabled(Level) bool }); ok {
+		return e.Enabled(level)
+	}
+
+	return true
+}
+
+func (l safeLogger) Debugf(format string, v ...interface{}) {
+	defer l.recover()
+	l.next.Debugf(format, v...)
+}
+
+func (l safeLogger) Errorf(format string, v ...interface{}) {
+	defer l.recover()
+	l.next.Errorf(format, v...)
+}
+
+func (l safeLogger) Infof(format string, v ...interface{}) {
+	defer l.recover()
+	l.next.Infof(format, v...)
+}
+
+func (l safeLogger) Warnf(format string, v ...interface{}) {
+	defer l.recover()
+	l.next.Warnf(format, v...)
+}
+
+func (l safeLogger) recover() {
+	if r := recover(); r != nil {
+		fmt.Fprintf(os.Stderr, "[ERROR] ding: recovered from panic in logger: %v\n", r)
+	}
+}
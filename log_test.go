@@ -0,0 +1,19 @@
+package ding
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoggerEnabled(t *testing.T) {
+	l := &Logger{Level: LevelWarn}
+
+	assert.False(t, l.Enabled(LevelDebug))
+	assert.False(t, l.Enabled(LevelInfo))
+	assert.True(t, l.Enabled(LevelWarn))
+	assert.True(t, l.Enabled(LevelError))
+
+	l.Level = LevelNull
+	assert.False(t, l.Enabled(LevelError))
+}
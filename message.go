@@ -0,0 +1,141 @@
+package ding
+
+import (
+	"errors"
+	"strings"
+)
+
+// MessageKey identifies a message that can be shown to the end users of your
+// app in place of an error of the client.
+type MessageKey string
+
+const (
+	// MessageInvalidPhoneNumber is shown for ErrInvalidPhoneNumber.
+	MessageInvalidPhoneNumber MessageKey = "invalid_phone_number"
+
+	// MessageVOIPNumber is shown for ErrVOIPNumber.
+	MessageVOIPNumber MessageKey = "voip_number"
+
+	// MessageUnsupportedRegion is shown for ErrUnsupportedRegion.
+	MessageUnsupportedRegion MessageKey = "unsupported_region"
+
+	// MessageOptedOut is shown for ErrOptedOut.
+	MessageOptedOut MessageKey = "opted_out"
+
+	// MessageDuplicateRequest is shown for ErrDuplicateRequest.
+	MessageDuplicateRequest MessageKey = "duplicate_request"
+
+	// MessageQueued is shown for ErrQueued.
+	MessageQueued MessageKey = "queued"
+
+	// MessageInvalidCode is shown for ErrInvalidCodeFormat.
+	MessageInvalidCode MessageKey = "invalid_code"
+
+	// MessageUnavailable is shown for every other error, which the user
+	// can't fix and whose details are not meant for them, such as
+	// ErrNegativeBalance.
+	MessageUnavailable MessageKey = "unavailable"
+)
+
+// messageKeys maps errors to their message, the most specific errors first
+// since some of them wrap others.
+var messageKeys = []struct {
+	err error
+	key MessageKey
+}{
+	{ErrVOIPNumber, MessageVOIPNumber},
+	{ErrInvalidPhoneNumber, MessageInvalidPhoneNumber},
+	{ErrUnsupportedRegion, MessageUnsupportedRegion},
+	{ErrOptedOut, MessageOptedOut},
+	{ErrDuplicateRequest, MessageDuplicateRequest},
+	{ErrQueued, MessageQueued},
+	{ErrInvalidCodeFormat, MessageInvalidCode},
+}
+
+// ErrorMessageKey returns the key of the message to show to end users for err.
+func ErrorMessageKey(err error) MessageKey {
+	for _, m := range messageKeys {
+		if errors.Is(err, m.err) {
+			return m.key
+		}
+	}
+
+	return MessageUnavailable
+}
+
+// Translations maps lower-cased language tags, such as "fr" or "fr-ca", to the
+// messages shown to end users. Only set the messages to add or reword: the
+// others are taken from DefaultTranslations.
+type Translations map[string]map[MessageKey]string
+
+// DefaultTranslations holds the messages of the client in English, French,
+// German and Spanish.
+var DefaultTranslations = Translations{
+	"en": {
+		MessageInvalidPhoneNumber: "This phone number is not valid. Please check it and try again.",
+		MessageVOIPNumber:         "Virtual phone numbers are not supported. Please use a mobile number.",
+		MessageUnsupportedRegion:  "We can't send codes to this country yet.",
+		MessageOptedOut:           "This phone number asked not to receive codes from us.",
+		MessageDuplicateRequest:   "A code is already on its way. Please wait a moment before asking for a new one.",
+		MessageQueued:             "Your code will be sent in a few moments.",
+		MessageInvalidCode:        "This code is not valid. Please check it and try again.",
+		MessageUnavailable:        "Something went wrong. Please try again later.",
+	},
+	"fr": {
+		MessageInvalidPhoneNumber: "Ce numéro de téléphone n'est pas valide. Vérifiez-le et réessayez.",
+		MessageVOIPNumber:         "Les numéros virtuels ne sont pas acceptés. Utilisez un numéro de mobile.",
+		MessageUnsupportedRegion:  "Nous ne pouvons pas encore envoyer de code vers ce pays.",
+		MessageOptedOut:           "Ce numéro de téléphone a demandé à ne plus recevoir de codes de notre part.",
+		MessageDuplicateRequest:   "Un code est déjà en route. Patientez un instant avant d'en demander un nouveau.",
+		MessageQueued:             "Votre code va vous être envoyé dans quelques instants.",
+		MessageInvalidCode:        "Ce code n'est pas valide. Vérifiez-le et réessayez.",
+		MessageUnavailable:        "Une erreur est survenue. Veuillez réessayer plus tard.",
+	},
+	"de": {
+		MessageInvalidPhoneNumber: "Diese Telefonnummer ist ungültig. Bitte überprüfen Sie sie und versuchen Sie es erneut.",
+		MessageVOIPNumber:         "Virtuelle Telefonnummern werden nicht unterstützt. Bitte verwenden Sie eine Mobilnummer.",
+		MessageUnsupportedRegion:  "Wir können noch keine Codes in dieses Land senden.",
+		MessageOptedOut:           "Für diese Telefonnummer wurde der Empfang unserer Codes abgelehnt.",
+		MessageDuplicateRequest:   "Ein Code ist bereits unterwegs. Bitte warten Sie einen Moment, bevor Sie einen neuen anfordern.",
+		MessageQueued:             "Ihr Code wird in wenigen Augenblicken gesendet.",
+		MessageInvalidCode:        "Dieser Code ist ungültig. Bitte überprüfen Sie ihn und versuchen Sie es erneut.",
+		MessageUnavailable:        "Etwas ist schiefgelaufen. Bitte versuchen Sie es später erneut.",
+	},
+	"es": {
+		MessageInvalidPhoneNumber: "Este número de teléfono no es válido. Compruébalo e inténtalo de nuevo.",
+		MessageVOIPNumber:         "No se admiten números virtuales. Usa un número de móvil.",
+		MessageUnsupportedRegion:  "Todavía no podemos enviar códigos a este país.",
+		MessageOptedOut:           "Este número de teléfono ha pedido no recibir nuestros códigos.",
+		MessageDuplicateRequest:   "Ya hay un código en camino. Espera un momento antes de pedir uno nuevo.",
+		MessageQueued:             "Tu código se enviará en unos instantes.",
+		MessageInvalidCode:        "Este código no es válido. Compruébalo e inténtalo de nuevo.",
+		MessageUnavailable:        "Se ha producido un error. Inténtalo de nuevo más tarde.",
+	},
+}
+
+// Message returns the message to show to end users for err in lang, a
+// language tag such as "fr" or "fr-CA". Missing messages are looked up in
+// DefaultTranslations, then in the base language of lang, then in English.
+func (t Translations) Message(err error, lang string) string {
+	key := ErrorMessageKey(err)
+
+	tag := strings.ReplaceAll(strings.ToLower(lang), "_", "-")
+	base, _, _ := strings.Cut(tag, "-")
+
+	for _, l := range []string{tag, base, "en"} {
+		for _, translations := range []Translations{t, DefaultTranslations} {
+			if msg, ok := translations[l][key]; ok {
+				return msg
+			}
+		}
+	}
+
+	return DefaultTranslations["en"][MessageUnavailable]
+}
+
+// UserMessage returns the message to show to end users for err in lang from
+// DefaultTranslations, instead of the error itself whose details are meant
+// for developers.
+func UserMessage(err error, lang string) string {
+	return DefaultTranslations.Message(err, lang)
+}
@@ -0,0 +1,33 @@
+package ding
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserMessage(t *testing.T) {
+	assert.Equal(t, MessageVOIPNumber, ErrorMessageKey(ErrVOIPNumber))
+	assert.Equal(t, MessageUnsupportedRegion, ErrorMessageKey(&Error{Err: ErrUnsupportedRegion, RequestID: "req"}))
+	assert.Equal(t, MessageUnavailable, ErrorMessageKey(ErrNegativeBalance))
+	assert.Equal(t, MessageUnavailable, ErrorMessageKey(fmt.Errorf("boom")))
+
+	assert.Equal(t, "Nous ne pouvons pas encore envoyer de code vers ce pays.", UserMessage(ErrUnsupportedRegion, "fr-CA"))
+	assert.Equal(t, "We can't send codes to this country yet.", UserMessage(ErrUnsupportedRegion, "it"))
+
+	// Every language has every message.
+	for lang, messages := range DefaultTranslations {
+		assert.Len(t, messages, len(DefaultTranslations["en"]), lang)
+	}
+
+	custom := Translations{
+		"it":    {MessageUnsupportedRegion: "Non possiamo ancora inviare codici in questo paese."},
+		"fr-ca": {MessageInvalidCode: "Ce code n'est pas bon."},
+	}
+
+	assert.Equal(t, "Non possiamo ancora inviare codici in questo paese.", custom.Message(ErrUnsupportedRegion, "it_IT"))
+	assert.Equal(t, "Ce code n'est pas bon.", custom.Message(ErrInvalidCodeFormat, "fr-CA"))
+	assert.Equal(t, DefaultTranslations["fr"][MessageInvalidCode], custom.Message(ErrInvalidCodeFormat, "fr"))
+	assert.Equal(t, DefaultTranslations["en"][MessageUnavailable], custom.Message(ErrInternal, "it"))
+}
@@ -0,0 +1,108 @@
+package ding
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/ding-live/ding-go/pkg/transport"
+)
+
+// Option overrides a setting of a Client derived with With.
+type Option func(*Client)
+
+// WithLogger sets the logger used by the derived client. Phone numbers are
+// still redacted according to Config.RedactPhoneNumbers.
+func WithLogger(logger LeveledLogger) Option {
+	return func(c *Client) {
+		c.api = c.api.WithLogger(newRedactingLogger(newSafeLogger(logger), c.phoneRedaction))
+	}
+}
+
+// WithTimeout sets the maximum duration of each call made by the derived
+// client, retries included.
+func WithTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		c.api = c.api.WithTimeout(d)
+	}
+}
+
+// WithCustomerUUID sets the customer UUID used by the derived client, for
+// services making calls on behalf of several Ding accounts. An invalid UUID is
+// reported as ErrInvalidCustomerUUID by the calls of the derived client.
+func WithCustomerUUID(customerUUID string) Option {
+	return func(c *Client) {
+		c.customerUUID = customerUUID
+		c.err = nil
+
+		if !isValidUUID(customerUUID) {
+			c.err = ErrInvalidCustomerUUID
+		}
+	}
+}
+
+// WithHeader sets a header sent with every request of the derived client,
+// for instance to tag requests going through your own proxy. It can't be used
+// to override the API key.
+func WithHeader(key, value string) Option {
+	return func(c *Client) {
+		c.api = c.api.WithHeader(key, value)
+	}
+}
+
+// WithResponseCapture stores in *res the final response received by each call
+// of the derived client, to read headers the SDK doesn't model yet or to debug
+// a call. The response holds the status and headers, but not the body, which
+// is consumed by the client, and res.Request the method and URL of the call.
+// *res is left untouched when no response is received.
+//
+// Use a derived client per call, since concurrent calls would overwrite *res.
+func WithResponseCapture(res **http.Response) Option {
+	return func(c *Client) {
+		c.api = c.api.WithOnResponse(func(req transport.Request, r transport.Response) {
+			*res = httpResponse(req, r)
+		})
+	}
+}
+
+// With returns a copy of the client with opts applied. The copy is cheap to
+// create and shares its HTTP connection pool with c, which makes it suitable
+// for per-request customization.
+func (c *Client) With(opts ...Option) *Client {
+	d := *c
+
+	for _, opt := range opts {
+		opt(&d)
+	}
+
+	return &d
+}
+
+type optionsKey struct{}
+
+// ContextWithOptions returns a copy of ctx carrying opts. Every call made with
+// the returned context, or a context derived from it, applies opts on top of
+// the settings of the client, as if it was made by a client derived with With.
+//
+// This lets middleware set options, such as the customer UUID of the current
+// tenant, for SDK calls made further down the stack. Options already attached
+// to ctx are kept and applied first.
+func ContextWithOptions(ctx context.Context, opts ...Option) context.Context {
+	prev, _ := ctx.Value(optionsKey{}).([]Option)
+
+	all := make([]Option, 0, len(prev)+len(opts))
+	all = append(all, prev...)
+	all = append(all, opts...)
+
+	return context.WithValue(ctx, optionsKey{}, all)
+}
+
+// withContext returns the client to use for a call made with ctx.
+func (c *Client) withContext(ctx context.Context) *Client {
+	opts, _ := ctx.Value(optionsKey{}).([]Option)
+	if len(opts) == 0 {
+		return c
+	}
+
+	return c.With(opts...)
+}
@@ -0,0 +1,166 @@
+package ding
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ding-live/ding-go/pkg/dingtest"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingLogger struct {
+	errors []string
+}
+
+func (l *recordingLogger) Debugf(string, ...interface{}) {}
+func (l *recordingLogger) Infof(string, ...interface{})  {}
+func (l *recordingLogger) Warnf(string, ...interface{})  {}
+
+func (l *recordingLogger) Errorf(format string, v ...interface{}) {
+	l.errors = append(l.errors, format)
+}
+
+func TestWithCustomerUUID(t *testing.T) {
+	srv := dingtest.NewServer()
+	defer srv.Close()
+
+	client := newTestClient(t, srv)
+
+	_, err := client.With(WithCustomerUUID(uuid.New().String())).Authenticate(NewAuth("+33612345678"))
+	require.ErrorIs(t, err, ErrInvalidCustomerUUID)
+
+	_, err = client.With(WithCustomerUUID("invalid")).Authenticate(NewAuth("+33612345678"))
+	require.ErrorIs(t, err, ErrInvalidCustomerUUID)
+
+	// The original client is left untouched.
+	_, err = client.Authenticate(NewAuth("+33612345678"))
+	require.NoError(t, err)
+}
+
+func TestWithLogger(t *testing.T) {
+	srv := dingtest.NewServer()
+	defer srv.Close()
+
+	client := newTestClient(t, srv)
+	logger := &recordingLogger{}
+
+	_, err := client.With(WithLogger(logger)).Check(uuid.New().String(), "1234")
+	require.ErrorIs(t, err, ErrInvalidAuthUUID)
+	assert.NotEmpty(t, logger.errors)
+}
+
+func TestWithTimeout(t *testing.T) {
+	hc := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+	}))
+	defer hc.Close()
+
+	client, err := NewClient(Config{
+		CustomerUUID:      uuid.New().String(),
+		BaseURL:           hc.URL,
+		MaxNetworkRetries: Int(0),
+	})
+	require.NoError(t, err)
+
+	_, err = client.With(WithTimeout(10*time.Millisecond)).Check(uuid.New().String(), "1234")
+	require.ErrorIs(t, err, ErrUnreachable)
+}
+
+func TestTimeouts(t *testing.T) {
+	hc := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"code":"invalid_auth_uuid"}`)
+	}))
+	defer hc.Close()
+
+	client, err := NewClient(Config{
+		CustomerUUID:      uuid.New().String(),
+		BaseURL:           hc.URL,
+		MaxNetworkRetries: Int(0),
+		Timeout:           10 * time.Millisecond,
+		Timeouts:          map[string]time.Duration{"retry": time.Second},
+	})
+	require.NoError(t, err)
+
+	_, err = client.Check(uuid.New().String(), "1234")
+	require.ErrorIs(t, err, ErrUnreachable)
+
+	_, err = client.Retry(uuid.New().String())
+	require.ErrorIs(t, err, ErrInvalidAuthUUID)
+
+	// WithTimeout overrides the timeouts of every endpoint.
+	_, err = client.With(WithTimeout(10 * time.Millisecond)).Retry(uuid.New().String())
+	require.ErrorIs(t, err, ErrUnreachable)
+}
+
+func TestWithResponseCapture(t *testing.T) {
+	srv := dingtest.NewServer()
+	defer srv.Close()
+
+	client, err := NewClient(Config{
+		CustomerUUID: dingtest.CustomerUUID,
+		APIKey:       dingtest.APIKey,
+		BaseURL:      srv.URL,
+	})
+	require.NoError(t, err)
+
+	var res *http.Response
+
+	_, err = client.With(WithResponseCapture(&res)).Authenticate(NewAuth("+33612345678"))
+	require.NoError(t, err)
+
+	require.NotNil(t, res)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "application/json", res.Header.Get("content-type"))
+	assert.Equal(t, http.MethodPost, res.Request.Method)
+	assert.Equal(t, "/authentication", res.Request.URL.Path)
+
+	// Other clients are not affected.
+	res = nil
+
+	_, err = client.Authenticate(NewAuth("+33612345678"))
+	require.NoError(t, err)
+	assert.Nil(t, res)
+}
+
+func TestContextWithOptions(t *testing.T) {
+	var header string
+
+	hc := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Get("x-tenant")
+
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprintf(w, `{"authentication_uuid": "%s", "status": "pending"}`, uuid.New().String())
+	}))
+	defer hc.Close()
+
+	client, err := NewClient(Config{
+		CustomerUUID: uuid.New().String(),
+		BaseURL:      hc.URL,
+	})
+	require.NoError(t, err)
+
+	ctx := ContextWithOptions(context.Background(), WithHeader("x-tenant", "acme"))
+
+	_, err = client.AuthenticateWithContext(ctx, NewAuth("+33612345678"))
+	require.NoError(t, err)
+	assert.Equal(t, "acme", header)
+
+	ctx = ContextWithOptions(ctx, WithCustomerUUID("invalid"))
+
+	_, err = client.AuthenticateWithContext(ctx, NewAuth("+33612345678"))
+	require.ErrorIs(t, err, ErrInvalidCustomerUUID)
+
+	// Calls made without the context are left untouched.
+	_, err = client.Authenticate(NewAuth("+33612345678"))
+	require.NoError(t, err)
+	assert.Empty(t, header)
+}
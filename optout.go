@@ -0,0 +1,133 @@
+package ding
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// OptOutList holds the phone numbers that must never receive a code, such as
+// the ones of users who asked not to be contacted. Numbers are given in E.164
+// format. Implementations must be safe for concurrent use. Use
+// MemoryOptOutList, or implement it on top of a shared store or of the
+// do-not-contact service of your company, with a local cache if needed.
+type OptOutList interface {
+	Add(ctx context.Context, phoneNumber string) error
+	Remove(ctx context.Context, phoneNumber string) error
+	Contains(ctx context.Context, phoneNumber string) (bool, error)
+}
+
+// MemoryOptOutList is an OptOutList keeping numbers in memory.
+type MemoryOptOutList struct {
+	mu      sync.RWMutex
+	numbers map[string]struct{}
+}
+
+// NewMemoryOptOutList returns a list holding phoneNumbers, which must be in
+// E.164 format.
+func NewMemoryOptOutList(phoneNumbers ...string) *MemoryOptOutList {
+	l := &MemoryOptOutList{numbers: make(map[string]struct{}, len(phoneNumbers))}
+
+	for _, n := range phoneNumbers {
+		l.numbers[n] = struct{}{}
+	}
+
+	return l
+}
+
+// Add adds phoneNumber to the list.
+func (l *MemoryOptOutList) Add(_ context.Context, phoneNumber string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.numbers[phoneNumber] = struct{}{}
+
+	return nil
+}
+
+// Remove removes phoneNumber from the list, if present.
+func (l *MemoryOptOutList) Remove(_ context.Context, phoneNumber string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.numbers, phoneNumber)
+
+	return nil
+}
+
+// Contains reports whether phoneNumber is in the list.
+func (l *MemoryOptOutList) Contains(_ context.Context, phoneNumber string) (bool, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	_, ok := l.numbers[phoneNumber]
+
+	return ok, nil
+}
+
+// ----------------------------------------------------------------------------
+
+// OptOut adds phoneNumber to Config.OptOutList, so that Authenticate refuses
+// to send it codes with ErrOptedOut. The number is normalized as by
+// Authenticate with Config.DefaultPhoneRegion.
+func (c *Client) OptOut(ctx context.Context, phoneNumber string) error {
+	p, err := c.optOutNumber(phoneNumber)
+	if err != nil {
+		return err
+	}
+
+	if perr := c.protect("opt-out list", func() { err = c.optOutList.Add(ctx, p.E164) }); perr != nil {
+		return perr
+	}
+
+	return err
+}
+
+// OptIn removes phoneNumber from Config.OptOutList.
+func (c *Client) OptIn(ctx context.Context, phoneNumber string) error {
+	p, err := c.optOutNumber(phoneNumber)
+	if err != nil {
+		return err
+	}
+
+	if perr := c.protect("opt-out list", func() { err = c.optOutList.Remove(ctx, p.E164) }); perr != nil {
+		return perr
+	}
+
+	return err
+}
+
+func (c *Client) optOutNumber(phoneNumber string) (PhoneNumber, error) {
+	if c.optOutList == nil {
+		return PhoneNumber{}, ErrNoOptOutList
+	}
+
+	return c.phoneCache.normalize(phoneNumber, c.defaultPhoneRegion, c.phoneValidation)
+}
+
+// checkOptOut returns ErrOptedOut if p is in the opt-out list of the client.
+// Numbers are not sent when the list can't be read.
+func (c *Client) checkOptOut(ctx context.Context, p PhoneNumber) error {
+	if c.optOutList == nil {
+		return nil
+	}
+
+	var (
+		optedOut bool
+		err      error
+	)
+
+	if perr := c.protect("opt-out list", func() { optedOut, err = c.optOutList.Contains(ctx, p.E164) }); perr != nil {
+		return perr
+	}
+
+	if err != nil {
+		return fmt.Errorf("check opt-out list: %w", err)
+	}
+
+	if optedOut {
+		return ErrOptedOut
+	}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package ding
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ding-live/ding-go/pkg/dingtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type failingOptOutList struct {
+	*MemoryOptOutList
+}
+
+func (*failingOptOutList) Contains(context.Context, string) (bool, error) {
+	return false, errors.New("unavailable")
+}
+
+func TestOptOut(t *testing.T) {
+	srv := dingtest.NewServer()
+	defer srv.Close()
+
+	ctx := context.Background()
+	list := NewMemoryOptOutList("+33612345679")
+
+	client, err := NewClient(Config{
+		CustomerUUID: dingtest.CustomerUUID,
+		APIKey:       dingtest.APIKey,
+		BaseURL:      srv.URL,
+		OptOutList:   list,
+	})
+	require.NoError(t, err)
+
+	_, err = client.Authenticate(NewAuth("+33612345679"))
+	assert.ErrorIs(t, err, ErrOptedOut)
+
+	require.NoError(t, client.OptOut(ctx, "+33 6 12 34 56 78"))
+
+	optedOut, err := list.Contains(ctx, "+33612345678")
+	require.NoError(t, err)
+	assert.True(t, optedOut)
+
+	_, err = client.Authenticate(NewAuth("+33612345678"))
+	assert.ErrorIs(t, err, ErrOptedOut)
+
+	require.NoError(t, client.OptIn(ctx, "+33612345678"))
+
+	_, err = client.Authenticate(NewAuth("+33612345678"))
+	assert.NoError(t, err)
+
+	assert.ErrorIs(t, client.OptOut(ctx, "invalid"), ErrInvalidPhoneNumber)
+}
+
+func TestOptOutErrors(t *testing.T) {
+	srv := dingtest.NewServer()
+	defer srv.Close()
+
+	client := newTestClient(t, srv)
+	assert.ErrorIs(t, client.OptOut(context.Background(), "+33612345678"), ErrNoOptOutList)
+
+	client, err := NewClient(Config{
+		CustomerUUID: dingtest.CustomerUUID,
+		APIKey:       dingtest.APIKey,
+		BaseURL:      srv.URL,
+		OptOutList:   &failingOptOutList{NewMemoryOptOutList()},
+	})
+	require.NoError(t, err)
+
+	// Numbers are not sent when the list can't be read.
+	_, err = client.Authenticate(NewAuth("+33612345678"))
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrOptedOut)
+}
@@ -0,0 +1,159 @@
+package ding
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+const (
+	defaultPagerMaxRetries = 3
+	defaultPagerBackoff    = time.Second
+)
+
+// Page is a page of results returned by a list endpoint.
+type Page[T any] struct {
+	Items []T
+
+	// Cursor is the cursor the page was retrieved with. It can be used as
+	// PagerConfig.StartCursor to resume an interrupted iteration.
+	Cursor string
+
+	// Err is set when the page could not be retrieved. A page with an error is
+	// always the last one.
+	Err error
+}
+
+// PageFetcher retrieves the page of results designated by cursor, and returns
+// the cursor of the next page. An empty cursor designates the first page, and
+// an empty next cursor marks the last page.
+type PageFetcher[T any] func(ctx context.Context, cursor string) (items []T, next string, err error)
+
+// PagerConfig configures a Pager.
+type PagerConfig struct {
+	// StartCursor is the cursor of the first page to retrieve.
+	//
+	// Defaults to the first page.
+	StartCursor string
+
+	// MaxRetries is the maximum number of times a page is requested again when
+	// the fetcher returns an error wrapping ErrUnreachable. Other errors, such
+	// as those of rate limited requests the client already retried, end the
+	// iteration.
+	//
+	// Defaults to 3.
+	MaxRetries *int
+
+	// Backoff is the delay before requesting a page again. It doubles after
+	// every attempt.
+	//
+	// Defaults to 1 second.
+	Backoff time.Duration
+}
+
+// Pager iterates over the results of a list endpoint, one page at a time.
+type Pager[T any] struct {
+	fetch      PageFetcher[T]
+	cursor     string
+	maxRetries int
+	backoff    time.Duration
+}
+
+// NewPager returns a Pager retrieving pages with fetch.
+func NewPager[T any](fetch PageFetcher[T], cfg PagerConfig) *Pager[T] {
+	p := &Pager[T]{
+		fetch:      fetch,
+		cursor:     cfg.StartCursor,
+		maxRetries: defaultPagerMaxRetries,
+		backoff:    cfg.Backoff,
+	}
+
+	if cfg.MaxRetries != nil {
+		p.maxRetries = *cfg.MaxRetries
+	}
+
+	if p.backoff <= 0 {
+		p.backoff = defaultPagerBackoff
+	}
+
+	return p
+}
+
+// Pages returns a channel receiving the pages in order:
+//
+//	for page := range pager.Pages(ctx) {
+//		if page.Err != nil {
+//			return page.Err
+//		}
+//		...
+//	}
+//
+// The channel is closed after the last page, after a page with an error, or
+// once ctx is done. Cancel ctx when you stop reading before the end.
+func (p *Pager[T]) Pages(ctx context.Context) <-chan Page[T] {
+	ch := make(chan Page[T])
+
+	go func() {
+		defer close(ch)
+
+		cursor := p.cursor
+
+		for {
+			items, next, err := p.fetchPage(ctx, cursor)
+
+			select {
+			case ch <- Page[T]{Items: items, Cursor: cursor, Err: err}:
+			case <-ctx.Done():
+				return
+			}
+
+			if err != nil || next == "" {
+				return
+			}
+
+			cursor = next
+		}
+	}()
+
+	return ch
+}
+
+// All retrieves every page and returns their items. On failure, the items
+// retrieved so far are returned along with the error.
+func (p *Pager[T]) All(ctx context.Context) ([]T, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var all []T
+
+	for page := range p.Pages(ctx) {
+		if page.Err != nil {
+			return all, page.Err
+		}
+
+		all = append(all, page.Items...)
+	}
+
+	return all, ctx.Err()
+}
+
+func (p *Pager[T]) fetchPage(ctx context.Context, cursor string) ([]T, string, error) {
+	backoff := p.backoff
+
+	for attempt := 0; ; attempt++ {
+		items, next, err := p.fetch(ctx, cursor)
+		if err == nil {
+			return items, next, nil
+		}
+
+		if !errors.Is(err, ErrUnreachable) || attempt == p.maxRetries {
+			return nil, "", err
+		}
+
+		if !waitUntil(ctx, time.Now().Add(backoff)) {
+			return nil, "", err
+		}
+
+		backoff *= 2
+	}
+}
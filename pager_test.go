@@ -0,0 +1,77 @@
+package ding
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fetchNumbers returns pages of two numbers from 0 to 5, the cursor being the
+// first number of the page.
+func fetchNumbers(_ context.Context, cursor string) ([]int, string, error) {
+	start := 0
+	if cursor != "" {
+		start, _ = strconv.Atoi(cursor)
+	}
+
+	next := ""
+	if start+2 < 6 {
+		next = strconv.Itoa(start + 2)
+	}
+
+	return []int{start, start + 1}, next, nil
+}
+
+func TestPagerAll(t *testing.T) {
+	all, err := NewPager(fetchNumbers, PagerConfig{}).All(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []int{0, 1, 2, 3, 4, 5}, all)
+
+	all, err = NewPager(fetchNumbers, PagerConfig{StartCursor: "4"}).All(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []int{4, 5}, all)
+}
+
+func TestPagerRetriesTransientErrors(t *testing.T) {
+	failures := 2
+
+	fetch := func(ctx context.Context, cursor string) ([]int, string, error) {
+		if cursor == "2" && failures > 0 {
+			failures--
+			return nil, "", ErrUnreachable
+		}
+
+		return fetchNumbers(ctx, cursor)
+	}
+
+	all, err := NewPager(fetch, PagerConfig{Backoff: time.Millisecond}).All(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []int{0, 1, 2, 3, 4, 5}, all)
+}
+
+func TestPagerPartialFailure(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	fetch := func(ctx context.Context, cursor string) ([]int, string, error) {
+		if cursor == "2" {
+			return nil, "", errBoom
+		}
+
+		return fetchNumbers(ctx, cursor)
+	}
+
+	var pages []Page[int]
+	for page := range NewPager(fetch, PagerConfig{}).Pages(context.Background()) {
+		pages = append(pages, page)
+	}
+
+	require.Len(t, pages, 2)
+	assert.Equal(t, []int{0, 1}, pages[0].Items)
+	assert.ErrorIs(t, pages[1].Err, errBoom)
+	assert.Equal(t, "2", pages[1].Cursor)
+}
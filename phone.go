@@ -0,0 +1,161 @@
+package ding
+
+import (
+	"time"
+
+	"github.com/ding-live/ding-go/internal/lru"
+)
+
+// PhoneValidation controls how phone numbers are validated before being sent
+// to the Ding API.
+type PhoneValidation int
+
+const (
+	// PhoneValidationStrict only accepts numbers that belong to a range known
+	// to be allocated. This is the default.
+	PhoneValidationStrict PhoneValidation = iota
+
+	// PhoneValidationLenient accepts any number with a plausible length for
+	// its country, including ranges allocated after the validation metadata
+	// embedded in the library was last updated.
+	PhoneValidationLenient
+
+	// PhoneValidationOff does not validate numbers and lets the API decide.
+	// Numbers that can be parsed are still normalized to E.164.
+	PhoneValidationOff
+)
+
+// PhoneNumber is a phone number as sent to the Ding API, after normalization.
+type PhoneNumber struct {
+	// E164 is the number in E.164 format, such as "+33612345678".
+	E164 string
+
+	// CountryCode is the country calling code of the number, such as 33. It is
+	// 0 if the number could not be parsed, which can only happen when phone
+	// validation is off.
+	CountryCode int
+
+	// Region is the two-letter ISO code of the region the number belongs to,
+	// such as "FR", or an empty string if it is unknown.
+	Region string
+
+	// LineType is the type of line the number belongs to, as given by its
+	// range. It is LineTypeUnknown for numbers that are not valid.
+	LineType LineType
+}
+
+// LineType is the type of line of a phone number.
+type LineType string
+
+const (
+	LineTypeUnknown LineType = ""
+	LineTypeMobile  LineType = "mobile"
+
+	// LineTypeFixedLine is used for numbers of landlines, which can't
+	// receive SMS.
+	LineTypeFixedLine LineType = "fixed_line"
+
+	// LineTypeFixedLineOrMobile is used in regions where the ranges of
+	// landlines and mobiles can't be told apart, such as the United States.
+	LineTypeFixedLineOrMobile LineType = "fixed_line_or_mobile"
+
+	// LineTypeVOIP is used for numbers of VOIP services, which are commonly
+	// used to create fake accounts.
+	LineTypeVOIP LineType = "voip"
+
+	LineTypeTollFree    LineType = "toll_free"
+	LineTypePremiumRate LineType = "premium_rate"
+
+	// LineTypeOther is used for the remaining types, such as pagers or
+	// personal numbers.
+	LineTypeOther LineType = "other"
+)
+
+func (t LineType) String() string {
+	return string(t)
+}
+
+func (p PhoneNumber) String() string {
+	return p.E164
+}
+
+// NormalizePhoneNumber parses raw and returns it in the E.164 format expected
+// by the Ding API, such as "+33612345678".
+//
+// defaultRegion is the two-letter ISO country code used to interpret numbers
+// written in national format, such as "FR" for "06 12 34 56 78". It can be
+// left empty if raw is in international format. ErrInvalidPhoneNumber is
+// returned if raw is not a valid phone number.
+func NormalizePhoneNumber(raw, defaultRegion string) (string, error) {
+	p, err := ParsePhoneNumber(raw, defaultRegion)
+	if err != nil {
+		return "", err
+	}
+
+	return p.E164, nil
+}
+
+// ParsePhoneNumber is like NormalizePhoneNumber, but returns everything known
+// about the number, such as its region and line type, without calling the
+// Ding API.
+func ParsePhoneNumber(raw, defaultRegion string) (PhoneNumber, error) {
+	return normalizePhoneNumber(raw, defaultRegion, PhoneValidationStrict)
+}
+
+const (
+	defaultPhoneCacheSize = 1024
+	defaultPhoneCacheTTL  = 10 * time.Minute
+)
+
+type phoneCacheKey struct {
+	raw    string
+	region string
+	mode   PhoneValidation
+}
+
+type phoneCacheEntry struct {
+	phoneNumber PhoneNumber
+	err         error
+}
+
+// phoneCache caches the results of normalizePhoneNumber, since parsing shows
+// up in profiles when the same numbers are validated over and over.
+type phoneCache struct {
+	cache *lru.Cache[phoneCacheKey, phoneCacheEntry]
+}
+
+func newPhoneCache(size int, ttl time.Duration) *phoneCache {
+	if size < 0 {
+		return nil
+	}
+
+	if size == 0 {
+		size = defaultPhoneCacheSize
+	}
+
+	if ttl == 0 {
+		ttl = defaultPhoneCacheTTL
+	}
+
+	return &phoneCache{
+		cache: lru.New[phoneCacheKey, phoneCacheEntry](size, ttl),
+	}
+}
+
+// normalize is normalizePhoneNumber going through the cache. A nil cache
+// doesn't cache anything.
+func (c *phoneCache) normalize(raw, region string, mode PhoneValidation) (PhoneNumber, error) {
+	if c == nil {
+		return normalizePhoneNumber(raw, region, mode)
+	}
+
+	key := phoneCacheKey{raw: raw, region: region, mode: mode}
+	if e, ok := c.cache.Get(key); ok {
+		return e.phoneNumber, e.err
+	}
+
+	p, err := normalizePhoneNumber(raw, region, mode)
+	c.cache.Add(key, phoneCacheEntry{phoneNumber: p, err: err})
+
+	return p, err
+}
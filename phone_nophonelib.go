@@ -0,0 +1,65 @@
+//go:build ding_nophonelib
+
+package ding
+
+import "strings"
+
+// When built with the ding_nophonelib tag, the SDK doesn't embed the phone
+// number metadata of libphonenumber. Numbers must then be given in
+// international format, and are only checked to look like E.164 numbers:
+// ranges and regions are left for the API to validate. PhoneNumber.CountryCode,
+// PhoneNumber.Region and PhoneNumber.LineType are never set, so
+// Config.RejectVOIPNumbers has no effect.
+
+const (
+	minE164Digits = 8
+	maxE164Digits = 15
+)
+
+func normalizePhoneNumber(raw, region string, mode PhoneValidation) (PhoneNumber, error) {
+	e164, ok := toE164(raw)
+	if !ok {
+		if mode == PhoneValidationOff {
+			return PhoneNumber{E164: raw}, nil
+		}
+
+		return PhoneNumber{}, ErrInvalidPhoneNumber
+	}
+
+	return PhoneNumber{E164: e164}, nil
+}
+
+// toE164 strips the usual separators from raw, and reports whether the result
+// is a number in international format.
+func toE164(raw string) (string, bool) {
+	s := strings.TrimSpace(raw)
+
+	switch {
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	case strings.HasPrefix(s, "00"):
+		s = s[2:]
+	default:
+		return "", false
+	}
+
+	var b strings.Builder
+	b.WriteByte('+')
+
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == ' ' || r == '-' || r == '.' || r == '(' || r == ')':
+		default:
+			return "", false
+		}
+	}
+
+	digits := b.Len() - 1
+	if digits < minE164Digits || digits > maxE164Digits || b.String()[1] == '0' {
+		return "", false
+	}
+
+	return b.String(), true
+}
@@ -0,0 +1,37 @@
+//go:build ding_nophonelib
+
+package ding
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizePhoneNumberNoPhoneLib(t *testing.T) {
+	tests := []struct {
+		raw     string
+		mode    PhoneValidation
+		want    string
+		wantErr bool
+	}{
+		{"+33 6 12 34 56 78", PhoneValidationStrict, "+33612345678", false},
+		{"0033 (6) 12-34-56-78", PhoneValidationStrict, "+33612345678", false},
+		{"06 12 34 56 78", PhoneValidationStrict, "", true},
+		{"+33 6 1", PhoneValidationLenient, "", true},
+		{"+0 612 345 678", PhoneValidationStrict, "", true},
+		{"not a number", PhoneValidationOff, "not a number", false},
+	}
+
+	for _, tt := range tests {
+		got, err := normalizePhoneNumber(tt.raw, "FR", tt.mode)
+		if tt.wantErr {
+			assert.ErrorIs(t, err, ErrInvalidPhoneNumber, tt.raw)
+			continue
+		}
+
+		require.NoError(t, err, tt.raw)
+		assert.Equal(t, tt.want, got.E164, tt.raw)
+	}
+}
@@ -0,0 +1,170 @@
+//go:build !ding_nophonelib
+
+package ding
+
+import (
+	"testing"
+
+	"github.com/ding-live/ding-go/pkg/dingtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizePhoneNumber(t *testing.T) {
+	tests := []struct {
+		raw    string
+		region string
+		want   string
+	}{
+		{"+33612345678", "", "+33612345678"},
+		{"+33 6 12 34 56 78", "", "+33612345678"},
+		{"06 12 34 56 78", "FR", "+33612345678"},
+		{"06 12 34 56 78", "fr", "+33612345678"},
+		{"+1 (201) 555-0123", "FR", "+12015550123"},
+	}
+
+	for _, tt := range tests {
+		got, err := NormalizePhoneNumber(tt.raw, tt.region)
+		require.NoError(t, err, tt.raw)
+		assert.Equal(t, tt.want, got, tt.raw)
+	}
+}
+
+func TestNormalizeInvalidPhoneNumber(t *testing.T) {
+	for _, raw := range []string{"", "invalid", "06 12 34 56 78", "+33 1"} {
+		_, err := NormalizePhoneNumber(raw, "")
+		assert.ErrorIs(t, err, ErrInvalidPhoneNumber, raw)
+	}
+}
+
+func TestDefaultPhoneRegion(t *testing.T) {
+	srv := dingtest.NewServer()
+	defer srv.Close()
+
+	client := newTestClient(t, srv)
+
+	_, err := client.Authenticate(AuthenticateOptions{PhoneNumber: "06 12 34 56 78"})
+	require.ErrorIs(t, err, ErrInvalidPhoneNumber)
+
+	auth, err := client.Authenticate(NewAuth("06 12 34 56 78").WithPhoneRegion("FR"))
+	require.NoError(t, err)
+	assert.Equal(t, PhoneNumber{
+		E164:        "+33612345678",
+		CountryCode: 33,
+		Region:      "FR",
+		LineType:    LineTypeMobile,
+	}, auth.PhoneNumber)
+
+	client, err = NewClient(Config{
+		CustomerUUID:       dingtest.CustomerUUID,
+		APIKey:             dingtest.APIKey,
+		BaseURL:            srv.URL,
+		DefaultPhoneRegion: "FR",
+	})
+	require.NoError(t, err)
+
+	_, err = client.Authenticate(AuthenticateOptions{PhoneNumber: "06 12 34 56 78"})
+	require.NoError(t, err)
+}
+
+func TestPhoneValidation(t *testing.T) {
+	tests := []struct {
+		raw     string
+		mode    PhoneValidation
+		want    string
+		wantErr bool
+	}{
+		{"+1 201 111 1111", PhoneValidationStrict, "", true},
+		{"+1 201 111 1111", PhoneValidationLenient, "+12011111111", false},
+		{"+33 6 1234", PhoneValidationLenient, "", true},
+		{"+33 6 1234", PhoneValidationOff, "+3361234", false},
+		{"not a number", PhoneValidationOff, "not a number", false},
+	}
+
+	for _, tt := range tests {
+		got, err := normalizePhoneNumber(tt.raw, "", tt.mode)
+		if tt.wantErr {
+			assert.ErrorIs(t, err, ErrInvalidPhoneNumber, tt.raw)
+			continue
+		}
+
+		require.NoError(t, err, tt.raw)
+		assert.Equal(t, tt.want, got.E164, tt.raw)
+	}
+}
+
+func TestLineType(t *testing.T) {
+	for raw, want := range map[string]LineType{
+		"+33612345678":  LineTypeMobile,
+		"+33123456789":  LineTypeFixedLine,
+		"+12015550123":  LineTypeFixedLineOrMobile,
+		"+445612345678": LineTypeVOIP,
+		"+33800123456":  LineTypeTollFree,
+	} {
+		p, err := ParsePhoneNumber(raw, "")
+		require.NoError(t, err, raw)
+		assert.Equal(t, want, p.LineType, raw)
+	}
+
+	p, err := normalizePhoneNumber("+1 201 111 1111", "", PhoneValidationLenient)
+	require.NoError(t, err)
+	assert.Equal(t, LineTypeUnknown, p.LineType)
+}
+
+func TestRejectVOIPNumbers(t *testing.T) {
+	srv := dingtest.NewServer()
+	defer srv.Close()
+
+	client, err := NewClient(Config{
+		CustomerUUID:      dingtest.CustomerUUID,
+		APIKey:            dingtest.APIKey,
+		BaseURL:           srv.URL,
+		RejectVOIPNumbers: true,
+	})
+	require.NoError(t, err)
+
+	_, err = client.Authenticate(NewAuth("+445612345678"))
+	assert.ErrorIs(t, err, ErrVOIPNumber)
+	assert.ErrorIs(t, err, ErrInvalidPhoneNumber)
+
+	_, err = client.Authenticate(NewAuth("+33612345678"))
+	assert.NoError(t, err)
+}
+
+func TestLandlineWarning(t *testing.T) {
+	srv := dingtest.NewServer()
+	defer srv.Close()
+
+	client := newTestClient(t, srv)
+
+	auth, err := client.Authenticate(NewAuth("+33123456789"))
+	require.NoError(t, err)
+	require.Len(t, auth.Warnings, 1)
+	assert.Equal(t, WarningLandline, auth.Warnings[0].Code)
+
+	auth, err = client.Authenticate(NewAuth("+33612345678"))
+	require.NoError(t, err)
+	assert.Empty(t, auth.Warnings)
+}
+
+func TestPhoneCache(t *testing.T) {
+	c := newPhoneCache(0, 0)
+
+	for i := 0; i < 2; i++ {
+		p, err := c.normalize("06 12 34 56 78", "FR", PhoneValidationStrict)
+		require.NoError(t, err)
+		assert.Equal(t, "+33612345678", p.E164)
+
+		_, err = c.normalize("+1 201 111 1111", "", PhoneValidationStrict)
+		assert.ErrorIs(t, err, ErrInvalidPhoneNumber)
+	}
+
+	assert.Equal(t, 2, c.cache.Len())
+
+	// The validation mode is part of the key.
+	p, err := c.normalize("+1 201 111 1111", "", PhoneValidationLenient)
+	require.NoError(t, err)
+	assert.Equal(t, "+12011111111", p.E164)
+
+	assert.Nil(t, newPhoneCache(-1, 0))
+}
@@ -0,0 +1,87 @@
+//go:build !ding_nophonelib
+
+// The phonenumbers package parses its metadata for every region in its init
+// function, whether numbers are validated or not, and it can't be deferred
+// from here: up to its latest release, loading is neither lazy nor per region.
+// Services that only call Check or Retry and care about startup time or
+// memory should build with the ding_nophonelib tag instead.
+
+package ding
+
+import (
+	"strings"
+
+	"github.com/nyaruka/phonenumbers"
+)
+
+// unknownRegion is the region used to parse numbers when none is given. It
+// only accepts numbers in international format.
+const unknownRegion = "ZZ"
+
+func normalizePhoneNumber(raw, region string, mode PhoneValidation) (PhoneNumber, error) {
+	num, err := parsePhoneNumber(raw, region)
+	if err != nil {
+		if mode == PhoneValidationOff {
+			return PhoneNumber{E164: raw}, nil
+		}
+
+		return PhoneNumber{}, err
+	}
+
+	switch mode {
+	case PhoneValidationStrict:
+		if !phonenumbers.IsValidNumber(num) {
+			return PhoneNumber{}, ErrInvalidPhoneNumber
+		}
+	case PhoneValidationLenient:
+		if !phonenumbers.IsPossibleNumber(num) {
+			return PhoneNumber{}, ErrInvalidPhoneNumber
+		}
+	}
+
+	p := PhoneNumber{
+		E164:        phonenumbers.Format(num, phonenumbers.E164),
+		CountryCode: int(num.GetCountryCode()),
+		LineType:    lineType(phonenumbers.GetNumberType(num)),
+	}
+
+	if r := phonenumbers.GetRegionCodeForNumber(num); r != unknownRegion {
+		p.Region = r
+	}
+
+	return p, nil
+}
+
+func lineType(t phonenumbers.PhoneNumberType) LineType {
+	switch t {
+	case phonenumbers.UNKNOWN:
+		return LineTypeUnknown
+	case phonenumbers.MOBILE:
+		return LineTypeMobile
+	case phonenumbers.FIXED_LINE:
+		return LineTypeFixedLine
+	case phonenumbers.FIXED_LINE_OR_MOBILE:
+		return LineTypeFixedLineOrMobile
+	case phonenumbers.VOIP:
+		return LineTypeVOIP
+	case phonenumbers.TOLL_FREE:
+		return LineTypeTollFree
+	case phonenumbers.PREMIUM_RATE:
+		return LineTypePremiumRate
+	default:
+		return LineTypeOther
+	}
+}
+
+func parsePhoneNumber(raw, region string) (*phonenumbers.PhoneNumber, error) {
+	if region == "" {
+		region = unknownRegion
+	}
+
+	num, err := phonenumbers.Parse(raw, strings.ToUpper(region))
+	if err != nil {
+		return nil, ErrInvalidPhoneNumber
+	}
+
+	return num, nil
+}
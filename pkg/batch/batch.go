@@ -0,0 +1,112 @@
+// Package batch sends authentications in bulk, for instance to re-verify the
+// phone numbers of your users on a schedule. It bounds the number of
+// concurrent calls and their aggregate rate, and reports the result of every
+// item separately.
+package batch
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	ding "github.com/ding-live/ding-go"
+)
+
+const defaultWorkers = 4
+
+// Client is the subset of *ding.Client used by Authenticate.
+type Client interface {
+	AuthenticateWithContext(ctx context.Context, opt ding.AuthenticateOptions) (*ding.Authentication, error)
+}
+
+// Config configures how a batch is executed.
+type Config struct {
+	// Workers is the maximum number of concurrent calls.
+	//
+	// Defaults to 4.
+	Workers int
+
+	// RatePerSecond is the maximum number of calls started per second, across
+	// all workers.
+	//
+	// Defaults to no limit.
+	RatePerSecond float64
+}
+
+// Result is the outcome of a single item of a batch.
+type Result struct {
+	// Index is the position of the item in the batch.
+	Index int
+
+	Options        ding.AuthenticateOptions
+	Authentication *ding.Authentication
+	Err            error
+}
+
+// Authenticate authenticates every item of opts with client and returns their
+// results in the same order. A failing item doesn't stop the batch: its error
+// is reported in its Result. If ctx is done, the items that were not sent yet
+// fail with the context error.
+func Authenticate(ctx context.Context, client Client, opts []ding.AuthenticateOptions, cfg Config) []Result {
+	if cfg.Workers <= 0 {
+		cfg.Workers = defaultWorkers
+	}
+
+	results := make([]Result, len(opts))
+	for i, opt := range opts {
+		results[i] = Result{Index: i, Options: opt}
+	}
+
+	var tick <-chan time.Time
+	if cfg.RatePerSecond > 0 {
+		ticker := time.NewTicker(time.Duration(float64(time.Second) / cfg.RatePerSecond))
+		defer ticker.Stop()
+
+		tick = ticker.C
+	}
+
+	items := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < cfg.Workers; w++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := range items {
+				r := &results[i]
+				r.Authentication, r.Err = client.AuthenticateWithContext(ctx, r.Options)
+			}
+		}()
+	}
+
+	sent := 0
+
+loop:
+	for ; sent < len(opts); sent++ {
+		// The first call is not delayed.
+		if tick != nil && sent > 0 {
+			select {
+			case <-ctx.Done():
+				break loop
+			case <-tick:
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			break loop
+		case items <- sent:
+		}
+	}
+
+	close(items)
+	wg.Wait()
+
+	for i := sent; i < len(results); i++ {
+		results[i].Err = ctx.Err()
+	}
+
+	return results
+}
@@ -0,0 +1,90 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	ding "github.com/ding-live/ding-go"
+	"github.com/ding-live/ding-go/pkg/status"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClient struct {
+	mu      sync.Mutex
+	active  int
+	maxSeen int
+}
+
+func (c *fakeClient) AuthenticateWithContext(ctx context.Context, opt ding.AuthenticateOptions) (*ding.Authentication, error) {
+	c.mu.Lock()
+	c.active++
+	if c.active > c.maxSeen {
+		c.maxSeen = c.active
+	}
+	c.mu.Unlock()
+
+	time.Sleep(5 * time.Millisecond)
+
+	c.mu.Lock()
+	c.active--
+	c.mu.Unlock()
+
+	if opt.PhoneNumber == "invalid" {
+		return nil, ding.ErrInvalidPhoneNumber
+	}
+
+	return &ding.Authentication{Status: status.AuthPending}, nil
+}
+
+func TestAuthenticate(t *testing.T) {
+	client := &fakeClient{}
+
+	opts := make([]ding.AuthenticateOptions, 10)
+	for i := range opts {
+		opts[i] = ding.NewAuth("+33612345678")
+	}
+	opts[3] = ding.NewAuth("invalid")
+
+	results := Authenticate(context.Background(), client, opts, Config{Workers: 2})
+	require.Len(t, results, 10)
+
+	for i, r := range results {
+		assert.Equal(t, i, r.Index)
+
+		if i == 3 {
+			assert.ErrorIs(t, r.Err, ding.ErrInvalidPhoneNumber)
+			continue
+		}
+
+		require.NoError(t, r.Err)
+		assert.Equal(t, status.AuthPending, r.Authentication.Status)
+	}
+
+	assert.Equal(t, 2, client.maxSeen)
+}
+
+func TestAuthenticateRate(t *testing.T) {
+	opts := make([]ding.AuthenticateOptions, 5)
+
+	start := time.Now()
+	Authenticate(context.Background(), &fakeClient{}, opts, Config{Workers: 5, RatePerSecond: 100})
+
+	// 4 intervals of 10ms between the 5 calls.
+	assert.GreaterOrEqual(t, time.Since(start), 40*time.Millisecond)
+}
+
+func TestAuthenticateCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	results := Authenticate(ctx, &fakeClient{}, make([]ding.AuthenticateOptions, 3), Config{RatePerSecond: 1})
+	for _, r := range results {
+		assert.True(t, r.Err == nil || errors.Is(r.Err, context.Canceled))
+	}
+
+	assert.ErrorIs(t, results[2].Err, context.Canceled)
+}
@@ -0,0 +1,48 @@
+// Package code defines the error codes returned by the Ding API, so that
+// applications can switch on them without depending on the internals of the
+// client. The values are stable: new codes may be added, but existing ones are
+// never renamed.
+package code
+
+// Code is the code of an error response of the Ding API. Codes that are not
+// known to the SDK are kept as is.
+type Code string
+
+const (
+	// InternalServerError is returned when the API failed to handle the
+	// request.
+	InternalServerError Code = "internal_server_error"
+
+	// BadRequest is returned for malformed requests.
+	BadRequest Code = "bad_request"
+
+	// InvalidPhoneNumber is returned for phone numbers that can't be parsed.
+	InvalidPhoneNumber Code = "invalid_phone_number"
+
+	// AccountInvalid is returned when the customer UUID is unknown.
+	AccountInvalid Code = "account_invalid"
+
+	// NegativeBalance is returned when the account has no credit left.
+	NegativeBalance Code = "negative_balance"
+
+	// InvalidLine is returned for phone numbers that can't receive codes.
+	InvalidLine Code = "invalid_line"
+
+	// UnsupportedRegion is returned for phone numbers of regions that Ding
+	// doesn't deliver to.
+	UnsupportedRegion Code = "unsupported_region"
+
+	// InvalidAuthUUID is returned when the authentication UUID is unknown.
+	InvalidAuthUUID Code = "invalid_auth_uuid"
+)
+
+// Known reports whether c is one of the codes defined by this package.
+func (c Code) Known() bool {
+	switch c {
+	case InternalServerError, BadRequest, InvalidPhoneNumber, AccountInvalid,
+		NegativeBalance, InvalidLine, UnsupportedRegion, InvalidAuthUUID:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,14 @@
+package code
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKnown(t *testing.T) {
+	assert.True(t, NegativeBalance.Known())
+	assert.True(t, InvalidAuthUUID.Known())
+	assert.False(t, Code("quota_exceeded").Known())
+	assert.False(t, Code("").Known())
+}
@@ -0,0 +1,393 @@
+// Package dingtest provides a fake Ding API server that can be used to test
+// code built on top of the Ding client without reaching the real API.
+package dingtest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"github.com/ding-live/ding-go/internal/api"
+	"github.com/ding-live/ding-go/pkg/code"
+	"github.com/ding-live/ding-go/pkg/status"
+	"github.com/google/uuid"
+)
+
+const (
+	// CustomerUUID is the only customer UUID accepted by the fake server.
+	CustomerUUID = "a74ee547-564d-487a-91df-37fb25413a91"
+
+	// APIKey is the only API key accepted by the fake server.
+	APIKey = "dingtest_api_key"
+
+	// Code is the code that the fake server sends for every authentication,
+	// unless a Scenario sets another one.
+	Code = "1234"
+)
+
+const (
+	authenticationTTL = 5 * time.Minute
+	retryCooldown     = 30 * time.Second
+	maxRetries        = 3
+)
+
+// Server is a fake Ding API server. It keeps authentications in memory and
+// implements the same endpoints and error envelopes as the real API.
+type Server struct {
+	// URL is the base URL of the server, suitable for ding.Config.BaseURL.
+	URL string
+
+	srv     *httptest.Server
+	handler http.Handler
+
+	mu        sync.Mutex
+	auths     map[string]*authentication
+	lastAuth  string
+	scenarios map[string]Scenario
+}
+
+// Scenario scripts the behavior of the server for a phone number.
+type Scenario struct {
+	// Error makes authentications fail with this error code of the Ding
+	// API, such as "invalid_phone_number", "negative_balance" or
+	// "unsupported_region".
+	Error string `json:"error,omitempty"`
+
+	// Status is the status of the authentications created for the phone
+	// number, such as "rate_limited" or "spam_detected".
+	//
+	// Defaults to "pending".
+	Status status.Auth `json:"status,omitempty"`
+
+	// Code is the code that is valid for the phone number.
+	//
+	// Defaults to Code.
+	Code string `json:"code,omitempty"`
+
+	// Channel is the channel through which codes are delivered, unless the
+	// authentication requests other channels.
+	//
+	// Defaults to "sms".
+	Channel string `json:"channel,omitempty"`
+}
+
+type authentication struct {
+	uuid           string
+	phoneNumber    string
+	status         status.Auth
+	channel        string
+	code           string
+	createdAt      time.Time
+	expiresAt      time.Time
+	remainingRetry int
+}
+
+// NewServer starts and returns a new fake server. The caller should call
+// Close when finished, to shut it down.
+func NewServer() *Server {
+	s := NewHandler()
+
+	s.srv = httptest.NewServer(s)
+	s.URL = s.srv.URL
+
+	return s
+}
+
+// NewHandler returns a new fake server that doesn't listen by itself, to serve
+// it with your own http.Server. Its URL is left empty.
+func NewHandler() *Server {
+	s := &Server{
+		auths:     make(map[string]*authentication),
+		scenarios: make(map[string]Scenario),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/authentication", s.handleAuthentication)
+	mux.HandleFunc("/check", s.handleCheck)
+	mux.HandleFunc("/retry", s.handleRetry)
+	mux.HandleFunc("/status", s.handleStatus)
+
+	s.handler = s.authorize(mux)
+
+	return s
+}
+
+// ServeHTTP serves the endpoints of the Ding API.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.handler.ServeHTTP(w, r)
+}
+
+// Close shuts down the server.
+func (s *Server) Close() {
+	if s.srv != nil {
+		s.srv.Close()
+	}
+}
+
+// SetScenario scripts the behavior of the server for the authentications of
+// phoneNumber, in E.164 format.
+func (s *Server) SetScenario(phoneNumber string, sc Scenario) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.scenarios[phoneNumber] = sc
+}
+
+// LastAuthenticationUUID returns the UUID of the last authentication created on
+// the server, or an empty string if there is none.
+func (s *Server) LastAuthenticationUUID() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.lastAuth
+}
+
+// SetStatus changes the status of an authentication, as if it had evolved on
+// the Ding side. It reports whether the authentication exists.
+func (s *Server) SetStatus(authUUID string, st status.Auth) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a, ok := s.auths[authUUID]
+	if ok {
+		a.status = st
+	}
+
+	return ok
+}
+
+// ----------------------------------------------------------------------------
+
+func (s *Server) handleAuthentication(w http.ResponseWriter, r *http.Request) {
+	var req api.AuthRequest
+	if !decode(w, r, &req) {
+		return
+	}
+
+	if req.CustomerUUID != CustomerUUID {
+		writeError(w, http.StatusBadRequest, code.AccountInvalid, "unknown customer")
+		return
+	}
+
+	if req.PhoneNumber == "" {
+		writeError(w, http.StatusBadRequest, code.InvalidPhoneNumber, "missing phone number")
+		return
+	}
+
+	s.mu.Lock()
+	sc := s.scenarios[req.PhoneNumber]
+	s.mu.Unlock()
+
+	if sc.Error != "" {
+		writeError(w, http.StatusBadRequest, code.Code(sc.Error), "scripted error")
+		return
+	}
+
+	now := time.Now().UTC()
+	a := &authentication{
+		uuid:           uuid.New().String(),
+		phoneNumber:    req.PhoneNumber,
+		status:         status.AuthPending,
+		channel:        "sms",
+		code:           Code,
+		createdAt:      now,
+		expiresAt:      now.Add(authenticationTTL),
+		remainingRetry: maxRetries,
+	}
+
+	if sc.Status != "" {
+		a.status = sc.Status
+	}
+
+	if sc.Code != "" {
+		a.code = sc.Code
+	}
+
+	if sc.Channel != "" {
+		a.channel = sc.Channel
+	}
+
+	// The fake server always delivers through the first channel requested.
+	if len(req.Channels) > 0 {
+		a.channel = req.Channels[0]
+	}
+
+	// The response is built while the lock is held, since SetStatus may
+	// update the authentication as soon as it is stored.
+	s.mu.Lock()
+	s.auths[a.uuid] = a
+	s.lastAuth = a.uuid
+	res := api.AuthSuccessResponse{
+		AuthenticationUUID: a.uuid,
+		Status:             a.status,
+		CreatedAt:          a.createdAt,
+		ExpiresAt:          a.expiresAt,
+		Channel:            a.channel,
+	}
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (s *Server) handleCheck(w http.ResponseWriter, r *http.Request) {
+	var req api.CheckRequest
+	if !decode(w, r, &req) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a, ok := s.lookup(w, req.CustomerUUID, req.AuthenticationUUID)
+	if !ok {
+		return
+	}
+
+	var st status.Check
+	switch {
+	case a.status == status.AuthApproved:
+		st = status.CheckAlreadyValidated
+	case time.Now().After(a.expiresAt):
+		a.status = status.AuthExpired
+		st = status.CheckExpiredAuth
+	case req.CheckCode == a.code:
+		a.status = status.AuthApproved
+		st = status.CheckValid
+	default:
+		st = status.CheckInvalid
+	}
+
+	writeJSON(w, http.StatusOK, api.CheckSuccessResponse{
+		AuthenticationUUID: a.uuid,
+		Status:             st,
+	})
+}
+
+func (s *Server) handleRetry(w http.ResponseWriter, r *http.Request) {
+	var req api.RetryRequest
+	if !decode(w, r, &req) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a, ok := s.lookup(w, req.CustomerUUID, req.AuthenticationUUID)
+	if !ok {
+		return
+	}
+
+	now := time.Now().UTC()
+
+	var st status.Retry
+	switch {
+	case a.status == status.AuthApproved:
+		st = status.RetryAlreadyValidated
+	case now.After(a.expiresAt):
+		a.status = status.AuthExpired
+		st = status.RetryExpiredAuth
+	case a.remainingRetry == 0:
+		st = status.RetryDenied
+	default:
+		a.remainingRetry--
+		st = status.RetryApproved
+	}
+
+	writeJSON(w, http.StatusOK, api.RetrySuccessResponse{
+		AuthenticationUUID: a.uuid,
+		Status:             st,
+		CreatedAt:          now,
+		NextRetryAt:        now.Add(retryCooldown),
+		RemainingRetry:     a.remainingRetry,
+	})
+}
+
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	var req api.StatusRequest
+	if !decode(w, r, &req) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a, ok := s.lookup(w, req.CustomerUUID, req.AuthenticationUUID)
+	if !ok {
+		return
+	}
+
+	if a.status == status.AuthPending && time.Now().After(a.expiresAt) {
+		a.status = status.AuthExpired
+	}
+
+	writeJSON(w, http.StatusOK, api.AuthSuccessResponse{
+		AuthenticationUUID: a.uuid,
+		Status:             a.status,
+		CreatedAt:          a.createdAt,
+		ExpiresAt:          a.expiresAt,
+		Channel:            a.channel,
+	})
+}
+
+// lookup returns the authentication identified by authUUID, writing an error
+// response if it does not exist. The caller must hold s.mu.
+func (s *Server) lookup(w http.ResponseWriter, customerUUID, authUUID string) (*authentication, bool) {
+	if customerUUID != CustomerUUID {
+		writeError(w, http.StatusBadRequest, code.AccountInvalid, "unknown customer")
+		return nil, false
+	}
+
+	a, ok := s.auths[authUUID]
+	if !ok {
+		writeError(w, http.StatusBadRequest, code.InvalidAuthUUID, "unknown authentication")
+		return nil, false
+	}
+
+	return a, true
+}
+
+// ----------------------------------------------------------------------------
+
+func (s *Server) authorize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(api.APIKeyHeader) != APIKey {
+			writeJSON(w, http.StatusForbidden, api.GatewayErrorMessage{
+				Message: "Forbidden",
+			})
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func decode(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, code.BadRequest, "method not allowed")
+		return false
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, code.BadRequest, err.Error())
+		return false
+	}
+
+	return true
+}
+
+func writeError(w http.ResponseWriter, statusCode int, c code.Code, msg string) {
+	writeJSON(w, statusCode, api.ErrorResponse{
+		Code:    c,
+		Message: msg,
+		DocURL:  "https://docs.ding.live/api/error-handling#" + string(c),
+	})
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, v interface{}) {
+	w.Header().Set(api.RequestIDHeader, uuid.NewString())
+	w.Header().Set("content-type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(v)
+}
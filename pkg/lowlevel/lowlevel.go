@@ -0,0 +1,127 @@
+// Package lowlevel sends raw requests to the Ding API for callers who need
+// full control over the payloads, such as to set fields the ding package
+// doesn't expose or to read the error envelope of the API as is.
+//
+// A Client is obtained from a configured ding.Client with its LowLevel method,
+// so that requests are sent with its API keys, retries, hooks and logger:
+//
+//	ll := client.LowLevel()
+//	res, err := ll.Authentication(ctx, lowlevel.AuthRequest{
+//		CustomerUUID: customerUUID,
+//		PhoneNumber:  "+14155552671",
+//	})
+//	switch {
+//	case err != nil:
+//		// The API couldn't be reached or answered with an invalid response.
+//	case res.Error != nil:
+//		// The API rejected the request: see res.Error.Code.
+//	default:
+//		// res.Success holds the authentication.
+//	}
+//
+// Unlike the ding package, this package doesn't validate requests, map the
+// error codes of the API to errors, nor deduplicate or cache requests.
+package lowlevel
+
+import (
+	"context"
+
+	"github.com/ding-live/ding-go/internal/api"
+)
+
+// The request and response bodies of the endpoints of the Ding API.
+type (
+	AuthRequest          = api.AuthRequest
+	AuthSuccessResponse  = api.AuthSuccessResponse
+	CheckRequest         = api.CheckRequest
+	CheckSuccessResponse = api.CheckSuccessResponse
+	RetryRequest         = api.RetryRequest
+	RetrySuccessResponse = api.RetrySuccessResponse
+	StatusRequest        = api.StatusRequest
+
+	// ErrorResponse is the error envelope of the API, returned with a
+	// non-2XX status.
+	ErrorResponse = api.ErrorResponse
+)
+
+// The responses of the endpoints: exactly one of their Error and Success
+// fields is set. Their RequestID, Deprecation and ClockOffset fields are taken
+// from the headers of the response, and Raw holds the fields of a successful
+// response that are not known to this package.
+type (
+	AuthenticationResponse = api.AuthenticationResponse
+	CheckResponse          = api.CheckResponse
+	RetryResponse          = api.RetryResponse
+	StatusResponse         = api.StatusResponse
+
+	// Response is returned by Do, whose successful response is decoded
+	// into the value given by the caller instead of Success.
+	Response = api.Response[struct{}]
+)
+
+var (
+	// ErrInternal is returned when the API answers with an unexpected
+	// response, such as a body that can't be decoded.
+	ErrInternal = api.ErrInternal
+
+	// ErrUnauthorized is returned when the API key is rejected.
+	ErrUnauthorized = api.ErrUnauthorized
+
+	// ErrUnreachable is returned when the API can't be reached, once the
+	// retries are exhausted.
+	ErrUnreachable = api.ErrUnreachable
+)
+
+type (
+	// ResponseError wraps the errors that occurred once a response was
+	// received, along with its request ID.
+	ResponseError = api.ResponseError
+
+	// UnexpectedResponseError is returned for responses whose body isn't
+	// the JSON expected from the API. It wraps ErrInternal.
+	UnexpectedResponseError = api.UnexpectedResponseError
+
+	// PanicError is returned when a hook or the transport panics.
+	PanicError = api.PanicError
+)
+
+// ----------------------------------------------------------------------------
+
+// Client sends raw requests to the Ding API.
+type Client struct {
+	api *api.API
+}
+
+// New returns a Client sending its requests through a. It is meant to be
+// called by ding.Client.LowLevel, use that instead.
+func New(a *api.API) *Client {
+	return &Client{api: a}
+}
+
+// Authentication sends an OTP code to the phone number of req.
+func (c *Client) Authentication(ctx context.Context, req AuthRequest) (*AuthenticationResponse, error) {
+	return c.api.Authentication(ctx, req)
+}
+
+// Check checks the code of an authentication.
+func (c *Client) Check(ctx context.Context, req CheckRequest) (*CheckResponse, error) {
+	return c.api.Check(ctx, req)
+}
+
+// Retry sends a new code for an authentication.
+func (c *Client) Retry(ctx context.Context, req RetryRequest) (*RetryResponse, error) {
+	return c.api.Retry(ctx, req)
+}
+
+// Status returns the status of an authentication.
+func (c *Client) Status(ctx context.Context, req StatusRequest) (*StatusResponse, error) {
+	return c.api.Status(ctx, req)
+}
+
+// Do sends a request to an arbitrary endpoint, such as one added to the API
+// since. payload is encoded as the JSON body of the request unless nil, and a
+// successful response is decoded into out unless nil. Error responses are
+// decoded as the Error of the returned Response.
+func (c *Client) Do(ctx context.Context, method, path string, payload, out interface{}) (*Response, error) {
+	return c.api.Do(ctx, method, path, payload, out)
+}
@@ -0,0 +1,88 @@
+package lowlevel_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/ding-live/ding-go"
+	"github.com/ding-live/ding-go/pkg/code"
+	"github.com/ding-live/ding-go/pkg/dingtest"
+	"github.com/ding-live/ding-go/pkg/lowlevel"
+	"github.com/ding-live/ding-go/pkg/status"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestClient(t *testing.T, srv *dingtest.Server, apiKey string) *lowlevel.Client {
+	client, err := ding.NewClient(ding.Config{
+		CustomerUUID:      dingtest.CustomerUUID,
+		APIKey:            apiKey,
+		BaseURL:           srv.URL,
+		MaxNetworkRetries: ding.Int(0),
+	})
+	require.NoError(t, err)
+
+	return client.LowLevel()
+}
+
+func TestClient(t *testing.T) {
+	srv := dingtest.NewServer()
+	defer srv.Close()
+
+	srv.SetScenario("+33600000000", dingtest.Scenario{Error: string(code.UnsupportedRegion)})
+
+	client := newTestClient(t, srv, dingtest.APIKey)
+	ctx := context.Background()
+
+	auth, err := client.Authentication(ctx, lowlevel.AuthRequest{
+		CustomerUUID: dingtest.CustomerUUID,
+		PhoneNumber:  "+14155552671",
+		Channels:     []string{"whatsapp"},
+	})
+	require.NoError(t, err)
+	require.Nil(t, auth.Error)
+	assert.Equal(t, "whatsapp", auth.Success.Channel)
+
+	check, err := client.Check(ctx, lowlevel.CheckRequest{
+		CustomerUUID:       dingtest.CustomerUUID,
+		AuthenticationUUID: auth.Success.AuthenticationUUID,
+		CheckCode:          dingtest.Code,
+	})
+	require.NoError(t, err)
+	require.Nil(t, check.Error)
+	assert.Equal(t, status.CheckValid, check.Success.Status)
+
+	// The error envelope of the API is returned as is.
+	auth, err = client.Authentication(ctx, lowlevel.AuthRequest{
+		CustomerUUID: dingtest.CustomerUUID,
+		PhoneNumber:  "+33600000000",
+	})
+	require.NoError(t, err)
+	require.Nil(t, auth.Success)
+	assert.Equal(t, code.UnsupportedRegion, auth.Error.Code)
+
+	var out struct {
+		Status status.Auth `json:"status"`
+	}
+	res, err := client.Do(ctx, http.MethodPost, "status", lowlevel.StatusRequest{
+		CustomerUUID:       dingtest.CustomerUUID,
+		AuthenticationUUID: check.Success.AuthenticationUUID,
+	}, &out)
+	require.NoError(t, err)
+	require.Nil(t, res.Error)
+	assert.Equal(t, status.AuthApproved, out.Status)
+}
+
+func TestClientUnauthorized(t *testing.T) {
+	srv := dingtest.NewServer()
+	defer srv.Close()
+
+	client := newTestClient(t, srv, "invalid")
+
+	_, err := client.Authentication(context.Background(), lowlevel.AuthRequest{
+		CustomerUUID: dingtest.CustomerUUID,
+		PhoneNumber:  "+14155552671",
+	})
+	require.ErrorIs(t, err, lowlevel.ErrUnauthorized)
+}
@@ -0,0 +1,169 @@
+// Package money models amounts of money in minor units, such as cents, so that
+// costs can be added up without the rounding errors of float64. It is meant for
+// the billing data of the Ding API, which the client doesn't expose yet, and
+// for the cost reports built on top of it.
+package money
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+	ErrInvalidAmount    = errors.New("invalid amount")
+	ErrInvalidCurrency  = errors.New("invalid currency")
+)
+
+// exponents lists the currencies whose minor unit isn't a hundredth of the
+// major one, as defined by ISO 4217.
+var exponents = map[string]int{
+	"BHD": 3,
+	"BIF": 0,
+	"CLP": 0,
+	"DJF": 0,
+	"GNF": 0,
+	"IQD": 3,
+	"ISK": 0,
+	"JOD": 3,
+	"JPY": 0,
+	"KMF": 0,
+	"KRW": 0,
+	"KWD": 3,
+	"LYD": 3,
+	"OMR": 3,
+	"PYG": 0,
+	"RWF": 0,
+	"TND": 3,
+	"UGX": 0,
+	"VND": 0,
+	"VUV": 0,
+	"XAF": 0,
+	"XOF": 0,
+	"XPF": 0,
+}
+
+// Exponent returns the number of decimals of currency, such as 2 for "EUR" and
+// 0 for "JPY".
+func Exponent(currency string) int {
+	if e, ok := exponents[currency]; ok {
+		return e
+	}
+
+	return 2
+}
+
+// Money is an amount in the minor unit of an ISO 4217 currency, such as
+// {1234, "EUR"} for 12.34 €.
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+// New returns amount minor units of currency.
+func New(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// Parse parses a decimal amount of currency, such as "12.34". It returns
+// ErrInvalidAmount if s has more decimals than the currency.
+func Parse(s, currency string) (Money, error) {
+	if !isCurrency(currency) {
+		return Money{}, ErrInvalidCurrency
+	}
+
+	exp := Exponent(currency)
+
+	neg := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+
+	whole, frac, hasFrac := strings.Cut(s, ".")
+	if whole == "" || hasFrac && (frac == "" || len(frac) > exp) {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidAmount, s)
+	}
+
+	digits := whole + frac + strings.Repeat("0", exp-len(frac))
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			return Money{}, fmt.Errorf("%w: %q", ErrInvalidAmount, s)
+		}
+	}
+
+	amount, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidAmount, s)
+	}
+
+	if neg {
+		amount = -amount
+	}
+
+	return Money{Amount: amount, Currency: currency}, nil
+}
+
+// Add returns m + o, or ErrCurrencyMismatch if they are in different
+// currencies.
+func (m Money) Add(o Money) (Money, error) {
+	if m.Currency != o.Currency {
+		return Money{}, fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, o.Currency)
+	}
+
+	return Money{Amount: m.Amount + o.Amount, Currency: m.Currency}, nil
+}
+
+// Sub returns m - o, or ErrCurrencyMismatch if they are in different
+// currencies.
+func (m Money) Sub(o Money) (Money, error) {
+	return m.Add(Money{Amount: -o.Amount, Currency: o.Currency})
+}
+
+// Mul returns m multiplied by n, such as the price of n codes.
+func (m Money) Mul(n int64) Money {
+	return Money{Amount: m.Amount * n, Currency: m.Currency}
+}
+
+// Decimal returns the amount in major units with the decimals of the
+// currency, such as "12.34".
+func (m Money) Decimal() string {
+	exp := Exponent(m.Currency)
+
+	amount := m.Amount
+	sign := ""
+
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	s := strconv.FormatInt(amount, 10)
+	if exp == 0 {
+		return sign + s
+	}
+
+	if len(s) <= exp {
+		s = strings.Repeat("0", exp-len(s)+1) + s
+	}
+
+	return sign + s[:len(s)-exp] + "." + s[len(s)-exp:]
+}
+
+// String returns the amount followed by its currency, such as "12.34 EUR".
+func (m Money) String() string {
+	return m.Decimal() + " " + m.Currency
+}
+
+func isCurrency(s string) bool {
+	if len(s) != 3 {
+		return false
+	}
+
+	for _, r := range s {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+
+	return true
+}
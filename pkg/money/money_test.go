@@ -0,0 +1,62 @@
+package money
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	for _, tt := range []struct {
+		s        string
+		currency string
+		want     Money
+	}{
+		{"12.34", "EUR", New(1234, "EUR")},
+		{"12.3", "EUR", New(1230, "EUR")},
+		{"12", "EUR", New(1200, "EUR")},
+		{"-0.05", "USD", New(-5, "USD")},
+		{"1500", "JPY", New(1500, "JPY")},
+		{"1.234", "KWD", New(1234, "KWD")},
+	} {
+		got, err := Parse(tt.s, tt.currency)
+		require.NoError(t, err, tt.s)
+		assert.Equal(t, tt.want, got, tt.s)
+	}
+
+	for _, s := range []string{"", "1.", ".5", "1.234", "1,5", "abc", "1e3"} {
+		_, err := Parse(s, "EUR")
+		assert.ErrorIs(t, err, ErrInvalidAmount, s)
+	}
+
+	_, err := Parse("1.5", "JPY")
+	assert.ErrorIs(t, err, ErrInvalidAmount)
+
+	_, err = Parse("1", "eur")
+	assert.ErrorIs(t, err, ErrInvalidCurrency)
+}
+
+func TestString(t *testing.T) {
+	assert.Equal(t, "12.34 EUR", New(1234, "EUR").String())
+	assert.Equal(t, "0.05 USD", New(5, "USD").String())
+	assert.Equal(t, "-0.05 USD", New(-5, "USD").String())
+	assert.Equal(t, "1500 JPY", New(1500, "JPY").String())
+	assert.Equal(t, "0.001 KWD", New(1, "KWD").String())
+}
+
+func TestArithmetic(t *testing.T) {
+	// 0.1 + 0.2 is exactly 0.3.
+	sum, err := New(10, "EUR").Add(New(20, "EUR"))
+	require.NoError(t, err)
+	assert.Equal(t, New(30, "EUR"), sum)
+
+	diff, err := New(10, "EUR").Sub(New(20, "EUR"))
+	require.NoError(t, err)
+	assert.Equal(t, New(-10, "EUR"), diff)
+
+	assert.Equal(t, New(4500, "USD"), New(45, "USD").Mul(100))
+
+	_, err = New(10, "EUR").Add(New(10, "USD"))
+	assert.ErrorIs(t, err, ErrCurrencyMismatch)
+}
@@ -0,0 +1,335 @@
+// Package session ties your users to Ding authentications. It keeps track of
+// the authentication in progress for every user, enforces resend cooldowns and
+// a maximum number of code attempts locally, and exposes the three operations
+// every OTP flow needs: Start, Resend and Verify.
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	ding "github.com/ding-live/ding-go"
+	"github.com/ding-live/ding-go/pkg/status"
+)
+
+var (
+	ErrNotFound         = errors.New("no verification in progress")
+	ErrCooldown         = errors.New("a code was sent too recently")
+	ErrTooManyAttempts  = errors.New("too many attempts")
+	ErrExpired          = errors.New("verification expired")
+	ErrAlreadyVerified  = errors.New("verification already completed")
+	ErrResendDenied     = errors.New("no more codes can be sent")
+	ErrMissingClient    = errors.New("missing Ding client")
+	ErrMissingUserID    = errors.New("missing user ID")
+	ErrInvalidAttempts  = errors.New("maximum number of attempts must be positive")
+	ErrUnexpectedStatus = errors.New("unexpected status")
+)
+
+const (
+	defaultResendCooldown = 30 * time.Second
+	defaultMaxAttempts    = 5
+)
+
+// Client is the subset of *ding.Client used by the Manager.
+type Client interface {
+	AuthenticateWithContext(ctx context.Context, opt ding.AuthenticateOptions) (*ding.Authentication, error)
+	CheckWithContext(ctx context.Context, authUUID string, code string) (*ding.Check, error)
+	RetryWithContext(ctx context.Context, authUUID string) (*ding.Retry, error)
+}
+
+// Session is the verification in progress for a given user.
+type Session struct {
+	UserID             string
+	PhoneNumber        string
+	AuthenticationUUID string
+	ExpiresAt          time.Time
+
+	// LastSentAt is the last time a code was sent to the user.
+	LastSentAt time.Time
+
+	// ResendAvailableAt is the earliest time at which Resend will be accepted.
+	ResendAvailableAt time.Time
+
+	// Attempts is the number of codes checked so far.
+	Attempts int
+
+	// MaxAttempts is the number of codes the user can submit, set by Start.
+	// Sessions stored by earlier versions of the package have none, and use
+	// Config.MaxAttempts.
+	MaxAttempts int
+}
+
+// RemainingAttempts returns the number of codes the user can still submit, for
+// instance to warn them after a wrong code.
+func (s *Session) RemainingAttempts() int {
+	if n := s.MaxAttempts - s.Attempts; n > 0 {
+		return n
+	}
+
+	return 0
+}
+
+// StartOption customizes a single verification started with Manager.Start.
+type StartOption func(*Session)
+
+// WithMaxAttempts overrides Config.MaxAttempts for the verification, such as to
+// allow fewer attempts for sensitive operations. Start returns
+// ErrInvalidAttempts, without sending a code, if n is not positive.
+func WithMaxAttempts(n int) StartOption {
+	return func(s *Session) {
+		s.MaxAttempts = n
+	}
+}
+
+// Config is the configuration required to instanciate a new Manager.
+type Config struct {
+	// Client is the Ding client used to send and check codes.
+	Client Client
+
+	// Store persists sessions between calls.
+	//
+	// Defaults to a new MemoryStore.
+	Store Store
+
+	// ResendCooldown is the minimum delay between two codes sent to the same
+	// user.
+	//
+	// Defaults to 30 seconds.
+	ResendCooldown time.Duration
+
+	// MaxAttempts is the maximum number of codes a user can submit for a
+	// single verification. NewManager returns ErrInvalidAttempts if it is
+	// negative.
+	//
+	// Defaults to 5.
+	MaxAttempts int
+}
+
+// Manager handles OTP verifications for your users.
+type Manager struct {
+	client         Client
+	store          Store
+	resendCooldown time.Duration
+	maxAttempts    int
+	now            func() time.Time
+}
+
+// NewManager returns a new Manager with a `Config` object.
+func NewManager(cfg Config) (*Manager, error) {
+	if cfg.Client == nil {
+		return nil, ErrMissingClient
+	}
+
+	if cfg.MaxAttempts < 0 {
+		return nil, ErrInvalidAttempts
+	}
+
+	m := &Manager{
+		client:         cfg.Client,
+		store:          cfg.Store,
+		resendCooldown: cfg.ResendCooldown,
+		maxAttempts:    cfg.MaxAttempts,
+		now:            time.Now,
+	}
+
+	if m.store == nil {
+		m.store = NewMemoryStore()
+	}
+
+	if m.resendCooldown == 0 {
+		m.resendCooldown = defaultResendCooldown
+	}
+
+	if m.maxAttempts == 0 {
+		m.maxAttempts = defaultMaxAttempts
+	}
+
+	return m, nil
+}
+
+// Start sends a code to the phone number in opt and attaches the resulting
+// authentication to userID, replacing any verification in progress. It returns
+// ErrCooldown if a code was sent to the user less than ResendCooldown ago.
+func (m *Manager) Start(ctx context.Context, userID string, opt ding.AuthenticateOptions, opts ...StartOption) (*Session, error) {
+	if userID == "" {
+		return nil, ErrMissingUserID
+	}
+
+	now := m.now()
+
+	prev, err := m.get(ctx, userID)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+
+	if prev != nil && now.Before(prev.ResendAvailableAt) && now.Before(prev.ExpiresAt) {
+		return prev, ErrCooldown
+	}
+
+	s := &Session{
+		UserID:      userID,
+		MaxAttempts: m.maxAttempts,
+	}
+
+	for _, o := range opts {
+		o(s)
+	}
+
+	if s.MaxAttempts <= 0 {
+		return nil, ErrInvalidAttempts
+	}
+
+	auth, err := m.client.AuthenticateWithContext(ctx, opt)
+	if err != nil {
+		return nil, err
+	}
+
+	if auth.Status != status.AuthPending {
+		return nil, fmt.Errorf("%w: %s", ErrUnexpectedStatus, auth.Status)
+	}
+
+	s.PhoneNumber = auth.PhoneNumber.E164
+	s.AuthenticationUUID = auth.AuthenticationUUID
+	s.ExpiresAt = auth.ExpiresAt
+	s.LastSentAt = now
+	s.ResendAvailableAt = now.Add(m.resendCooldown)
+
+	if err := m.store.Put(ctx, s); err != nil {
+		return nil, fmt.Errorf("store session: %w", err)
+	}
+
+	return s, nil
+}
+
+// Resend sends a new code for the verification in progress for userID.
+func (m *Manager) Resend(ctx context.Context, userID string) (*Session, error) {
+	s, err := m.get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := m.now()
+
+	if !now.Before(s.ExpiresAt) {
+		return nil, m.end(ctx, userID, ErrExpired)
+	}
+
+	if now.Before(s.ResendAvailableAt) {
+		return s, ErrCooldown
+	}
+
+	retry, err := m.client.RetryWithContext(ctx, s.AuthenticationUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch retry.Status {
+	case status.RetryApproved:
+		s.LastSentAt = now
+		s.ResendAvailableAt = now.Add(m.resendCooldown)
+	case status.RetryRateLimited:
+		return s, ErrCooldown
+	case status.RetryExpiredAuth:
+		return nil, m.end(ctx, userID, ErrExpired)
+	case status.RetryAlreadyValidated:
+		return nil, m.end(ctx, userID, ErrAlreadyVerified)
+	case status.RetryDenied, status.RetryNoAttempt:
+		return s, ErrResendDenied
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnexpectedStatus, retry.Status)
+	}
+
+	if err := m.store.Put(ctx, s); err != nil {
+		return nil, fmt.Errorf("store session: %w", err)
+	}
+
+	return s, nil
+}
+
+// Verify checks the code submitted by userID. It returns true if the code is
+// valid, in which case the session is removed from the store. A wrong code
+// returns false and a nil error until MaxAttempts is reached; use Get to tell
+// the user how many attempts remain.
+func (m *Manager) Verify(ctx context.Context, userID string, code string) (bool, error) {
+	s, err := m.get(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+
+	if !m.now().Before(s.ExpiresAt) {
+		return false, m.end(ctx, userID, ErrExpired)
+	}
+
+	limit := s.MaxAttempts
+	if limit == 0 {
+		limit = m.maxAttempts
+	}
+
+	// The attempt is recorded atomically before reaching the API so that
+	// concurrent or failing calls can't be used to get around MaxAttempts.
+	s, err = m.store.AddAttempt(ctx, userID, limit)
+	if err != nil {
+		if errors.Is(err, ErrTooManyAttempts) || errors.Is(err, ErrNotFound) {
+			return false, err
+		}
+
+		return false, fmt.Errorf("store session: %w", err)
+	}
+
+	check, err := m.client.CheckWithContext(ctx, s.AuthenticationUUID, code)
+	if err != nil {
+		return false, err
+	}
+
+	switch check.Status {
+	case status.CheckValid:
+		return true, m.end(ctx, userID, nil)
+	case status.CheckInvalid:
+		return false, nil
+	case status.CheckRateLimited:
+		return false, ErrTooManyAttempts
+	case status.CheckExpiredAuth:
+		return false, m.end(ctx, userID, ErrExpired)
+	case status.CheckAlreadyValidated:
+		return false, m.end(ctx, userID, ErrAlreadyVerified)
+	default:
+		return false, fmt.Errorf("%w: %s", ErrUnexpectedStatus, check.Status)
+	}
+}
+
+// Get returns the verification in progress for userID, or ErrNotFound.
+func (m *Manager) Get(ctx context.Context, userID string) (*Session, error) {
+	return m.get(ctx, userID)
+}
+
+// Cancel removes the verification in progress for userID, if any.
+func (m *Manager) Cancel(ctx context.Context, userID string) error {
+	return m.end(ctx, userID, nil)
+}
+
+// ----------------------------------------------------------------------------
+
+func (m *Manager) get(ctx context.Context, userID string) (*Session, error) {
+	s, err := m.store.Get(ctx, userID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, err
+		}
+
+		return nil, fmt.Errorf("load session: %w", err)
+	}
+
+	return s, nil
+}
+
+// end removes the session of userID from the store and returns reason, or the
+// store error if the session could not be removed.
+func (m *Manager) end(ctx context.Context, userID string, reason error) error {
+	if err := m.store.Delete(ctx, userID); err != nil {
+		return fmt.Errorf("delete session: %w", err)
+	}
+
+	return reason
+}
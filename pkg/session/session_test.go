@@ -0,0 +1,180 @@
+package session
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	ding "github.com/ding-live/ding-go"
+	"github.com/ding-live/ding-go/pkg/dingtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testPhoneNumber = "+33612345678"
+
+func newTestManager(t *testing.T, cfg Config) *Manager {
+	srv := dingtest.NewServer()
+	t.Cleanup(srv.Close)
+
+	client, err := ding.NewClient(ding.Config{
+		CustomerUUID: dingtest.CustomerUUID,
+		APIKey:       dingtest.APIKey,
+		BaseURL:      srv.URL,
+	})
+	require.NoError(t, err)
+
+	cfg.Client = client
+
+	m, err := NewManager(cfg)
+	require.NoError(t, err)
+
+	return m
+}
+
+func TestVerify(t *testing.T) {
+	ctx := context.Background()
+	m := newTestManager(t, Config{})
+
+	_, err := m.Start(ctx, "user", ding.AuthenticateOptions{PhoneNumber: testPhoneNumber})
+	require.NoError(t, err)
+
+	ok, err := m.Verify(ctx, "user", "0000")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	ok, err = m.Verify(ctx, "user", dingtest.Code)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	_, err = m.Verify(ctx, "user", dingtest.Code)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestVerifyMaxAttempts(t *testing.T) {
+	ctx := context.Background()
+	m := newTestManager(t, Config{MaxAttempts: 2})
+
+	_, err := m.Start(ctx, "user", ding.AuthenticateOptions{PhoneNumber: testPhoneNumber})
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		ok, err := m.Verify(ctx, "user", "0000")
+		require.NoError(t, err)
+		assert.False(t, ok)
+	}
+
+	_, err = m.Verify(ctx, "user", dingtest.Code)
+	assert.ErrorIs(t, err, ErrTooManyAttempts)
+}
+
+func TestVerifyConcurrentAttempts(t *testing.T) {
+	ctx := context.Background()
+	m := newTestManager(t, Config{MaxAttempts: 3})
+
+	_, err := m.Start(ctx, "user", ding.AuthenticateOptions{PhoneNumber: testPhoneNumber})
+	require.NoError(t, err)
+
+	var (
+		wg      sync.WaitGroup
+		checked int32
+	)
+
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			if _, err := m.Verify(ctx, "user", "0000"); err == nil {
+				atomic.AddInt32(&checked, 1)
+			} else {
+				assert.ErrorIs(t, err, ErrTooManyAttempts)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	assert.EqualValues(t, 3, checked)
+
+	s, err := m.Get(ctx, "user")
+	require.NoError(t, err)
+	assert.Equal(t, 3, s.Attempts)
+}
+
+func TestStartWithMaxAttempts(t *testing.T) {
+	ctx := context.Background()
+	m := newTestManager(t, Config{})
+
+	s, err := m.Start(ctx, "user", ding.AuthenticateOptions{PhoneNumber: testPhoneNumber}, WithMaxAttempts(2))
+	require.NoError(t, err)
+	assert.Equal(t, 2, s.RemainingAttempts())
+
+	ok, err := m.Verify(ctx, "user", "0000")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	s, err = m.Get(ctx, "user")
+	require.NoError(t, err)
+	assert.Equal(t, 1, s.RemainingAttempts())
+
+	ok, err = m.Verify(ctx, "user", "0000")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	_, err = m.Verify(ctx, "user", dingtest.Code)
+	assert.ErrorIs(t, err, ErrTooManyAttempts)
+
+	_, err = m.Get(ctx, "nobody")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestInvalidMaxAttempts(t *testing.T) {
+	ctx := context.Background()
+	m := newTestManager(t, Config{})
+
+	for _, n := range []int{0, -1} {
+		_, err := m.Start(ctx, "user", ding.AuthenticateOptions{PhoneNumber: testPhoneNumber}, WithMaxAttempts(n))
+		assert.ErrorIs(t, err, ErrInvalidAttempts)
+	}
+
+	// No code was sent.
+	_, err := m.Get(ctx, "user")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, err = NewManager(Config{Client: m.client, MaxAttempts: -1})
+	assert.ErrorIs(t, err, ErrInvalidAttempts)
+}
+
+func TestResendCooldown(t *testing.T) {
+	ctx := context.Background()
+	m := newTestManager(t, Config{ResendCooldown: time.Minute})
+
+	now := time.Now()
+	m.now = func() time.Time { return now }
+
+	_, err := m.Start(ctx, "user", ding.AuthenticateOptions{PhoneNumber: testPhoneNumber})
+	require.NoError(t, err)
+
+	_, err = m.Resend(ctx, "user")
+	assert.ErrorIs(t, err, ErrCooldown)
+
+	_, err = m.Start(ctx, "user", ding.AuthenticateOptions{PhoneNumber: testPhoneNumber})
+	assert.ErrorIs(t, err, ErrCooldown)
+
+	now = now.Add(time.Minute)
+
+	s, err := m.Resend(ctx, "user")
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(time.Minute), s.ResendAvailableAt)
+}
+
+func TestResendUnknownUser(t *testing.T) {
+	m := newTestManager(t, Config{})
+
+	_, err := m.Resend(context.Background(), "unknown")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
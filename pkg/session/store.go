@@ -0,0 +1,91 @@
+package session
+
+import (
+	"context"
+	"sync"
+)
+
+// Store persists sessions. Implementations must be safe for concurrent use
+// and must return ErrNotFound from Get and AddAttempt when there is no session
+// for a user.
+type Store interface {
+	Get(ctx context.Context, userID string) (*Session, error)
+	Put(ctx context.Context, s *Session) error
+	Delete(ctx context.Context, userID string) error
+
+	// AddAttempt increments the Attempts of the session of userID and
+	// returns the updated session, or ErrTooManyAttempts if it already
+	// reached limit. The check and the increment must be atomic, such as with
+	// a transaction or a compare-and-swap, so that concurrent calls can't
+	// exceed limit.
+	AddAttempt(ctx context.Context, userID string, limit int) (*Session, error)
+}
+
+// MemoryStore is a Store keeping sessions in memory. It is suitable for
+// single-instance deployments and tests.
+type MemoryStore struct {
+	mu       sync.Mutex
+	sessions map[string]Session
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		sessions: make(map[string]Session),
+	}
+}
+
+// Get returns the session of userID.
+func (m *MemoryStore) Get(_ context.Context, userID string) (*Session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, ok := m.sessions[userID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	return &s, nil
+}
+
+// Put stores s, replacing any previous session of the same user.
+func (m *MemoryStore) Put(_ context.Context, s *Session) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.sessions[s.UserID] = *s
+
+	return nil
+}
+
+// Delete removes the session of userID. It is not an error to delete a session
+// that does not exist.
+func (m *MemoryStore) Delete(_ context.Context, userID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.sessions, userID)
+
+	return nil
+}
+
+// AddAttempt increments the Attempts of the session of userID, unless it
+// already reached limit.
+func (m *MemoryStore) AddAttempt(_ context.Context, userID string, limit int) (*Session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, ok := m.sessions[userID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	if s.Attempts >= limit {
+		return nil, ErrTooManyAttempts
+	}
+
+	s.Attempts++
+	m.sessions[userID] = s
+
+	return &s, nil
+}
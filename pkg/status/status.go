@@ -2,9 +2,42 @@ package status
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"sync"
 )
 
+// ErrUnknown is returned when parsing a status that is not known to the SDK.
+var ErrUnknown = errors.New("unknown status")
+
+// registry is a set of known status values.
+type registry struct {
+	mu     sync.RWMutex
+	values map[string]bool
+}
+
+func newRegistry(values ...string) *registry {
+	r := &registry{values: make(map[string]bool)}
+	r.add(values...)
+	return r
+}
+
+func (r *registry) add(values ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, v := range values {
+		r.values[v] = true
+	}
+}
+
+func (r *registry) has(v string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.values[v]
+}
+
 type Check string
 
 const (
@@ -18,6 +51,55 @@ const (
 	CheckExpiredAuth      Check = "expired_auth"
 )
 
+var checks = newRegistry("valid", "invalid", "without_attempt", "rate_limited", "already_validated", "expired_auth")
+
+// RegisterCheck adds statuses introduced by the API after this version of the SDK
+// to the ones accepted by ParseCheck.
+func RegisterCheck(values ...Check) {
+	for _, v := range values {
+		checks.add(string(v))
+	}
+}
+
+// ParseCheck returns the Check status represented by s. If s is not a known
+// status, it is returned as is along with an error wrapping ErrUnknown, so that
+// the raw value remains available through UnknownValue. An empty s is parsed
+// as CheckUnknown.
+func ParseCheck(s string) (Check, error) {
+	if checks.has(s) {
+		return Check(s), nil
+	}
+
+	if s == "" {
+		return CheckUnknown, fmt.Errorf("%w: empty status", ErrUnknown)
+	}
+
+	return Check(s), fmt.Errorf("%w: %q", ErrUnknown, s)
+}
+
+// Known reports whether d is a built-in status or one added with RegisterCheck.
+func (d Check) Known() bool {
+	return checks.has(string(d))
+}
+
+// UnknownValue returns the raw status received from the API when it is not
+// known to the SDK, and an empty string otherwise.
+func (d Check) UnknownValue() string {
+	if d == CheckUnknown || d.Known() {
+		return ""
+	}
+
+	return string(d)
+}
+
+func (d Check) String() string {
+	return string(d)
+}
+
+func (d Check) MarshalJSON() ([]byte, error) {
+	return json.Marshal(string(d))
+}
+
 func (d *Check) UnmarshalJSON(b []byte) error {
 	var res string
 	err := json.Unmarshal(b, &res)
@@ -25,14 +107,37 @@ func (d *Check) UnmarshalJSON(b []byte) error {
 		return fmt.Errorf("unmarshal Check: %w", err)
 	}
 
-	switch res {
-	case "valid", "invalid", "without_attempt", "rate_limited", "already_validated", "expired_auth":
-		*d = Check(res)
+	// Statuses introduced after this version of the SDK are kept as is rather
+	// than failing the whole response.
+	*d, _ = ParseCheck(res)
+
+	return nil
+}
+
+// IsTerminal reports whether the authentication will not change anymore after
+// a check returned this status.
+func (d Check) IsTerminal() bool {
+	switch d {
+	case CheckValid, CheckAlreadyValidated, CheckExpiredAuth, CheckWithoutAttempt:
+		return true
 	default:
-		*d = CheckUnknown
+		return false
 	}
+}
 
-	return nil
+// IsSuccess reports whether the code was valid.
+func (d Check) IsSuccess() bool {
+	return d == CheckValid
+}
+
+// IsRetryable reports whether checking a code again later may succeed.
+func (d Check) IsRetryable() bool {
+	switch d {
+	case CheckInvalid, CheckRateLimited:
+		return true
+	default:
+		return false
+	}
 }
 
 // ----------------------------------------------------------------------------
@@ -50,6 +155,55 @@ const (
 	AuthExpired      Auth = "expired"
 )
 
+var auths = newRegistry("pending", "rate_limited", "spam_detected", "approved", "canceled", "expired")
+
+// RegisterAuth adds statuses introduced by the API after this version of the SDK
+// to the ones accepted by ParseAuth.
+func RegisterAuth(values ...Auth) {
+	for _, v := range values {
+		auths.add(string(v))
+	}
+}
+
+// ParseAuth returns the Auth status represented by s. If s is not a known
+// status, it is returned as is along with an error wrapping ErrUnknown, so that
+// the raw value remains available through UnknownValue. An empty s is parsed
+// as AuthUnknown.
+func ParseAuth(s string) (Auth, error) {
+	if auths.has(s) {
+		return Auth(s), nil
+	}
+
+	if s == "" {
+		return AuthUnknown, fmt.Errorf("%w: empty status", ErrUnknown)
+	}
+
+	return Auth(s), fmt.Errorf("%w: %q", ErrUnknown, s)
+}
+
+// Known reports whether d is a built-in status or one added with RegisterAuth.
+func (d Auth) Known() bool {
+	return auths.has(string(d))
+}
+
+// UnknownValue returns the raw status received from the API when it is not
+// known to the SDK, and an empty string otherwise.
+func (d Auth) UnknownValue() string {
+	if d == AuthUnknown || d.Known() {
+		return ""
+	}
+
+	return string(d)
+}
+
+func (d Auth) String() string {
+	return string(d)
+}
+
+func (d Auth) MarshalJSON() ([]byte, error) {
+	return json.Marshal(string(d))
+}
+
 func (d *Auth) UnmarshalJSON(b []byte) error {
 	var res string
 	err := json.Unmarshal(b, &res)
@@ -57,14 +211,32 @@ func (d *Auth) UnmarshalJSON(b []byte) error {
 		return fmt.Errorf("unmarshal Auth: %w", err)
 	}
 
-	switch res {
-	case "pending", "rate_limited", "spam_detected", "approved", "canceled", "expired":
-		*d = Auth(res)
+	// Statuses introduced after this version of the SDK are kept as is rather
+	// than failing the whole response.
+	*d, _ = ParseAuth(res)
+
+	return nil
+}
+
+// IsTerminal reports whether an authentication in this status will not change
+// anymore.
+func (d Auth) IsTerminal() bool {
+	switch d {
+	case AuthApproved, AuthCanceled, AuthExpired, AuthSpamDetected, AuthRateLimited:
+		return true
 	default:
-		*d = AuthUnknown
+		return false
 	}
+}
 
-	return nil
+// IsSuccess reports whether the user completed the authentication.
+func (d Auth) IsSuccess() bool {
+	return d == AuthApproved
+}
+
+// IsRetryable reports whether authenticating again later may succeed.
+func (d Auth) IsRetryable() bool {
+	return d == AuthRateLimited
 }
 
 // ----------------------------------------------------------------------------
@@ -82,6 +254,55 @@ const (
 	RetryAlreadyValidated Retry = "already_validated"
 )
 
+var retries = newRegistry("approved", "denied", "no_attempt", "rate_limited", "expired_auth", "already_validated")
+
+// RegisterRetry adds statuses introduced by the API after this version of the SDK
+// to the ones accepted by ParseRetry.
+func RegisterRetry(values ...Retry) {
+	for _, v := range values {
+		retries.add(string(v))
+	}
+}
+
+// ParseRetry returns the Retry status represented by s. If s is not a known
+// status, it is returned as is along with an error wrapping ErrUnknown, so that
+// the raw value remains available through UnknownValue. An empty s is parsed
+// as RetryUnknown.
+func ParseRetry(s string) (Retry, error) {
+	if retries.has(s) {
+		return Retry(s), nil
+	}
+
+	if s == "" {
+		return RetryUnknown, fmt.Errorf("%w: empty status", ErrUnknown)
+	}
+
+	return Retry(s), fmt.Errorf("%w: %q", ErrUnknown, s)
+}
+
+// Known reports whether d is a built-in status or one added with RegisterRetry.
+func (d Retry) Known() bool {
+	return retries.has(string(d))
+}
+
+// UnknownValue returns the raw status received from the API when it is not
+// known to the SDK, and an empty string otherwise.
+func (d Retry) UnknownValue() string {
+	if d == RetryUnknown || d.Known() {
+		return ""
+	}
+
+	return string(d)
+}
+
+func (d Retry) String() string {
+	return string(d)
+}
+
+func (d Retry) MarshalJSON() ([]byte, error) {
+	return json.Marshal(string(d))
+}
+
 func (d *Retry) UnmarshalJSON(b []byte) error {
 	var res string
 	err := json.Unmarshal(b, &res)
@@ -89,12 +310,30 @@ func (d *Retry) UnmarshalJSON(b []byte) error {
 		return fmt.Errorf("unmarshal Retry: %w", err)
 	}
 
-	switch res {
-	case "approved", "denied", "no_attempt", "rate_limited", "expired_auth", "already_validated":
-		*d = Retry(res)
+	// Statuses introduced after this version of the SDK are kept as is rather
+	// than failing the whole response.
+	*d, _ = ParseRetry(res)
+
+	return nil
+}
+
+// IsTerminal reports whether no more codes can be sent for the authentication
+// after a retry returned this status.
+func (d Retry) IsTerminal() bool {
+	switch d {
+	case RetryDenied, RetryNoAttempt, RetryExpiredAuth, RetryAlreadyValidated:
+		return true
 	default:
-		*d = RetryUnknown
+		return false
 	}
+}
 
-	return nil
+// IsSuccess reports whether a new code was sent.
+func (d Retry) IsSuccess() bool {
+	return d == RetryApproved
+}
+
+// IsRetryable reports whether retrying again later may succeed.
+func (d Retry) IsRetryable() bool {
+	return d == RetryRateLimited
 }
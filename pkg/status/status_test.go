@@ -0,0 +1,113 @@
+package status
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthHelpers(t *testing.T) {
+	assert.False(t, AuthPending.IsTerminal())
+	assert.False(t, AuthUnknown.IsTerminal())
+	assert.True(t, AuthApproved.IsTerminal())
+	assert.True(t, AuthSpamDetected.IsTerminal())
+
+	assert.True(t, AuthApproved.IsSuccess())
+	assert.False(t, AuthExpired.IsSuccess())
+
+	assert.True(t, AuthRateLimited.IsRetryable())
+	assert.False(t, AuthSpamDetected.IsRetryable())
+}
+
+func TestCheckHelpers(t *testing.T) {
+	assert.False(t, CheckInvalid.IsTerminal())
+	assert.True(t, CheckValid.IsTerminal())
+	assert.True(t, CheckExpiredAuth.IsTerminal())
+
+	assert.True(t, CheckValid.IsSuccess())
+	assert.False(t, CheckAlreadyValidated.IsSuccess())
+
+	assert.True(t, CheckInvalid.IsRetryable())
+	assert.False(t, CheckExpiredAuth.IsRetryable())
+}
+
+func TestRetryHelpers(t *testing.T) {
+	assert.False(t, RetryApproved.IsTerminal())
+	assert.True(t, RetryDenied.IsTerminal())
+
+	assert.True(t, RetryApproved.IsSuccess())
+	assert.False(t, RetryRateLimited.IsSuccess())
+
+	assert.True(t, RetryRateLimited.IsRetryable())
+	assert.False(t, RetryUnknown.IsRetryable())
+}
+
+func TestParse(t *testing.T) {
+	a, err := ParseAuth("approved")
+	require.NoError(t, err)
+	assert.Equal(t, AuthApproved, a)
+
+	a, err = ParseAuth("not_a_status")
+	require.ErrorIs(t, err, ErrUnknown)
+	assert.Equal(t, Auth("not_a_status"), a)
+
+	a, err = ParseAuth("")
+	require.ErrorIs(t, err, ErrUnknown)
+	assert.Equal(t, AuthUnknown, a)
+
+	c, err := ParseCheck("valid")
+	require.NoError(t, err)
+	assert.Equal(t, CheckValid, c)
+
+	r, err := ParseRetry("no_attempt")
+	require.NoError(t, err)
+	assert.Equal(t, RetryNoAttempt, r)
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	type payload struct {
+		Auth  Auth  `json:"auth"`
+		Check Check `json:"check"`
+		Retry Retry `json:"retry"`
+	}
+
+	in := payload{
+		Auth:  AuthSpamDetected,
+		Check: CheckExpiredAuth,
+		Retry: RetryDenied,
+	}
+
+	b, err := json.Marshal(in)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"auth": "spam_detected", "check": "expired_auth", "retry": "denied"}`, string(b))
+
+	var out payload
+	require.NoError(t, json.Unmarshal(b, &out))
+	assert.Equal(t, in, out)
+
+	assert.Equal(t, "spam_detected", AuthSpamDetected.String())
+}
+
+func TestUnknownValue(t *testing.T) {
+	var c Check
+	require.NoError(t, json.Unmarshal([]byte(`"code_reused"`), &c))
+
+	assert.False(t, c.Known())
+	assert.Equal(t, "code_reused", c.UnknownValue())
+	assert.Equal(t, "", CheckValid.UnknownValue())
+	assert.Equal(t, "", CheckUnknown.UnknownValue())
+}
+
+func TestRegister(t *testing.T) {
+	_, err := ParseRetry("queued")
+	require.ErrorIs(t, err, ErrUnknown)
+
+	RegisterRetry("queued")
+
+	r, err := ParseRetry("queued")
+	require.NoError(t, err)
+	assert.True(t, r.Known())
+	assert.Equal(t, "", r.UnknownValue())
+}
@@ -0,0 +1,243 @@
+// Package stepup gates routes on a recent OTP verification. Once
+// session.Manager.Verify accepts the code of a user, Issue hands them a signed
+// token, as a cookie, that Require checks on the routes that need step-up
+// verification.
+//
+// Require is a standard net/http middleware, so it can be used as is with
+// net/http and chi:
+//
+//	r.With(guard.Require).Post("/transfers", createTransfer)
+//
+// and with echo through echo.WrapMiddleware:
+//
+//	e.POST("/transfers", createTransfer, echo.WrapMiddleware(guard.Require))
+//
+// Other frameworks can call Verified, for instance with gin:
+//
+//	func stepUp(guard *stepup.Guard) gin.HandlerFunc {
+//		return func(c *gin.Context) {
+//			if !guard.Verified(c.Request) {
+//				c.AbortWithStatus(http.StatusForbidden)
+//				return
+//			}
+//			c.Next()
+//		}
+//	}
+//
+// Tokens are of the form
+//
+//	1700000000.YWxpY2U.3yL2CfbQ3G8Rrm8Cj4QoTCeF7qUpfDIjeSz1MrfSN1o
+//
+// where the first part is the Unix time of the verification, the second one is
+// the base64url-encoded user ID and the last one is the base64url-encoded
+// HMAC-SHA256 of the first two parts, keyed with Config.Secret.
+package stepup
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultMaxAge     = 15 * time.Minute
+	defaultCookieName = "ding_stepup"
+	minSecretSize     = 32
+)
+
+// Header is the header from which tokens are read when the cookie is not set,
+// for clients that don't use cookies.
+const Header = "X-Step-Up-Token"
+
+var (
+	ErrMissingUserID = errors.New("missing user ID function")
+	ErrWeakSecret    = errors.New("secret must be at least 32 bytes long")
+	ErrInvalidToken  = errors.New("invalid step-up token")
+	ErrExpiredToken  = errors.New("step-up token expired")
+)
+
+// Config is the configuration required to instanciate a new Guard.
+type Config struct {
+	// Secret is the key used to sign tokens. It must be at least 32 bytes
+	// long, and shared by all the instances of your app.
+	Secret []byte
+
+	// UserID returns the ID of the user signed in on r, or an empty string if
+	// there is none. Tokens are only accepted for the user they were issued
+	// to.
+	UserID func(r *http.Request) string
+
+	// MaxAge is how long a verification is valid for.
+	//
+	// Defaults to 15 minutes.
+	MaxAge time.Duration
+
+	// CookieName is the name of the cookie holding the token.
+	//
+	// Defaults to "ding_stepup".
+	CookieName string
+
+	// Unverified handles the requests rejected by Require.
+	//
+	// Defaults to replying with 403 Forbidden.
+	Unverified http.Handler
+}
+
+// Guard issues and checks step-up tokens.
+type Guard struct {
+	secret     []byte
+	userID     func(r *http.Request) string
+	maxAge     time.Duration
+	cookieName string
+	unverified http.Handler
+	now        func() time.Time
+}
+
+// New returns a new Guard with a `Config` object.
+func New(cfg Config) (*Guard, error) {
+	if len(cfg.Secret) < minSecretSize {
+		return nil, ErrWeakSecret
+	}
+
+	if cfg.UserID == nil {
+		return nil, ErrMissingUserID
+	}
+
+	g := &Guard{
+		secret:     cfg.Secret,
+		userID:     cfg.UserID,
+		maxAge:     cfg.MaxAge,
+		cookieName: cfg.CookieName,
+		unverified: cfg.Unverified,
+		now:        time.Now,
+	}
+
+	if g.maxAge == 0 {
+		g.maxAge = defaultMaxAge
+	}
+
+	if g.cookieName == "" {
+		g.cookieName = defaultCookieName
+	}
+
+	if g.unverified == nil {
+		g.unverified = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "verification required", http.StatusForbidden)
+		})
+	}
+
+	return g, nil
+}
+
+// Token returns a token stating that userID was verified now.
+func (g *Guard) Token(userID string) string {
+	payload := strconv.FormatInt(g.now().Unix(), 10) + "." + base64.RawURLEncoding.EncodeToString([]byte(userID))
+
+	return payload + "." + base64.RawURLEncoding.EncodeToString(g.sign(payload))
+}
+
+// Issue sets the cookie stating that userID was verified now. Call it once
+// session.Manager.Verify accepted the code of the user.
+func (g *Guard) Issue(w http.ResponseWriter, userID string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     g.cookieName,
+		Value:    g.Token(userID),
+		Path:     "/",
+		MaxAge:   int(g.maxAge / time.Second),
+		Secure:   true,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// Clear removes the cookie set by Issue, for instance when the user signs out.
+func (g *Guard) Clear(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     g.cookieName,
+		Path:     "/",
+		MaxAge:   -1,
+		Secure:   true,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// Check returns the ID of the user that token was issued to, or an error if
+// it is invalid or older than MaxAge.
+func (g *Guard) Check(token string) (string, error) {
+	i := strings.LastIndexByte(token, '.')
+	if i < 0 {
+		return "", ErrInvalidToken
+	}
+
+	payload := token[:i]
+
+	mac, err := base64.RawURLEncoding.DecodeString(token[i+1:])
+	if err != nil || !hmac.Equal(mac, g.sign(payload)) {
+		return "", ErrInvalidToken
+	}
+
+	ts, user, ok := strings.Cut(payload, ".")
+	if !ok {
+		return "", ErrInvalidToken
+	}
+
+	sec, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return "", ErrInvalidToken
+	}
+
+	if g.now().Sub(time.Unix(sec, 0)) > g.maxAge {
+		return "", ErrExpiredToken
+	}
+
+	userID, err := base64.RawURLEncoding.DecodeString(user)
+	if err != nil {
+		return "", ErrInvalidToken
+	}
+
+	return string(userID), nil
+}
+
+// Verified reports whether the request carries a valid token, from the cookie
+// or the Header, issued to the user signed in on r.
+func (g *Guard) Verified(r *http.Request) bool {
+	userID := g.userID(r)
+	if userID == "" {
+		return false
+	}
+
+	token := r.Header.Get(Header)
+	if c, err := r.Cookie(g.cookieName); err == nil {
+		token = c.Value
+	}
+
+	tokenUserID, err := g.Check(token)
+
+	return err == nil && hmac.Equal([]byte(tokenUserID), []byte(userID))
+}
+
+// Require only lets verified requests through to next. Others are handled by
+// Config.Unverified.
+func (g *Guard) Require(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !g.Verified(r) {
+			g.unverified.ServeHTTP(w, r)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (g *Guard) sign(payload string) []byte {
+	h := hmac.New(sha256.New, g.secret)
+	h.Write([]byte(payload))
+
+	return h.Sum(nil)
+}
@@ -0,0 +1,102 @@
+package stepup
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var secret = []byte("0123456789abcdef0123456789abcdef")
+
+func newTestGuard(t *testing.T) *Guard {
+	g, err := New(Config{
+		Secret: secret,
+		UserID: func(r *http.Request) string { return r.Header.Get("X-User-ID") },
+	})
+	require.NoError(t, err)
+
+	return g
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(Config{Secret: []byte("short"), UserID: func(*http.Request) string { return "" }})
+	assert.ErrorIs(t, err, ErrWeakSecret)
+
+	_, err = New(Config{Secret: secret})
+	assert.ErrorIs(t, err, ErrMissingUserID)
+}
+
+func TestCheck(t *testing.T) {
+	g := newTestGuard(t)
+
+	userID, err := g.Check(g.Token("alice.smith@example.com"))
+	require.NoError(t, err)
+	assert.Equal(t, "alice.smith@example.com", userID)
+
+	for _, token := range []string{
+		"",
+		"garbage",
+		g.Token("alice") + "x",
+		"1700000000.YWxpY2U.3yL2CfbQ3G8Rrm8Cj4QoTCeF7qUpfDIjeSz1MrfSN1o",
+	} {
+		_, err := g.Check(token)
+		assert.ErrorIs(t, err, ErrInvalidToken, token)
+	}
+
+	other, err := New(Config{Secret: []byte("fedcba9876543210fedcba9876543210"), UserID: g.userID})
+	require.NoError(t, err)
+
+	_, err = other.Check(g.Token("alice"))
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	token := g.Token("alice")
+	g.now = func() time.Time { return time.Now().Add(defaultMaxAge + time.Second) }
+
+	_, err = g.Check(token)
+	assert.ErrorIs(t, err, ErrExpiredToken)
+}
+
+func TestRequire(t *testing.T) {
+	g := newTestGuard(t)
+
+	h := g.Require(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	serve := func(userID string, cookies []*http.Cookie, header string) int {
+		r := httptest.NewRequest(http.MethodPost, "/transfers", nil)
+		r.Header.Set("X-User-ID", userID)
+		r.Header.Set(Header, header)
+		for _, c := range cookies {
+			r.AddCookie(c)
+		}
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		return w.Code
+	}
+
+	w := httptest.NewRecorder()
+	g.Issue(w, "alice")
+	cookies := w.Result().Cookies()
+	require.Len(t, cookies, 1)
+	assert.True(t, cookies[0].HttpOnly)
+	assert.True(t, cookies[0].Secure)
+
+	assert.Equal(t, http.StatusNoContent, serve("alice", cookies, ""))
+	assert.Equal(t, http.StatusNoContent, serve("alice", nil, g.Token("alice")))
+
+	// Tokens are bound to the user they were issued to.
+	assert.Equal(t, http.StatusForbidden, serve("bob", cookies, ""))
+	assert.Equal(t, http.StatusForbidden, serve("", cookies, ""))
+	assert.Equal(t, http.StatusForbidden, serve("alice", nil, ""))
+
+	w = httptest.NewRecorder()
+	g.Clear(w)
+	assert.Equal(t, -1, w.Result().Cookies()[0].MaxAge)
+}
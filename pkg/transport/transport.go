@@ -0,0 +1,90 @@
+// Package transport defines how the Ding client exchanges requests with the
+// Ding API. The client uses HTTP by default; implement Transport to route
+// requests through another backend, such as a gateway listening on a unix
+// socket, a sidecar or a test double.
+package transport
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+)
+
+// Request is a request to the Ding API.
+type Request struct {
+	Method string
+
+	// URL is the absolute URL of the endpoint, built from Config.BaseURL.
+	URL string
+
+	// Header holds the headers of the request, API key included.
+	Header http.Header
+
+	// Body is the JSON encoded body of the request, or nil.
+	Body []byte
+}
+
+// Response is a response of the Ding API. The client closes Body once it is
+// done with it.
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Body       io.ReadCloser
+}
+
+// Transport sends requests to the Ding API. An error must only be returned if
+// no response was obtained; error statuses are reported through the Response.
+// Implementations must be safe for concurrent use.
+type Transport interface {
+	Do(ctx context.Context, req Request) (Response, error)
+}
+
+// Func is an adapter to use ordinary functions as Transports.
+type Func func(ctx context.Context, req Request) (Response, error)
+
+// Do calls f(ctx, req).
+func (f Func) Do(ctx context.Context, req Request) (Response, error) {
+	return f(ctx, req)
+}
+
+// HTTP is a Transport sending requests with an *http.Client.
+type HTTP struct {
+	// Client is the HTTP client used to send requests.
+	//
+	// Defaults to http.DefaultClient.
+	Client *http.Client
+}
+
+// Do sends req over HTTP.
+func (t HTTP) Do(ctx context.Context, req Request) (Response, error) {
+	var body io.Reader
+	if req.Body != nil {
+		body = bytes.NewReader(req.Body)
+	}
+
+	r, err := http.NewRequestWithContext(ctx, req.Method, req.URL, body)
+	if err != nil {
+		return Response{}, err
+	}
+
+	for k, v := range req.Header {
+		r.Header[k] = v
+	}
+
+	client := t.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	res, err := client.Do(r)
+	if err != nil {
+		return Response{}, err
+	}
+
+	return Response{
+		StatusCode: res.StatusCode,
+		Header:     res.Header,
+		Body:       res.Body,
+	}, nil
+}
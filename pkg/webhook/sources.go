@@ -0,0 +1,247 @@
+package webhook
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultRefreshInterval is the interval at which Sources are refreshed by
+// Run unless SourcesConfig.RefreshInterval is set.
+const DefaultRefreshInterval = time.Hour
+
+// maxSourcesSize bounds the size of the lists of IP ranges.
+const maxSourcesSize = 1 << 20
+
+var (
+	ErrMissingSourcesURL = errors.New("missing sources URL")
+	ErrEmptySources      = errors.New("empty list of sources")
+	ErrSourcesTooLarge   = errors.New("list of sources too large")
+)
+
+// SourcesConfig configures Sources.
+type SourcesConfig struct {
+	// URL serves the IP ranges that callbacks are sent from, as one IP
+	// address or CIDR prefix per line. Empty lines and lines starting with #
+	// are ignored.
+	URL string
+
+	// Prefixes are the IP ranges known before the first refresh, such as a
+	// copy of the list shipped with your service, so that callbacks are
+	// accepted even if URL can't be reached at startup.
+	Prefixes []netip.Prefix
+
+	// RefreshInterval is the interval at which Run refreshes the list.
+	//
+	// Defaults to DefaultRefreshInterval.
+	RefreshInterval time.Duration
+
+	// HTTPClient is used to fetch the list.
+	//
+	// Defaults to http.DefaultClient.
+	HTTPClient *http.Client
+
+	// OnError is called when Run fails to refresh the list, in which case
+	// the previous list is kept.
+	OnError func(error)
+}
+
+// Sources is a list of the IP ranges that callbacks are sent from, kept up to
+// date from a URL, to reject spoofed callbacks before checking their
+// signature, for instance at the edge of your network.
+//
+// The SDK doesn't embed a list of ranges: Ding doesn't document a stable set
+// of egress addresses, so the list is loaded from the URL given by your Ding
+// account manager or from one you host. Source checks complement signatures,
+// which remain the only proof that a callback comes from Ding.
+type Sources struct {
+	url      string
+	interval time.Duration
+	hc       *http.Client
+	onError  func(error)
+
+	mu       sync.RWMutex
+	prefixes []netip.Prefix
+}
+
+// NewSources returns Sources loading their list from cfg.URL. The list only
+// holds cfg.Prefixes until Refresh or Run is called.
+func NewSources(cfg SourcesConfig) (*Sources, error) {
+	if cfg.URL == "" {
+		return nil, ErrMissingSourcesURL
+	}
+
+	s := &Sources{
+		url:      cfg.URL,
+		interval: cfg.RefreshInterval,
+		hc:       cfg.HTTPClient,
+		onError:  cfg.OnError,
+		prefixes: append([]netip.Prefix(nil), cfg.Prefixes...),
+	}
+
+	if s.interval <= 0 {
+		s.interval = DefaultRefreshInterval
+	}
+
+	if s.hc == nil {
+		s.hc = http.DefaultClient
+	}
+
+	return s, nil
+}
+
+// IsKnownSource reports whether ip belongs to one of the ranges of s.
+func (s *Sources) IsKnownSource(ip netip.Addr) bool {
+	ip = ip.Unmap()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, p := range s.prefixes {
+		if p.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Refresh replaces the list of s with the one served at its URL. The list is
+// kept as is if it can't be fetched or parsed, or if it is empty or larger
+// than 1 MB, in which case ErrSourcesTooLarge is returned.
+func (s *Sources) Refresh(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return fmt.Errorf("fetch sources: %w", err)
+	}
+
+	res, err := s.hc.Do(req)
+	if err != nil {
+		return fmt.Errorf("fetch sources: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("fetch sources: unexpected status %s", res.Status)
+	}
+
+	// A truncated list could end with a shortened range, such as
+	// "203.0.113.0/2" for "203.0.113.0/24", which would widen the list.
+	body, err := io.ReadAll(io.LimitReader(res.Body, maxSourcesSize+1))
+	if err != nil {
+		return fmt.Errorf("fetch sources: %w", err)
+	}
+
+	if len(body) > maxSourcesSize {
+		return ErrSourcesTooLarge
+	}
+
+	prefixes, err := ParseSources(bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.prefixes = prefixes
+	s.mu.Unlock()
+
+	return nil
+}
+
+// Run refreshes s right away, then at its refresh interval until ctx is done.
+func (s *Sources) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.Refresh(ctx); err != nil && s.onError != nil && ctx.Err() == nil {
+			s.onError(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Middleware rejects with 403 Forbidden the requests whose remote address
+// doesn't belong to s. Behind a proxy, the remote address is the one of the
+// proxy: call IsKnownSource with the client address it forwards instead.
+func (s *Sources) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			host = r.RemoteAddr
+		}
+
+		ip, err := netip.ParseAddr(host)
+		if err != nil || !s.IsKnownSource(ip) {
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ParseSources reads a list of IP ranges in the format served at
+// SourcesConfig.URL.
+func ParseSources(r io.Reader) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		p, err := parseSource(line)
+		if err != nil {
+			return nil, fmt.Errorf("parse sources: %w", err)
+		}
+
+		prefixes = append(prefixes, p)
+	}
+
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("parse sources: %w", err)
+	}
+
+	if len(prefixes) == 0 {
+		return nil, ErrEmptySources
+	}
+
+	return prefixes, nil
+}
+
+func parseSource(s string) (netip.Prefix, error) {
+	if strings.Contains(s, "/") {
+		p, err := netip.ParsePrefix(s)
+		if err != nil {
+			return netip.Prefix{}, err
+		}
+
+		return p.Masked(), nil
+	}
+
+	ip, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+
+	ip = ip.Unmap()
+
+	return netip.PrefixFrom(ip, ip.BitLen()), nil
+}
@@ -0,0 +1,144 @@
+package webhook
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSources(t *testing.T) {
+	prefixes, err := ParseSources(strings.NewReader(`
+# Ding callbacks
+192.0.2.0/24
+198.51.100.7
+2001:db8::/32
+203.0.113.9/16
+`))
+	require.NoError(t, err)
+
+	assert.Equal(t, []netip.Prefix{
+		netip.MustParsePrefix("192.0.2.0/24"),
+		netip.MustParsePrefix("198.51.100.7/32"),
+		netip.MustParsePrefix("2001:db8::/32"),
+		netip.MustParsePrefix("203.0.0.0/16"),
+	}, prefixes)
+
+	_, err = ParseSources(strings.NewReader("# nothing\n"))
+	assert.ErrorIs(t, err, ErrEmptySources)
+
+	_, err = ParseSources(strings.NewReader("192.0.2.0/24\nnot an IP\n"))
+	assert.Error(t, err)
+}
+
+func TestSources(t *testing.T) {
+	var list atomic.Value
+	list.Store("192.0.2.0/24\n")
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(list.Load().(string)))
+	}))
+	defer srv.Close()
+
+	_, err := NewSources(SourcesConfig{})
+	assert.ErrorIs(t, err, ErrMissingSourcesURL)
+
+	s, err := NewSources(SourcesConfig{
+		URL:      srv.URL,
+		Prefixes: []netip.Prefix{netip.MustParsePrefix("198.51.100.0/24")},
+	})
+	require.NoError(t, err)
+
+	assert.True(t, s.IsKnownSource(netip.MustParseAddr("198.51.100.1")))
+	assert.False(t, s.IsKnownSource(netip.MustParseAddr("192.0.2.1")))
+
+	require.NoError(t, s.Refresh(context.Background()))
+
+	assert.False(t, s.IsKnownSource(netip.MustParseAddr("198.51.100.1")))
+	assert.True(t, s.IsKnownSource(netip.MustParseAddr("192.0.2.1")))
+	assert.True(t, s.IsKnownSource(netip.MustParseAddr("::ffff:192.0.2.1")))
+
+	// Invalid lists don't replace the current one.
+	list.Store("")
+	assert.ErrorIs(t, s.Refresh(context.Background()), ErrEmptySources)
+	assert.True(t, s.IsKnownSource(netip.MustParseAddr("192.0.2.1")))
+
+	// Nor do lists cut at the size limit, whose last range may be cut too.
+	list.Store(strings.Repeat("# padding\n", maxSourcesSize/10) + "203.0.113.0/24\n")
+	assert.ErrorIs(t, s.Refresh(context.Background()), ErrSourcesTooLarge)
+	assert.False(t, s.IsKnownSource(netip.MustParseAddr("203.0.113.1")))
+	assert.True(t, s.IsKnownSource(netip.MustParseAddr("192.0.2.1")))
+}
+
+func TestSourcesRun(t *testing.T) {
+	var calls int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		_, _ = w.Write([]byte("192.0.2.0/24\n"))
+	}))
+	defer srv.Close()
+
+	errs := make(chan error, 1)
+
+	s, err := NewSources(SourcesConfig{
+		URL:             srv.URL,
+		RefreshInterval: 10 * time.Millisecond,
+		OnError:         func(err error) { errs <- err },
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	go func() {
+		s.Run(ctx)
+		close(done)
+	}()
+
+	assert.Error(t, <-errs)
+	assert.Eventually(t, func() bool {
+		return s.IsKnownSource(netip.MustParseAddr("192.0.2.1"))
+	}, time.Second, 5*time.Millisecond)
+
+	cancel()
+	<-done
+}
+
+func TestSourcesMiddleware(t *testing.T) {
+	s, err := NewSources(SourcesConfig{
+		URL:      "https://example.com/sources.txt",
+		Prefixes: []netip.Prefix{netip.MustParsePrefix("192.0.2.0/24")},
+	})
+	require.NoError(t, err)
+
+	h := s.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	for remoteAddr, code := range map[string]int{
+		"192.0.2.10:4321":    http.StatusNoContent,
+		"198.51.100.1:4321":  http.StatusForbidden,
+		"[2001:db8::1]:4321": http.StatusForbidden,
+		"garbage":            http.StatusForbidden,
+	} {
+		r := httptest.NewRequest(http.MethodPost, "/callbacks/ding", nil)
+		r.RemoteAddr = remoteAddr
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		assert.Equal(t, code, w.Code, remoteAddr)
+	}
+}
@@ -0,0 +1,141 @@
+// Package webhook verifies the signature of the callbacks sent by Ding to the
+// callback URL of an authentication.
+//
+// Signed callbacks carry a SignatureHeader of the form
+//
+//	t=1700000000,v1=5257a869e7ecebeda32affa62cdca3fa51cad7e77a0e56ff536d0ce8e108d8bd
+//
+// where t is the Unix time at which the callback was sent and v1 is the
+// hex-encoded HMAC-SHA256 of t, a dot and the raw request body, keyed with the
+// callback secret of your account.
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ding-live/ding-go/internal/fips"
+)
+
+// SignatureHeader is the header carrying the signature of a callback.
+const SignatureHeader = "x-ding-signature"
+
+// DefaultTolerance is the maximum difference between the timestamp of a
+// callback and the current time accepted by Verify.
+const DefaultTolerance = 5 * time.Minute
+
+var (
+	ErrMissingSignature = errors.New("missing signature")
+	ErrInvalidSignature = errors.New("invalid signature")
+	ErrMissingSecret    = errors.New("missing callback secret")
+
+	// ErrExpiredSignature is returned for signatures whose timestamp is too
+	// far in the past, or in the future, which a replayed or forged callback
+	// may use to outlive the tolerance.
+	ErrExpiredSignature = errors.New("signature timestamp outside of the tolerance")
+
+	// ErrWeakSecret is returned in FIPS 140 mode for callback secrets shorter
+	// than 14 bytes, which FIPS doesn't allow as HMAC keys.
+	ErrWeakSecret = errors.New("callback secret too short for FIPS 140 mode")
+)
+
+// Verify checks that signature, the value of the SignatureHeader of a
+// callback, matches body for secret and has a timestamp less than
+// DefaultTolerance away from the current time.
+func Verify(secret string, body []byte, signature string) error {
+	return VerifyAt(secret, body, signature, time.Now(), DefaultTolerance)
+}
+
+// VerifyAt is like Verify but checks the timestamp of the signature against now
+// and tolerance instead. A zero tolerance disables the check.
+func VerifyAt(secret string, body []byte, signature string, now time.Time, tolerance time.Duration) error {
+	if secret == "" {
+		return ErrMissingSecret
+	}
+
+	if fips.Enabled() && len(secret) < fips.MinKeySize {
+		return ErrWeakSecret
+	}
+
+	if signature == "" {
+		return ErrMissingSignature
+	}
+
+	var (
+		timestamp string
+		macs      [][]byte
+	)
+
+	for _, part := range strings.Split(signature, ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return ErrInvalidSignature
+		}
+
+		switch k {
+		case "t":
+			timestamp = v
+		case "v1":
+			mac, err := hex.DecodeString(v)
+			if err != nil {
+				return ErrInvalidSignature
+			}
+
+			macs = append(macs, mac)
+		}
+	}
+
+	sec, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || len(macs) == 0 {
+		return ErrInvalidSignature
+	}
+
+	expected := sign(secret, timestamp, body)
+
+	valid := false
+	for _, mac := range macs {
+		if hmac.Equal(mac, expected) {
+			valid = true
+		}
+	}
+
+	if !valid {
+		return ErrInvalidSignature
+	}
+
+	if tolerance > 0 {
+		skew := now.Sub(time.Unix(sec, 0))
+		if skew < 0 {
+			skew = -skew
+		}
+
+		if skew > tolerance {
+			return ErrExpiredSignature
+		}
+	}
+
+	return nil
+}
+
+// Sign returns the SignatureHeader value Ding would send for body at t. It is
+// mostly useful to test your callback handlers. In FIPS 140 mode, it panics if
+// secret is shorter than 14 bytes.
+func Sign(secret string, body []byte, t time.Time) string {
+	timestamp := strconv.FormatInt(t.Unix(), 10)
+
+	return "t=" + timestamp + ",v1=" + hex.EncodeToString(sign(secret, timestamp, body))
+}
+
+func sign(secret string, timestamp string, body []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return mac.Sum(nil)
+}
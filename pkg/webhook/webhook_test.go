@@ -0,0 +1,40 @@
+package webhook
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ding-live/ding-go/internal/fips"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerify(t *testing.T) {
+	const secret = "whsec_test_secret"
+
+	body := []byte(`{"authentication_uuid":"a74ee547-564d-487a-91df-37fb25413a91","status":"approved"}`)
+	now := time.Unix(1700000000, 0)
+	sig := Sign(secret, body, now)
+
+	assert.NoError(t, VerifyAt(secret, body, sig, now.Add(time.Minute), DefaultTolerance))
+
+	// Rotated secrets send several signatures.
+	assert.NoError(t, VerifyAt(secret, body, Sign("whsec_old_secret", body, now)+",v1="+sig[len("t=1700000000,v1="):], now, 0))
+
+	assert.ErrorIs(t, VerifyAt(secret, body, sig, now.Add(time.Hour), DefaultTolerance), ErrExpiredSignature)
+	assert.NoError(t, VerifyAt(secret, body, sig, now.Add(-time.Minute), DefaultTolerance))
+	assert.ErrorIs(t, VerifyAt(secret, body, sig, now.Add(-time.Hour), DefaultTolerance), ErrExpiredSignature)
+	assert.ErrorIs(t, VerifyAt(secret, []byte(`{}`), sig, now, 0), ErrInvalidSignature)
+	assert.ErrorIs(t, VerifyAt("whsec_other_secret", body, sig, now, 0), ErrInvalidSignature)
+	assert.ErrorIs(t, VerifyAt(secret, body, "v1=00", now, 0), ErrInvalidSignature)
+	assert.ErrorIs(t, VerifyAt(secret, body, "garbage", now, 0), ErrInvalidSignature)
+	assert.ErrorIs(t, VerifyAt(secret, body, "", now, 0), ErrMissingSignature)
+	assert.ErrorIs(t, VerifyAt("", body, sig, now, 0), ErrMissingSecret)
+}
+
+func TestVerifyWeakSecretFIPS(t *testing.T) {
+	if !fips.Enabled() {
+		t.Skip("FIPS 140 mode is disabled")
+	}
+
+	assert.ErrorIs(t, Verify("short", []byte(`{}`), "t=1700000000,v1=00"), ErrWeakSecret)
+}
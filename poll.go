@@ -0,0 +1,206 @@
+package ding
+
+import (
+	"context"
+	"time"
+
+	"github.com/ding-live/ding-go/pkg/status"
+)
+
+const defaultPollInterval = 2 * time.Second
+
+// PollConfig configures how AuthenticateAndWait, WaitForStatus and
+// StreamStatus poll the status of an authentication.
+type PollConfig struct {
+	// Interval is the delay between two status requests.
+	//
+	// Defaults to 2 seconds.
+	Interval time.Duration
+
+	// Timeout bounds the total time spent waiting. Use the context of the
+	// call for finer control.
+	//
+	// Defaults to no timeout.
+	Timeout time.Duration
+}
+
+// withTimeout returns cfg with its defaults set, and ctx bounded by its
+// Timeout.
+func (cfg PollConfig) withTimeout(ctx context.Context) (PollConfig, context.Context, context.CancelFunc) {
+	if cfg.Interval <= 0 {
+		cfg.Interval = defaultPollInterval
+	}
+
+	if cfg.Timeout <= 0 {
+		return cfg, ctx, func() {}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, cfg.Timeout)
+
+	return cfg, ctx, cancel
+}
+
+// AuthenticateAndWait performs an authentication request and polls its status
+// until it reaches a terminal state, such as approved, expired or
+// spam_detected. It is useful for integrations that don't rely on callbacks.
+//
+// If ctx is done or the poll timeout is reached before that, the last known
+// state of the authentication is returned along with the context error. The
+// same goes for ErrClosed if the client is closed while waiting.
+func (c *Client) AuthenticateAndWait(ctx context.Context, opt AuthenticateOptions, cfg PollConfig) (*Authentication, error) {
+	cfg, ctx, cancel := cfg.withTimeout(ctx)
+	defer cancel()
+
+	auth, err := c.AuthenticateWithContext(ctx, opt)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.wait(ctx, auth, cfg.Interval, nil)
+}
+
+// WaitForStatus polls the status of an authentication until it is one of
+// targets, or until it is terminal if targets is empty. The Ding API has no
+// long-polling or streaming endpoint, so the status is requested every
+// cfg.Interval: use AuthenticateOptions.CallbackURL to be notified instead.
+//
+// It also returns, without error, once the authentication reaches a terminal
+// status, which can't change anymore, so check the status of the returned
+// Authentication. If ctx is done, the poll timeout is reached or the client is
+// closed before that, the last known state of the authentication is returned
+// along with the context error or ErrClosed.
+func (c *Client) WaitForStatus(ctx context.Context, authUUID string, cfg PollConfig, targets ...status.Auth) (*Authentication, error) {
+	cfg, ctx, cancel := cfg.withTimeout(ctx)
+	defer cancel()
+
+	auth, err := c.AuthenticationStatusWithContext(ctx, authUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.wait(ctx, auth, cfg.Interval, targets)
+}
+
+// wait polls the status of auth every interval until it is one of targets or
+// terminal.
+func (c *Client) wait(ctx context.Context, auth *Authentication, interval time.Duration, targets []status.Auth) (*Authentication, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for !auth.Status.IsTerminal() && !hasStatus(targets, auth.Status) {
+		select {
+		case <-ctx.Done():
+			return auth, ctx.Err()
+		case <-c.lifecycle.done:
+			return auth, ErrClosed
+		case <-ticker.C:
+		}
+
+		next, err := c.AuthenticationStatusWithContext(ctx, auth.AuthenticationUUID)
+		if err != nil {
+			if ctx.Err() != nil {
+				return auth, ctx.Err()
+			}
+
+			return auth, err
+		}
+
+		auth = next
+	}
+
+	return auth, nil
+}
+
+func hasStatus(targets []status.Auth, st status.Auth) bool {
+	for _, target := range targets {
+		if st == target {
+			return true
+		}
+	}
+
+	return false
+}
+
+// StatusUpdate is a state of an authentication sent by StreamStatus.
+type StatusUpdate struct {
+	Authentication *Authentication
+
+	// Err is the error that ended the stream, such as ErrClosed, in which
+	// case Authentication is nil.
+	Err error
+}
+
+// StreamStatus sends the state of an authentication on the returned channel,
+// then every change of its status or channel, until it reaches a terminal
+// status. The channel is closed once the authentication is terminal, ctx is
+// done, or after an update holding the error that ended the stream, such as
+// context.DeadlineExceeded once the poll timeout is reached.
+//
+// The Ding API has no streaming endpoint, such as server-sent events or
+// WebSocket, so the status is polled every cfg.Interval.
+func (c *Client) StreamStatus(ctx context.Context, authUUID string, cfg PollConfig) (<-chan StatusUpdate, error) {
+	cfg, pollCtx, cancel := cfg.withTimeout(ctx)
+
+	auth, err := c.AuthenticationStatusWithContext(pollCtx, authUUID)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	updates := make(chan StatusUpdate, 1)
+	updates <- StatusUpdate{Authentication: auth}
+
+	go func() {
+		defer cancel()
+		defer close(updates)
+
+		send := func(u StatusUpdate) bool {
+			select {
+			case updates <- u:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		ticker := time.NewTicker(cfg.Interval)
+		defer ticker.Stop()
+
+		for !auth.Status.IsTerminal() {
+			select {
+			case <-pollCtx.Done():
+				if ctx.Err() == nil {
+					send(StatusUpdate{Err: pollCtx.Err()})
+				}
+
+				return
+			case <-c.lifecycle.done:
+				send(StatusUpdate{Err: ErrClosed})
+				return
+			case <-ticker.C:
+			}
+
+			next, err := c.AuthenticationStatusWithContext(pollCtx, authUUID)
+			if err != nil {
+				if ctx.Err() == nil {
+					if pollCtx.Err() != nil {
+						err = pollCtx.Err()
+					}
+
+					send(StatusUpdate{Err: err})
+				}
+
+				return
+			}
+
+			changed := next.Status != auth.Status || next.Channel != auth.Channel
+			auth = next
+
+			if changed && !send(StatusUpdate{Authentication: auth}) {
+				return
+			}
+		}
+	}()
+
+	return updates, nil
+}
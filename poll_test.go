@@ -0,0 +1,142 @@
+package ding
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ding-live/ding-go/pkg/dingtest"
+	"github.com/ding-live/ding-go/pkg/status"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestClient(t *testing.T, srv *dingtest.Server) *Client {
+	client, err := NewClient(Config{
+		CustomerUUID: dingtest.CustomerUUID,
+		APIKey:       dingtest.APIKey,
+		BaseURL:      srv.URL,
+	})
+	require.NoError(t, err)
+
+	return client
+}
+
+func TestAuthenticateAndWait(t *testing.T) {
+	srv := dingtest.NewServer()
+	defer srv.Close()
+
+	client := newTestClient(t, srv)
+
+	go func() {
+		for srv.LastAuthenticationUUID() == "" {
+			time.Sleep(time.Millisecond)
+		}
+
+		srv.SetStatus(srv.LastAuthenticationUUID(), status.AuthApproved)
+	}()
+
+	auth, err := client.AuthenticateAndWait(context.Background(), AuthenticateOptions{
+		PhoneNumber: "+33612345678",
+	}, PollConfig{Interval: 10 * time.Millisecond})
+	require.NoError(t, err)
+	assert.Equal(t, status.AuthApproved, auth.Status)
+}
+
+func TestAuthenticateAndWaitTimeout(t *testing.T) {
+	srv := dingtest.NewServer()
+	defer srv.Close()
+
+	client := newTestClient(t, srv)
+
+	auth, err := client.AuthenticateAndWait(context.Background(), AuthenticateOptions{
+		PhoneNumber: "+33612345678",
+	}, PollConfig{Interval: 10 * time.Millisecond, Timeout: 50 * time.Millisecond})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, status.AuthPending, auth.Status)
+}
+
+func TestWaitForStatus(t *testing.T) {
+	srv := dingtest.NewServer()
+	defer srv.Close()
+
+	client := newTestClient(t, srv)
+
+	auth, err := client.Authenticate(NewAuth("+33612345678"))
+	require.NoError(t, err)
+
+	got, err := client.WaitForStatus(context.Background(), auth.AuthenticationUUID, PollConfig{}, status.AuthPending)
+	require.NoError(t, err)
+	assert.Equal(t, status.AuthPending, got.Status)
+
+	got, err = client.WaitForStatus(context.Background(), auth.AuthenticationUUID, PollConfig{
+		Interval: 10 * time.Millisecond,
+		Timeout:  50 * time.Millisecond,
+	}, status.AuthApproved)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, status.AuthPending, got.Status)
+
+	// Terminal statuses end the wait even when they are not targeted.
+	srv.SetStatus(auth.AuthenticationUUID, status.AuthCanceled)
+
+	got, err = client.WaitForStatus(context.Background(), auth.AuthenticationUUID, PollConfig{}, status.AuthApproved)
+	require.NoError(t, err)
+	assert.Equal(t, status.AuthCanceled, got.Status)
+}
+
+func TestStreamStatus(t *testing.T) {
+	srv := dingtest.NewServer()
+	defer srv.Close()
+
+	client := newTestClient(t, srv)
+
+	auth, err := client.Authenticate(NewAuth("+33612345678"))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	updates, err := client.StreamStatus(ctx, auth.AuthenticationUUID, PollConfig{})
+	require.NoError(t, err)
+
+	u := <-updates
+	require.NoError(t, u.Err)
+	assert.Equal(t, status.AuthPending, u.Authentication.Status)
+
+	// The stream ends with the context.
+	cancel()
+
+	_, ok := <-updates
+	assert.False(t, ok)
+
+	// The stream ends with an error once the poll timeout is reached.
+	updates, err = client.StreamStatus(context.Background(), auth.AuthenticationUUID, PollConfig{
+		Interval: 10 * time.Millisecond,
+		Timeout:  50 * time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	u = <-updates
+	require.NoError(t, u.Err)
+
+	u = <-updates
+	assert.ErrorIs(t, u.Err, context.DeadlineExceeded)
+
+	_, ok = <-updates
+	assert.False(t, ok)
+
+	// Streams of terminal authentications end after their first update.
+	srv.SetStatus(auth.AuthenticationUUID, status.AuthApproved)
+
+	updates, err = client.StreamStatus(context.Background(), auth.AuthenticationUUID, PollConfig{})
+	require.NoError(t, err)
+
+	u = <-updates
+	require.NoError(t, u.Err)
+	assert.Equal(t, status.AuthApproved, u.Authentication.Status)
+
+	_, ok = <-updates
+	assert.False(t, ok)
+
+	_, err = client.StreamStatus(context.Background(), "invalid", PollConfig{})
+	assert.ErrorIs(t, err, ErrInvalidAuthUUID)
+}
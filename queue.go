@@ -0,0 +1,251 @@
+package ding
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultQueueBackoff    = 5 * time.Second
+	defaultQueueMaxBackoff = 5 * time.Minute
+
+	// queueSendTimeout bounds the sending of a queued authentication, so that
+	// a stalled connection can't hold the queue.
+	queueSendTimeout = 30 * time.Second
+)
+
+// QueuedAuthentication is an authentication waiting in a Queue for the Ding
+// API to be reachable again. Stores persisting it as JSON get a versioned
+// encoding, see MarshalJSON.
+type QueuedAuthentication struct {
+	ID         string
+	Options    AuthenticateOptions
+	EnqueuedAt time.Time
+}
+
+// QueueConfig configures a Queue.
+type QueueConfig struct {
+	// Store persists the queued authentications, so that they survive
+	// restarts.
+	//
+	// Defaults to a new MemoryQueueStore.
+	Store QueueStore
+
+	// Backoff is the delay before trying to flush the queue again after the
+	// API was found unreachable. It doubles after every failed attempt.
+	//
+	// Defaults to 5 seconds.
+	Backoff time.Duration
+
+	// MaxBackoff caps Backoff.
+	//
+	// Defaults to 5 minutes.
+	MaxBackoff time.Duration
+
+	// OnSent is called when a queued authentication is sent. Like hooks, it
+	// is called from the background and its panics are recovered and logged.
+	OnSent func(QueuedAuthentication, *Authentication)
+
+	// OnError is called when a queued authentication is dropped because it
+	// was rejected for another reason than the API being unreachable, such as
+	// an invalid phone number, or because it may have reached the API, such
+	// as after a timeout, since sending it again could deliver two codes. Its
+	// panics are recovered and logged.
+	OnError func(QueuedAuthentication, error)
+}
+
+// Queue sends authentications, and keeps them for later when the Ding API is
+// unreachable, for deployments with flaky networks such as points of sale.
+// Queued authentications are flushed one at a time, in order, in the
+// background, once the API can be reached again.
+//
+// Only authentications that failed before being sent, such as when the
+// connection was refused, are queued. Those that may have reached the API,
+// such as after a timeout, fail with ErrUnreachable as they would without a
+// queue, since sending them again could deliver two codes to the user.
+//
+// The queue stops when the client is closed, which interrupts the flush in
+// progress. Authentications still queued at that time are kept in the store
+// and sent by the next Queue using it, including one interrupted while being
+// sent, which may then reach the user twice.
+type Queue struct {
+	client     *Client
+	store      QueueStore
+	backoff    time.Duration
+	maxBackoff time.Duration
+	onSent     func(QueuedAuthentication, *Authentication)
+	onError    func(QueuedAuthentication, error)
+	kick       chan struct{}
+}
+
+// NewQueue returns a queue sending authentications with c, and starts flushing
+// the authentications already in its store.
+func (c *Client) NewQueue(cfg QueueConfig) (*Queue, error) {
+	if err := c.enter(); err != nil {
+		return nil, err
+	}
+
+	q := &Queue{
+		client:     c,
+		store:      cfg.Store,
+		backoff:    cfg.Backoff,
+		maxBackoff: cfg.MaxBackoff,
+		onSent:     cfg.OnSent,
+		onError:    cfg.OnError,
+		kick:       make(chan struct{}, 1),
+	}
+
+	if q.store == nil {
+		q.store = NewMemoryQueueStore()
+	}
+
+	if q.backoff <= 0 {
+		q.backoff = defaultQueueBackoff
+	}
+
+	if q.maxBackoff <= 0 {
+		q.maxBackoff = defaultQueueMaxBackoff
+	}
+
+	// The flusher is accounted for as a call in flight, so that Close waits
+	// for it to stop.
+	go func() {
+		defer c.leave()
+		q.run()
+	}()
+
+	return q, nil
+}
+
+// Authenticate sends an authentication right away if the queue is empty and
+// the API is reachable. Otherwise, the authentication is queued and
+// ErrQueued is returned.
+func (q *Queue) Authenticate(ctx context.Context, opt AuthenticateOptions) (*Authentication, error) {
+	pending, err := q.store.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(pending) == 0 {
+		auth, err := q.client.AuthenticateWithContext(ctx, opt)
+		if !errors.Is(err, errNotSent) {
+			return auth, err
+		}
+	}
+
+	err = q.store.Add(ctx, QueuedAuthentication{
+		ID:         uuid.New().String(),
+		Options:    opt,
+		EnqueuedAt: time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	select {
+	case q.kick <- struct{}{}:
+	default:
+	}
+
+	return nil, ErrQueued
+}
+
+func (q *Queue) run() {
+	backoff := q.backoff
+
+	// Flushes are interrupted when the client is closed.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		select {
+		case <-q.client.lifecycle.done:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	// The first flush happens right away to send what is left in the store.
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-q.client.lifecycle.done:
+			return
+		case <-q.kick:
+		case <-timer.C:
+		}
+
+		if q.flush(ctx) {
+			backoff = q.backoff
+			continue
+		}
+
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+
+		timer.Reset(backoff)
+
+		if backoff *= 2; backoff > q.maxBackoff {
+			backoff = q.maxBackoff
+		}
+	}
+}
+
+// flush sends the queued authentications one at a time, in order, and reports
+// whether the queue could be emptied.
+func (q *Queue) flush(ctx context.Context) bool {
+	pending, err := q.store.List(ctx)
+	if err != nil {
+		q.client.api.Logger().Errorf("list queued authentications: %v", err)
+		return false
+	}
+
+	for _, item := range pending {
+		if ctx.Err() != nil {
+			return false
+		}
+
+		auth, err := q.send(ctx, item)
+		if err != nil && (errors.Is(err, errNotSent) || errors.Is(err, ErrClosed) || ctx.Err() != nil) {
+			return false
+		}
+
+		if err := q.store.Remove(ctx, item.ID); err != nil {
+			q.client.api.Logger().Errorf("remove queued authentication: %v", err)
+			return false
+		}
+
+		// The callbacks run on the flushing goroutine, where a panic would
+		// crash the process, so they are only logged.
+		if err != nil {
+			if q.onError != nil {
+				_ = q.client.protect("queue error callback", func() { q.onError(item, err) })
+			}
+
+			continue
+		}
+
+		if q.onSent != nil {
+			_ = q.client.protect("queue sent callback", func() { q.onSent(item, auth) })
+		}
+	}
+
+	return true
+}
+
+// send sends a queued authentication, within queueSendTimeout.
+func (q *Queue) send(ctx context.Context, item QueuedAuthentication) (*Authentication, error) {
+	ctx, cancel := context.WithTimeout(ctx, queueSendTimeout)
+	defer cancel()
+
+	return q.client.AuthenticateWithContext(ctx, item.Options)
+}
@@ -0,0 +1,148 @@
+package ding
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// queueRecordVersion is the version of the JSON encoding of a
+// QueuedAuthentication. Records without a version were written before it
+// existed, with the Go field names of AuthenticateOptions.
+const queueRecordVersion = 1
+
+// queueRecord is the JSON encoding of a QueuedAuthentication. Its field names
+// are fixed, so that renaming a field of AuthenticateOptions can't drop the
+// authentications persisted by a QueueStore.
+type queueRecord struct {
+	Version         int                    `json:"version"`
+	ID              string                 `json:"id"`
+	EnqueuedAt      time.Time              `json:"enqueued_at"`
+	PhoneNumber     string                 `json:"phone_number"`
+	PhoneRegion     string                 `json:"phone_region,omitempty"`
+	Device          *deviceRecord          `json:"device,omitempty"`
+	CallbackURL     *string                `json:"callback_url,omitempty"`
+	IsReturningUser bool                   `json:"is_returning_user,omitempty"`
+	Channels        []Channel              `json:"channels,omitempty"`
+	Extra           map[string]interface{} `json:"extra,omitempty"`
+	Consent         *consentRecord         `json:"consent,omitempty"`
+	TTLMillis       int64                  `json:"ttl_ms,omitempty"`
+
+	// Options holds the options of records without a version.
+	Options *AuthenticateOptions `json:"options,omitempty"`
+}
+
+type deviceRecord struct {
+	IP         string     `json:"ip,omitempty"`
+	ID         string     `json:"id,omitempty"`
+	Type       DeviceType `json:"type,omitempty"`
+	AppVersion string     `json:"app_version,omitempty"`
+	OSVersion  string     `json:"os_version,omitempty"`
+	Model      string     `json:"model,omitempty"`
+	UserAgent  string     `json:"user_agent,omitempty"`
+}
+
+type consentRecord struct {
+	GivenAt      time.Time `json:"given_at,omitempty"`
+	TermsVersion string    `json:"terms_version,omitempty"`
+	Channel      Channel   `json:"channel,omitempty"`
+}
+
+// MarshalJSON encodes the authentication in a versioned format that doesn't
+// depend on the Go field names of AuthenticateOptions. It holds the phone
+// number of the user, and their device signals if any.
+func (q QueuedAuthentication) MarshalJSON() ([]byte, error) {
+	opt := q.Options
+
+	r := queueRecord{
+		Version:         queueRecordVersion,
+		ID:              q.ID,
+		EnqueuedAt:      q.EnqueuedAt,
+		PhoneNumber:     opt.PhoneNumber,
+		PhoneRegion:     opt.PhoneRegion,
+		CallbackURL:     opt.CallbackURL,
+		IsReturningUser: opt.IsReturningUser,
+		Channels:        opt.Channels,
+		Extra:           opt.Extra,
+		TTLMillis:       opt.TTL.Milliseconds(),
+	}
+
+	if d := opt.deviceSignals(); d != (DeviceSignals{}) {
+		r.Device = &deviceRecord{
+			IP:         d.IP,
+			ID:         d.ID,
+			Type:       d.Type,
+			AppVersion: d.AppVersion,
+			OSVersion:  d.OSVersion,
+			Model:      d.Model,
+			UserAgent:  d.UserAgent,
+		}
+	}
+
+	if c := opt.Consent; c != nil {
+		r.Consent = &consentRecord{
+			GivenAt:      c.GivenAt,
+			TermsVersion: c.TermsVersion,
+			Channel:      c.Channel,
+		}
+	}
+
+	return json.Marshal(r)
+}
+
+// UnmarshalJSON decodes an authentication encoded by MarshalJSON, or by
+// earlier versions of the package.
+func (q *QueuedAuthentication) UnmarshalJSON(b []byte) error {
+	var r queueRecord
+	if err := json.Unmarshal(b, &r); err != nil {
+		return err
+	}
+
+	switch r.Version {
+	case 0:
+		*q = QueuedAuthentication{ID: r.ID, EnqueuedAt: r.EnqueuedAt}
+
+		if r.Options != nil {
+			q.Options = *r.Options
+		}
+
+		return nil
+	case queueRecordVersion:
+	default:
+		return fmt.Errorf("unsupported queued authentication version %d", r.Version)
+	}
+
+	opt := AuthenticateOptions{
+		PhoneNumber:     r.PhoneNumber,
+		PhoneRegion:     r.PhoneRegion,
+		CallbackURL:     r.CallbackURL,
+		IsReturningUser: r.IsReturningUser,
+		Channels:        r.Channels,
+		Extra:           r.Extra,
+		TTL:             time.Duration(r.TTLMillis) * time.Millisecond,
+	}
+
+	if d := r.Device; d != nil {
+		opt.Device = &DeviceSignals{
+			IP:         d.IP,
+			ID:         d.ID,
+			Type:       d.Type,
+			AppVersion: d.AppVersion,
+			OSVersion:  d.OSVersion,
+			Model:      d.Model,
+			UserAgent:  d.UserAgent,
+		}
+	}
+
+	if c := r.Consent; c != nil {
+		opt.Consent = &Consent{
+			GivenAt:      c.GivenAt,
+			TermsVersion: c.TermsVersion,
+			Channel:      c.Channel,
+		}
+	}
+
+	*q = QueuedAuthentication{ID: r.ID, Options: opt, EnqueuedAt: r.EnqueuedAt}
+
+	return nil
+}
@@ -0,0 +1,174 @@
+package ding
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// QueueStore persists the authentications of a Queue. Implementations must be
+// safe for concurrent use and return items in the order they were added. Use
+// MemoryQueueStore or FileQueueStore, or implement it on top of a shared store
+// such as Redis.
+type QueueStore interface {
+	Add(ctx context.Context, item QueuedAuthentication) error
+	List(ctx context.Context) ([]QueuedAuthentication, error)
+	Remove(ctx context.Context, id string) error
+}
+
+// MemoryQueueStore is a QueueStore keeping items in memory. Items are lost when
+// the process exits.
+type MemoryQueueStore struct {
+	mu    sync.Mutex
+	items []QueuedAuthentication
+}
+
+// NewMemoryQueueStore returns an empty MemoryQueueStore.
+func NewMemoryQueueStore() *MemoryQueueStore {
+	return &MemoryQueueStore{}
+}
+
+// Add appends item to the store.
+func (s *MemoryQueueStore) Add(_ context.Context, item QueuedAuthentication) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.items = append(s.items, item)
+
+	return nil
+}
+
+// List returns the items of the store, oldest first.
+func (s *MemoryQueueStore) List(context.Context) ([]QueuedAuthentication, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]QueuedAuthentication(nil), s.items...), nil
+}
+
+// Remove removes the item with the given ID, if any.
+func (s *MemoryQueueStore) Remove(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.items = removeQueued(s.items, id)
+
+	return nil
+}
+
+// FileQueueStore is a QueueStore keeping items in a JSON file, which is
+// rewritten atomically on every change. Items are written in the versioned
+// format of QueuedAuthentication.MarshalJSON, and files written by earlier
+// versions of the package can still be read.
+//
+// The file holds personal data: the phone numbers of the users, and their IP
+// addresses and device signals if given. It is created with mode 0600, readable
+// by the user of the process only, so keep it out of backups and shared
+// directories, and drop the items of users who ask for erasure.
+type FileQueueStore struct {
+	path string
+
+	mu sync.Mutex
+}
+
+// NewFileQueueStore returns a store backed by the file at path, which is
+// created on the first Add if it doesn't exist.
+func NewFileQueueStore(path string) *FileQueueStore {
+	return &FileQueueStore{path: path}
+}
+
+// Add appends item to the file.
+func (s *FileQueueStore) Add(_ context.Context, item QueuedAuthentication) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	items, err := s.read()
+	if err != nil {
+		return err
+	}
+
+	return s.write(append(items, item))
+}
+
+// List returns the items of the file, oldest first.
+func (s *FileQueueStore) List(context.Context) ([]QueuedAuthentication, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.read()
+}
+
+// Remove removes the item with the given ID from the file, if any.
+func (s *FileQueueStore) Remove(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	items, err := s.read()
+	if err != nil {
+		return err
+	}
+
+	return s.write(removeQueued(items, id))
+}
+
+func (s *FileQueueStore) read() ([]QueuedAuthentication, error) {
+	b, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var items []QueuedAuthentication
+	if err := json.Unmarshal(b, &items); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+func (s *FileQueueStore) write(items []QueuedAuthentication) error {
+	b, err := json.Marshal(items)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), s.path)
+}
+
+func removeQueued(items []QueuedAuthentication, id string) []QueuedAuthentication {
+	kept := items[:0:0]
+
+	for _, item := range items {
+		if item.ID != id {
+			kept = append(kept, item)
+		}
+	}
+
+	return kept
+}
@@ -0,0 +1,301 @@
+package ding
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/ding-live/ding-go/pkg/dingtest"
+	"github.com/ding-live/ding-go/pkg/transport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// errNetworkDown is the error of requests made without network, which never
+// leave the machine.
+var errNetworkDown = &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ENETUNREACH}
+
+func TestQueue(t *testing.T) {
+	srv := dingtest.NewServer()
+	defer srv.Close()
+
+	var down atomic.Value
+	down.Store(true)
+
+	client, err := NewClient(Config{
+		CustomerUUID:      dingtest.CustomerUUID,
+		APIKey:            dingtest.APIKey,
+		BaseURL:           srv.URL,
+		MaxNetworkRetries: Int(0),
+		Transport: transport.Func(func(ctx context.Context, req transport.Request) (transport.Response, error) {
+			if down.Load().(bool) {
+				return transport.Response{}, errNetworkDown
+			}
+
+			return transport.HTTP{}.Do(ctx, req)
+		}),
+	})
+	require.NoError(t, err)
+
+	var (
+		mu   sync.Mutex
+		sent []string
+	)
+
+	store := NewFileQueueStore(filepath.Join(t.TempDir(), "queue.json"))
+
+	q, err := client.NewQueue(QueueConfig{
+		Store:   store,
+		Backoff: 10 * time.Millisecond,
+		OnSent: func(item QueuedAuthentication, auth *Authentication) {
+			mu.Lock()
+			sent = append(sent, auth.PhoneNumber.E164)
+			mu.Unlock()
+		},
+	})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	_, err = q.Authenticate(ctx, NewAuth("+33612345678"))
+	require.ErrorIs(t, err, ErrQueued)
+
+	_, err = q.Authenticate(ctx, NewAuth("+33612345679"))
+	require.ErrorIs(t, err, ErrQueued)
+
+	items, err := store.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+
+	down.Store(false)
+
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return len(sent) == 2
+	}, time.Second, 5*time.Millisecond)
+
+	assert.Equal(t, []string{"+33612345678", "+33612345679"}, sent)
+
+	items, err = store.List(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, items)
+
+	// The queue is empty, so authentications are sent right away.
+	auth, err := q.Authenticate(ctx, NewAuth("+33612345678"))
+	require.NoError(t, err)
+	assert.NotEmpty(t, auth.AuthenticationUUID)
+
+	require.NoError(t, client.Close(ctx))
+}
+
+func TestQueuePanickingCallbacks(t *testing.T) {
+	srv := dingtest.NewServer()
+	defer srv.Close()
+
+	var down atomic.Value
+	down.Store(true)
+
+	client, err := NewClient(Config{
+		CustomerUUID:      dingtest.CustomerUUID,
+		APIKey:            dingtest.APIKey,
+		BaseURL:           srv.URL,
+		MaxNetworkRetries: Int(0),
+		LeveledLogger:     &Logger{Level: LevelNull},
+		Transport: transport.Func(func(ctx context.Context, req transport.Request) (transport.Response, error) {
+			if down.Load().(bool) {
+				return transport.Response{}, errNetworkDown
+			}
+
+			return transport.HTTP{}.Do(ctx, req)
+		}),
+	})
+	require.NoError(t, err)
+
+	var calls int32
+
+	q, err := client.NewQueue(QueueConfig{
+		Backoff: 10 * time.Millisecond,
+		OnSent: func(QueuedAuthentication, *Authentication) {
+			atomic.AddInt32(&calls, 1)
+			panic("boom")
+		},
+		OnError: func(QueuedAuthentication, error) {
+			atomic.AddInt32(&calls, 1)
+			panic("boom")
+		},
+	})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	srv.SetScenario("+33612345679", dingtest.Scenario{Error: "invalid_phone_number"})
+
+	for _, phone := range []string{"+33612345678", "+33612345679", "+33612345670"} {
+		_, err = q.Authenticate(ctx, NewAuth(phone))
+		require.ErrorIs(t, err, ErrQueued)
+	}
+
+	down.Store(false)
+
+	// The panics don't stop the flush, nor crash the process.
+	require.Eventually(t, func() bool {
+		return atomic.LoadInt32(&calls) == 3
+	}, time.Second, 5*time.Millisecond)
+
+	require.NoError(t, client.Close(ctx))
+}
+
+func TestQueueFlushDoesNotBlock(t *testing.T) {
+	srv := dingtest.NewServer()
+	defer srv.Close()
+
+	var down atomic.Value
+	down.Store(true)
+
+	sending := make(chan struct{}, 1)
+
+	client, err := NewClient(Config{
+		CustomerUUID:      dingtest.CustomerUUID,
+		APIKey:            dingtest.APIKey,
+		BaseURL:           srv.URL,
+		MaxNetworkRetries: Int(0),
+		LeveledLogger:     &Logger{Level: LevelNull},
+		Transport: transport.Func(func(ctx context.Context, req transport.Request) (transport.Response, error) {
+			if down.Load().(bool) {
+				return transport.Response{}, errNetworkDown
+			}
+
+			// The connection stalls until the request is cancelled.
+			select {
+			case sending <- struct{}{}:
+			default:
+			}
+
+			<-ctx.Done()
+
+			return transport.Response{}, ctx.Err()
+		}),
+	})
+	require.NoError(t, err)
+
+	store := NewMemoryQueueStore()
+
+	q, err := client.NewQueue(QueueConfig{Store: store, Backoff: 10 * time.Millisecond})
+	require.NoError(t, err)
+
+	_, err = q.Authenticate(context.Background(), NewAuth("+33612345678"))
+	require.ErrorIs(t, err, ErrQueued)
+
+	down.Store(false)
+	<-sending
+
+	// Callers are not held by the flush in progress.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	_, err = q.Authenticate(ctx, NewAuth("+33612345679"))
+	require.ErrorIs(t, err, ErrQueued)
+
+	// Closing the client interrupts the flush, and keeps the authentications.
+	require.NoError(t, client.Close(ctx))
+
+	items, err := store.List(ctx)
+	require.NoError(t, err)
+	assert.Len(t, items, 2)
+}
+
+func TestQueueAmbiguousFailure(t *testing.T) {
+	client, err := NewClient(Config{
+		CustomerUUID:      dingtest.CustomerUUID,
+		APIKey:            dingtest.APIKey,
+		MaxNetworkRetries: Int(0),
+		LeveledLogger:     &Logger{Level: LevelNull},
+		Transport: transport.Func(func(ctx context.Context, req transport.Request) (transport.Response, error) {
+			return transport.Response{}, io.ErrUnexpectedEOF
+		}),
+	})
+	require.NoError(t, err)
+
+	store := NewMemoryQueueStore()
+
+	q, err := client.NewQueue(QueueConfig{Store: store})
+	require.NoError(t, err)
+
+	// The request may have reached the API, so it isn't queued.
+	_, err = q.Authenticate(context.Background(), NewAuth("+33612345678"))
+	require.ErrorIs(t, err, ErrUnreachable)
+	assert.NotErrorIs(t, err, ErrQueued)
+
+	items, err := store.List(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, items)
+
+	require.NoError(t, client.Close(context.Background()))
+}
+
+func TestFileQueueStoreFormat(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "queue.json")
+	store := NewFileQueueStore(path)
+
+	item := QueuedAuthentication{
+		ID: "1",
+		Options: NewAuth("+33612345678").
+			WithIP("192.0.2.1").
+			WithExtra("locale", "fr"),
+		EnqueuedAt: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC),
+	}
+	item.Options.TTL = time.Minute
+
+	require.NoError(t, store.Add(ctx, item))
+
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	var records []map[string]interface{}
+	require.NoError(t, json.Unmarshal(b, &records))
+	require.Len(t, records, 1)
+	assert.Equal(t, float64(queueRecordVersion), records[0]["version"])
+	assert.Equal(t, "+33612345678", records[0]["phone_number"])
+	assert.Equal(t, float64(60000), records[0]["ttl_ms"])
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	items, err := store.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, "+33612345678", items[0].Options.PhoneNumber)
+	assert.Equal(t, "192.0.2.1", items[0].Options.deviceSignals().IP)
+	assert.Equal(t, map[string]interface{}{"locale": "fr"}, items[0].Options.Extra)
+	assert.Equal(t, time.Minute, items[0].Options.TTL)
+	assert.True(t, item.EnqueuedAt.Equal(items[0].EnqueuedAt))
+
+	// Files written before the format was versioned are still read.
+	legacy := `[{"id":"2","options":{"PhoneNumber":"+33612345679","Channels":["sms"]},"enqueued_at":"2024-03-01T12:00:00Z"}]`
+	require.NoError(t, os.WriteFile(path, []byte(legacy), 0o600))
+
+	items, err = store.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, "2", items[0].ID)
+	assert.Equal(t, "+33612345679", items[0].Options.PhoneNumber)
+	assert.Equal(t, []Channel{ChannelSMS}, items[0].Options.Channels)
+
+	// Newer formats are not silently dropped.
+	require.NoError(t, os.WriteFile(path, []byte(`[{"version":2,"id":"3"}]`), 0o600))
+
+	_, err = store.List(ctx)
+	assert.Error(t, err)
+}
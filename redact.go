@@ -0,0 +1,188 @@
+package ding
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// PhoneRedaction controls how phone numbers appear in the logs of the client.
+type PhoneRedaction int
+
+const (
+	// PhoneRedactionNone leaves phone numbers as is. This is the default.
+	PhoneRedactionNone PhoneRedaction = iota
+
+	// PhoneRedactionTruncate masks the digits of phone numbers but the first
+	// three and the last two, such as "+336******78".
+	PhoneRedactionTruncate
+
+	// PhoneRedactionHash replaces phone numbers with the first 12 hex digits
+	// of their SHA-256 hash, such as "sha256:7c9e3e0d8e5b", so that the lines
+	// about a given number can still be correlated. Since there are few phone
+	// numbers, hashes can be reversed by trying them all: they pseudonymize
+	// numbers rather than anonymize them.
+	PhoneRedactionHash
+)
+
+// phonePattern matches the phone numbers in E.164 format, which is the format
+// of every number handled by the client after normalization.
+var phonePattern = regexp.MustCompile(`\+[1-9][0-9]{6,14}`)
+
+// redact returns number redacted according to r.
+func (r PhoneRedaction) redact(number string) string {
+	switch r {
+	case PhoneRedactionTruncate:
+		digits := strings.TrimPrefix(number, "+")
+		if len(digits) <= 5 {
+			return "+" + strings.Repeat("*", len(digits))
+		}
+
+		return "+" + digits[:3] + strings.Repeat("*", len(digits)-5) + digits[len(digits)-2:]
+	case PhoneRedactionHash:
+		sum := sha256.Sum256([]byte(number))
+		return "sha256:" + hex.EncodeToString(sum[:6])
+	default:
+		return number
+	}
+}
+
+// RedactPhoneNumber redacts number, in E.164 format, according to
+// Config.RedactPhoneNumbers. Use it to apply the same policy to your own logs,
+// audit records and metric labels.
+func (c *Client) RedactPhoneNumber(number string) string {
+	return c.phoneRedaction.redact(number)
+}
+
+// redactingLogger redacts the phone numbers of the messages it logs.
+type redactingLogger struct {
+	next      LeveledLogger
+	redaction PhoneRedaction
+}
+
+func newRedactingLogger(next LeveledLogger, redaction PhoneRedaction) LeveledLogger {
+	if redaction == PhoneRedactionNone {
+		return next
+	}
+
+	return &redactingLogger{next: next, redaction: redaction}
+}
+
+// enabled reports whether next emits messages of level, to avoid formatting
+// messages that are dropped.
+func (l *redactingLogger) enabled(level Level) bool {
+	if e, ok := l.next.(interface{ Enabled(Level) bool }); ok {
+		return e.Enabled(level)
+	}
+
+	return true
+}
+
+func (l *redactingLogger) format(format string, v []interface{}) string {
+	return phonePattern.ReplaceAllStringFunc(fmt.Sprintf(format, v...), l.redaction.redact)
+}
+
+func (l *redactingLogger) Debugf(format string, v ...interface{}) {
+	if l.enabled(LevelDebug) {
+		l.next.Debugf("%s", l.format(format, v))
+	}
+}
+
+func (l *redactingLogger) Errorf(format string, v ...interface{}) {
+	if l.enabled(LevelError) {
+		l.next.Errorf("%s", l.format(format, v))
+	}
+}
+
+func (l *redactingLogger) Infof(format string, v ...interface{}) {
+	if l.enabled(LevelInfo) {
+		l.next.Infof("%s", l.format(format, v))
+	}
+}
+
+func (l *redactingLogger) Warnf(format string, v ...interface{}) {
+	if l.enabled(LevelWarn) {
+		l.next.Warnf("%s", l.format(format, v))
+	}
+}
+
+// redactedSecret replaces the secrets of Config in its string representations.
+const redactedSecret = "[REDACTED]"
+
+func redactSecret(s string) string {
+	if s == "" {
+		return ""
+	}
+
+	return redactedSecret
+}
+
+// config has the fields of Config without its methods, so that it can be
+// formatted without recursing into String.
+type config Config
+
+// String returns the fields of cfg with its API key, callback secret and
+// signing key redacted, so that logging a Config with %v or %+v, or capturing
+// it in a crash report, doesn't expose them.
+func (cfg Config) String() string {
+	return fmt.Sprintf("%+v", cfg.redacted())
+}
+
+// GoString redacts the secrets of cfg like String does, for the %#v verb.
+func (cfg Config) GoString() string {
+	return "ding.Config" + strings.TrimPrefix(fmt.Sprintf("%#v", cfg.redacted()), "ding.config")
+}
+
+func (cfg Config) redacted() config {
+	cfg.APIKey = redactSecret(cfg.APIKey)
+	cfg.FallbackAPIKey = redactSecret(cfg.FallbackAPIKey)
+	cfg.CallbackSecret = redactSecret(cfg.CallbackSecret)
+	cfg.SigningKey = redactSecret(cfg.SigningKey)
+
+	if cfg.APIKeys != nil {
+		keys := make([]WeightedAPIKey, len(cfg.APIKeys))
+		for i, k := range cfg.APIKeys {
+			k.Key = redactSecret(k.Key)
+			keys[i] = k
+		}
+		cfg.APIKeys = keys
+	}
+
+	if cfg.Environments != nil {
+		envs := make(map[string]Environment, len(cfg.Environments))
+		for name, env := range cfg.Environments {
+			env.APIKey = redactSecret(env.APIKey)
+			envs[name] = env
+		}
+		cfg.Environments = envs
+	}
+
+	return config(cfg)
+}
+
+// environment has the fields of Environment without its methods.
+type environment Environment
+
+// String returns the fields of env with its API key redacted.
+func (env Environment) String() string {
+	env.APIKey = redactSecret(env.APIKey)
+	return fmt.Sprintf("%+v", environment(env))
+}
+
+// GoString redacts the API key of env like String does, for the %#v verb.
+func (env Environment) GoString() string {
+	env.APIKey = redactSecret(env.APIKey)
+	return "ding.Environment" + strings.TrimPrefix(fmt.Sprintf("%#v", environment(env)), "ding.environment")
+}
+
+// String describes c without the secrets it holds.
+func (c *Client) String() string {
+	return fmt.Sprintf("ding.Client{CustomerUUID: %s}", c.customerUUID)
+}
+
+// GoString describes c like String does, for the %#v verb.
+func (c *Client) GoString() string {
+	return c.String()
+}
@@ -0,0 +1,113 @@
+package ding
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ding-live/ding-go/pkg/dingtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type messageLogger struct {
+	messages []string
+}
+
+func (l *messageLogger) Debugf(format string, v ...interface{}) { l.log(format, v) }
+func (l *messageLogger) Errorf(format string, v ...interface{}) { l.log(format, v) }
+func (l *messageLogger) Infof(format string, v ...interface{})  { l.log(format, v) }
+func (l *messageLogger) Warnf(format string, v ...interface{})  { l.log(format, v) }
+
+func (l *messageLogger) log(format string, v []interface{}) {
+	l.messages = append(l.messages, fmt.Sprintf(format, v...))
+}
+
+func TestPhoneRedaction(t *testing.T) {
+	assert.Equal(t, "+33612345678", PhoneRedactionNone.redact("+33612345678"))
+	assert.Equal(t, "+336******78", PhoneRedactionTruncate.redact("+33612345678"))
+	assert.Equal(t, "+*****", PhoneRedactionTruncate.redact("+12345"))
+
+	hash := PhoneRedactionHash.redact("+33612345678")
+	assert.Regexp(t, `^sha256:[0-9a-f]{12}$`, hash)
+	assert.Equal(t, hash, PhoneRedactionHash.redact("+33612345678"))
+	assert.NotEqual(t, hash, PhoneRedactionHash.redact("+33612345679"))
+}
+
+func TestRedactingLogger(t *testing.T) {
+	var next messageLogger
+
+	logger := newRedactingLogger(&next, PhoneRedactionTruncate)
+	logger.Errorf("send to %s failed: %v", "+33612345678", fmt.Errorf("number +14155550100 is busy"))
+	logger.Debugf("status %d", 200)
+
+	assert.Equal(t, []string{
+		"send to +336******78 failed: number +141******00 is busy",
+		"status 200",
+	}, next.messages)
+
+	// Messages dropped by the Logger aren't formatted.
+	assert.Same(t, &next, newRedactingLogger(&next, PhoneRedactionNone))
+	assert.False(t, (&redactingLogger{next: &Logger{Level: LevelError}}).enabled(LevelDebug))
+}
+
+func TestRedactPhoneNumbers(t *testing.T) {
+	srv := dingtest.NewServer()
+	defer srv.Close()
+
+	var logger messageLogger
+
+	client, err := NewClient(Config{
+		CustomerUUID:       dingtest.CustomerUUID,
+		APIKey:             dingtest.APIKey,
+		BaseURL:            srv.URL,
+		LeveledLogger:      &logger,
+		RedactPhoneNumbers: PhoneRedactionHash,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, PhoneRedactionHash.redact("+33612345678"), client.RedactPhoneNumber("+33612345678"))
+
+	client.api.Logger().Infof("authenticating %s", "+33612345678")
+	client.With(WithLogger(&logger)).api.Logger().Infof("authenticating %s", "+33612345678")
+
+	require.Len(t, logger.messages, 2)
+	for _, m := range logger.messages {
+		assert.NotContains(t, m, "+33612345678")
+	}
+}
+
+func TestConfigString(t *testing.T) {
+	cfg := Config{
+		CustomerUUID:   dingtest.CustomerUUID,
+		APIKey:         "secret_api_key",
+		CallbackSecret: "secret_callback",
+		SigningKey:     "secret_signing",
+		APIKeys:        []WeightedAPIKey{{Key: "secret_weighted", Weight: 2}},
+		FallbackAPIKey: "secret_fallback",
+		Environments: map[string]Environment{
+			"staging": {APIKey: "secret_staging"},
+		},
+	}
+
+	client, err := NewClient(Config{
+		CustomerUUID:   dingtest.CustomerUUID,
+		APIKey:         "secret_api_key",
+		CallbackSecret: "secret_callback",
+	})
+	require.NoError(t, err)
+
+	for _, verb := range []string{"%v", "%+v", "%#v", "%s"} {
+		for _, v := range []interface{}{cfg, &cfg, cfg.Environments["staging"], client} {
+			s := fmt.Sprintf(verb, v)
+			assert.NotContains(t, s, "secret_", verb)
+		}
+
+		assert.Contains(t, fmt.Sprintf(verb, cfg), dingtest.CustomerUUID)
+		assert.Contains(t, fmt.Sprintf(verb, cfg), redactedSecret)
+	}
+
+	// Formatting doesn't modify cfg.
+	assert.Equal(t, "secret_api_key", cfg.APIKey)
+	assert.Equal(t, "secret_staging", cfg.Environments["staging"].APIKey)
+	assert.Equal(t, "secret_weighted", cfg.APIKeys[0].Key)
+}
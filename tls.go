@@ -0,0 +1,101 @@
+package ding
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+)
+
+// TLSPolicy restricts the TLS connections made to the Ding API by the default
+// HTTP client of the client.
+type TLSPolicy struct {
+	// MinVersion is the minimum TLS version accepted, such as tls.VersionTLS13.
+	//
+	// Defaults to tls.VersionTLS12.
+	MinVersion uint16
+
+	// CipherSuites is the list of cipher suites enabled for TLS 1.2 and
+	// below, such as tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384. The cipher
+	// suites of TLS 1.3 are not configurable.
+	//
+	// Defaults to the cipher suites enabled by Go.
+	CipherSuites []uint16
+
+	// RootCAs is the set of root certificate authorities used to verify the
+	// certificate of the Ding API, to pin its CA. Use LoadRootCAs to read
+	// them from a PEM file.
+	//
+	// Defaults to the system roots.
+	RootCAs *x509.CertPool
+}
+
+func (p TLSPolicy) isZero() bool {
+	return p.MinVersion == 0 && len(p.CipherSuites) == 0 && p.RootCAs == nil
+}
+
+// config returns the tls.Config enforcing p.
+func (p TLSPolicy) config() (*tls.Config, error) {
+	switch p.MinVersion {
+	case 0, tls.VersionTLS12, tls.VersionTLS13:
+	case tls.VersionTLS10, tls.VersionTLS11:
+		return nil, fmt.Errorf("TLS versions below 1.2 are not supported by the Ding API")
+	default:
+		return nil, fmt.Errorf("unknown TLS version %#04x", p.MinVersion)
+	}
+
+	for _, id := range p.CipherSuites {
+		if !isSecureCipherSuite(id) {
+			return nil, fmt.Errorf("unknown or insecure cipher suite %#04x", id)
+		}
+	}
+
+	cfg := &tls.Config{
+		MinVersion:   p.MinVersion,
+		CipherSuites: p.CipherSuites,
+		RootCAs:      p.RootCAs,
+	}
+
+	if cfg.MinVersion == 0 {
+		cfg.MinVersion = tls.VersionTLS12
+	}
+
+	return cfg, nil
+}
+
+// cipherSuiteIDs returns the IDs of the secure cipher suites implemented by
+// Go, keyed on their names.
+func cipherSuiteIDs() map[string]uint16 {
+	ids := make(map[string]uint16)
+	for _, s := range tls.CipherSuites() {
+		ids[s.Name] = s.ID
+	}
+
+	return ids
+}
+
+func isSecureCipherSuite(id uint16) bool {
+	for _, s := range tls.CipherSuites() {
+		if s.ID == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+// LoadRootCAs reads the PEM encoded certificates of the file at path into a
+// pool, to be used as TLSPolicy.RootCAs.
+func LoadRootCAs(path string) (*x509.CertPool, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read root CAs: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(b) {
+		return nil, fmt.Errorf("no certificate found in %s", path)
+	}
+
+	return pool, nil
+}
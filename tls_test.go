@@ -0,0 +1,102 @@
+package ding
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ding-live/ding-go/pkg/dingtest"
+	"github.com/ding-live/ding-go/pkg/transport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTLSServer(t *testing.T, maxVersion uint16) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewUnstartedServer(dingtest.NewHandler())
+	srv.TLS = &tls.Config{MaxVersion: maxVersion}
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func newTLSClient(t *testing.T, srv *httptest.Server, policy TLSPolicy) *Client {
+	t.Helper()
+
+	client, err := NewClient(Config{
+		CustomerUUID:      dingtest.CustomerUUID,
+		APIKey:            dingtest.APIKey,
+		BaseURL:           srv.URL,
+		MaxNetworkRetries: Int(0),
+		LeveledLogger:     &Logger{Level: LevelNull},
+		TLS:               policy,
+	})
+	require.NoError(t, err)
+
+	return client
+}
+
+func TestTLSPolicy(t *testing.T) {
+	srv := newTLSServer(t, tls.VersionTLS12)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(srv.Certificate())
+
+	client := newTLSClient(t, srv, TLSPolicy{RootCAs: roots})
+	_, err := client.Authenticate(NewAuth("+33612345678"))
+	assert.NoError(t, err)
+
+	// The certificate of the server is not signed by the system roots.
+	client = newTLSClient(t, srv, TLSPolicy{MinVersion: tls.VersionTLS12})
+	_, err = client.Authenticate(NewAuth("+33612345678"))
+	assert.ErrorIs(t, err, ErrUnreachable)
+
+	// The server doesn't speak TLS 1.3.
+	client = newTLSClient(t, srv, TLSPolicy{MinVersion: tls.VersionTLS13, RootCAs: roots})
+	_, err = client.Authenticate(NewAuth("+33612345678"))
+	assert.ErrorIs(t, err, ErrUnreachable)
+}
+
+func TestTLSPolicyErrors(t *testing.T) {
+	for name, cfg := range map[string]Config{
+		"old version":      {TLS: TLSPolicy{MinVersion: tls.VersionTLS11}},
+		"unknown version":  {TLS: TLSPolicy{MinVersion: 0x0305}},
+		"insecure suite":   {TLS: TLSPolicy{CipherSuites: []uint16{tls.TLS_RSA_WITH_RC4_128_SHA}}},
+		"custom client":    {TLS: TLSPolicy{MinVersion: tls.VersionTLS13}, CustomHTTPClient: &http.Client{}},
+		"custom transport": {TLS: TLSPolicy{MinVersion: tls.VersionTLS13}, Transport: transport.HTTP{}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			cfg.CustomerUUID = dingtest.CustomerUUID
+			_, err := NewClient(cfg)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestLoadRootCAs(t *testing.T) {
+	srv := newTLSServer(t, 0)
+
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{
+		Type:  "CERTIFICATE",
+		Bytes: srv.Certificate().Raw,
+	}), 0o600))
+
+	roots, err := LoadRootCAs(path)
+	require.NoError(t, err)
+
+	client := newTLSClient(t, srv, TLSPolicy{RootCAs: roots})
+	_, err = client.Authenticate(NewAuth("+33612345678"))
+	assert.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(path, []byte("not a certificate"), 0o600))
+	_, err = LoadRootCAs(path)
+	assert.Error(t, err)
+}
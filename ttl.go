@@ -0,0 +1,51 @@
+package ding
+
+import (
+	"sync"
+	"time"
+)
+
+// deadlines records the expiries of the authentications shortened with
+// AuthenticateOptions.TTL, so that CheckWithContext rejects their codes once
+// past them, while the Ding API would still accept them.
+type deadlines struct {
+	mu     sync.Mutex
+	byUUID map[string]time.Time
+}
+
+func newDeadlines() *deadlines {
+	return &deadlines{
+		byUUID: make(map[string]time.Time),
+	}
+}
+
+// set records deadline, a local time, for authUUID. The earliest deadline is
+// kept if the authentication already has one, and past deadlines are dropped.
+func (d *deadlines) set(authUUID string, deadline time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+
+	for id, t := range d.byUUID {
+		if now.After(t) {
+			delete(d.byUUID, id)
+		}
+	}
+
+	if t, ok := d.byUUID[authUUID]; ok && t.Before(deadline) {
+		return
+	}
+
+	d.byUUID[authUUID] = deadline
+}
+
+// expired reports whether authUUID has a deadline before now.
+func (d *deadlines) expired(authUUID string, now time.Time) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	t, ok := d.byUUID[authUUID]
+
+	return ok && !now.Before(t)
+}
@@ -0,0 +1,43 @@
+package ding
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// SupportedAPIVersions lists the versions of the Ding API that the client
+// supports, from the oldest to the newest.
+var SupportedAPIVersions = []string{"v1"}
+
+// ErrUnsupportedAPIVersion is returned when Config.APIVersion is not one of
+// SupportedAPIVersions, or doesn't match Config.BaseURL.
+var ErrUnsupportedAPIVersion = errors.New("unsupported API version")
+
+// versionedBaseURL returns the base URL of the API at version, which the Ding
+// API carries in the path of its URLs rather than in a header. A custom
+// baseURL must already point at that version.
+func versionedBaseURL(baseURL, version string) (string, error) {
+	if version == "" {
+		return baseURL, nil
+	}
+
+	supported := false
+	for _, v := range SupportedAPIVersions {
+		supported = supported || v == version
+	}
+
+	if !supported {
+		return "", fmt.Errorf("%w %q, supported versions are %s", ErrUnsupportedAPIVersion, version, strings.Join(SupportedAPIVersions, ", "))
+	}
+
+	if baseURL == "" {
+		return strings.TrimSuffix(apiBaseURL, "/v1") + "/" + version, nil
+	}
+
+	if !strings.HasSuffix(strings.TrimSuffix(baseURL, "/"), "/"+version) {
+		return "", fmt.Errorf("%w %q: base URL %s points at another version", ErrUnsupportedAPIVersion, version, baseURL)
+	}
+
+	return baseURL, nil
+}
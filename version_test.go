@@ -0,0 +1,36 @@
+package ding
+
+import (
+	"testing"
+
+	"github.com/ding-live/ding-go/pkg/dingtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIVersion(t *testing.T) {
+	baseURL, err := versionedBaseURL("", "v1")
+	require.NoError(t, err)
+	assert.Equal(t, apiBaseURL, baseURL)
+
+	baseURL, err = versionedBaseURL("https://eu.ding.live/v1/", "v1")
+	require.NoError(t, err)
+	assert.Equal(t, "https://eu.ding.live/v1/", baseURL)
+
+	baseURL, err = versionedBaseURL("http://localhost:8080", "")
+	require.NoError(t, err)
+	assert.Equal(t, "http://localhost:8080", baseURL)
+
+	_, err = versionedBaseURL("", "v0")
+	assert.ErrorIs(t, err, ErrUnsupportedAPIVersion)
+
+	_, err = versionedBaseURL("http://localhost:8080", "v1")
+	assert.ErrorIs(t, err, ErrUnsupportedAPIVersion)
+
+	_, err = NewClient(Config{
+		CustomerUUID: dingtest.CustomerUUID,
+		APIKey:       dingtest.APIKey,
+		APIVersion:   "2024-01-01",
+	})
+	assert.ErrorIs(t, err, ErrUnsupportedAPIVersion)
+}
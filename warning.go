@@ -0,0 +1,74 @@
+package ding
+
+import (
+	"net/http"
+
+	"github.com/ding-live/ding-go/internal/api"
+)
+
+// dateLayout formats the dates of warnings.
+const dateLayout = "2006-01-02"
+
+// WarningCode identifies the kind of a Warning.
+type WarningCode string
+
+const (
+	// WarningLandline is given for numbers in landline ranges, which may not
+	// receive codes sent by SMS. It is detected locally from
+	// PhoneNumber.LineType.
+	WarningLandline WarningCode = "landline"
+
+	// WarningDeprecated is given when the Ding API marks the endpoint as
+	// deprecated with the Deprecation header, or schedules its removal with
+	// the Sunset header. Upgrade the SDK before the endpoint is removed.
+	WarningDeprecated WarningCode = "deprecated"
+)
+
+// Warning is a non-fatal issue with a call, which may become a failure later.
+type Warning struct {
+	Code    WarningCode
+	Message string
+}
+
+func (w Warning) String() string {
+	return string(w.Code) + ": " + w.Message
+}
+
+// responseWarnings returns the warnings of a response with the given
+// Deprecation and Sunset headers.
+func responseWarnings(deprecation, sunset string) []Warning {
+	if deprecation == "" && sunset == "" {
+		return nil
+	}
+
+	msg := "endpoint deprecated"
+
+	// The Deprecation header may hold a date, or only "true" as in earlier
+	// drafts of RFC 9745.
+	if since := api.ParseDeprecation(deprecation); !since.IsZero() {
+		msg += " since " + since.Format(dateLayout)
+	}
+
+	if sunset != "" {
+		if deprecation == "" {
+			msg = "endpoint removal scheduled"
+		} else {
+			msg += ", removal scheduled"
+		}
+
+		if t, err := http.ParseTime(sunset); err == nil {
+			msg += " for " + t.UTC().Format(dateLayout)
+		}
+	}
+
+	return []Warning{{Code: WarningDeprecated, Message: msg}}
+}
+
+// phoneWarnings returns the warnings about the phone number p.
+func phoneWarnings(p PhoneNumber) []Warning {
+	if p.LineType != LineTypeFixedLine {
+		return nil
+	}
+
+	return []Warning{{Code: WarningLandline, Message: "number possibly landline, SMS may not be received"}}
+}
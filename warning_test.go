@@ -0,0 +1,104 @@
+package ding
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ding-live/ding-go/pkg/dingtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeprecationWarning(t *testing.T) {
+	hc := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Deprecation", "@1735689600")
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"authentication_uuid":%q,"status":"valid"}`, dingtest.CustomerUUID)
+	}))
+	defer hc.Close()
+
+	client, err := NewClient(Config{
+		CustomerUUID: dingtest.CustomerUUID,
+		BaseURL:      hc.URL,
+	})
+	require.NoError(t, err)
+
+	check, err := client.Check(dingtest.CustomerUUID, "1234")
+	require.NoError(t, err)
+	assert.Equal(t, []Warning{{Code: WarningDeprecated, Message: "endpoint deprecated since 2025-01-01"}}, check.Warnings)
+}
+
+func TestResponseWarnings(t *testing.T) {
+	assert.Nil(t, responseWarnings("", ""))
+
+	for _, tt := range []struct {
+		deprecation, sunset, message string
+	}{
+		{"true", "", "endpoint deprecated"},
+		{"@1735689600", "", "endpoint deprecated since 2025-01-01"},
+		{"Wed, 01 Jan 2025 00:00:00 GMT", "", "endpoint deprecated since 2025-01-01"},
+		{"@1735689600", "Wed, 31 Dec 2025 23:59:59 GMT", "endpoint deprecated since 2025-01-01, removal scheduled for 2025-12-31"},
+		{"", "Wed, 31 Dec 2025 23:59:59 GMT", "endpoint removal scheduled for 2025-12-31"},
+		{"", "soon", "endpoint removal scheduled"},
+	} {
+		assert.Equal(t, []Warning{{Code: WarningDeprecated, Message: tt.message}}, responseWarnings(tt.deprecation, tt.sunset))
+	}
+}
+
+// warnLogger records warnings and drops other messages.
+type warnLogger struct {
+	warnings []string
+}
+
+func (l *warnLogger) Debugf(string, ...interface{}) {}
+func (l *warnLogger) Errorf(string, ...interface{}) {}
+func (l *warnLogger) Infof(string, ...interface{})  {}
+
+func (l *warnLogger) Warnf(format string, v ...interface{}) {
+	l.warnings = append(l.warnings, fmt.Sprintf(format, v...))
+}
+
+func TestOnDeprecation(t *testing.T) {
+	hc := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Deprecation", "@1735689600")
+		w.Header().Set("Sunset", "Wed, 31 Dec 2025 23:59:59 GMT")
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"authentication_uuid":%q,"status":"valid"}`, dingtest.CustomerUUID)
+	}))
+	defer hc.Close()
+
+	var (
+		logger       warnLogger
+		deprecations []Deprecation
+	)
+
+	client, err := NewClient(Config{
+		CustomerUUID:  dingtest.CustomerUUID,
+		BaseURL:       hc.URL,
+		LeveledLogger: &logger,
+		Hooks: Hooks{
+			OnDeprecation: func(d Deprecation) { deprecations = append(deprecations, d) },
+		},
+	})
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		_, err = client.Check(dingtest.CustomerUUID, "1234")
+		require.NoError(t, err)
+	}
+
+	d := Deprecation{
+		Method: http.MethodPost,
+		Path:   "check",
+		Since:  time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC),
+		Sunset: time.Date(2025, time.December, 31, 23, 59, 59, 0, time.UTC),
+	}
+	assert.Equal(t, []Deprecation{d, d}, deprecations)
+
+	// The deprecation is only logged once.
+	require.Len(t, logger.warnings, 1)
+	assert.Contains(t, logger.warnings[0], "POST check is deprecated")
+}